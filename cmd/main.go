@@ -3,11 +3,16 @@ package main
 import (
 	env "github.com/ilyakaznacheev/cleanenv"
 	"log"
+	"os"
 	"todo-list/configs"
 	"todo-list/internal/handler"
 	"todo-list/internal/handler/http"
 	"todo-list/internal/repository"
+	"todo-list/internal/repository/memory"
 	"todo-list/internal/service"
+
+	// The runtime image ships without a zoneinfo database.
+	_ "time/tzdata"
 )
 
 //	@title			Todo List API
@@ -33,9 +38,31 @@ func main() {
 		log.Fatal(err)
 	}
 
-	repo := repository.NewRepository(&cfg)
-	svc := service.NewService(repo)
-	h := handler.NewHandler(svc)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrations(&cfg, os.Args[2:])
+		return
+	}
+
+	var repo service.Repository
+	switch cfg.Storage {
+	case configs.StoragePostgres, configs.StorageSQLite:
+		sqlRepo := repository.NewRepository(&cfg)
+		if cfg.MigrateOnStart {
+			migrateUp(sqlRepo)
+		}
+		repo = sqlRepo
+	case configs.StorageMemory:
+		repo = memory.NewRepository()
+	default:
+		log.Fatalf("unknown storage driver %q", cfg.Storage)
+	}
+
+	svc := service.NewService(repo, repo, repo, repo, &cfg)
+	if cfg.PurgeInterval > 0 {
+		go purgeTrash(svc, cfg.PurgeInterval)
+	}
+
+	h := handler.NewHandler(svc, svc, svc, svc)
 
 	http.StartListening(&cfg, h)
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"todo-list/configs"
+	"todo-list/internal/repository"
+)
+
+const migrateUsage = "usage: main migrate up | down [steps] | status"
+
+// runMigrations handles the migrate subcommand against the configured storage.
+func runMigrations(cfg *configs.Config, args []string) {
+	if cfg.Storage == configs.StorageMemory {
+		log.Fatal("memory storage has no schema to migrate")
+	}
+
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	repo := repository.NewRepository(cfg)
+	defer repo.Close()
+
+	m, err := repo.Migrator()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		err = m.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				log.Fatal(migrateUsage)
+			}
+		}
+		err = m.Down(ctx, steps)
+	case "status":
+		statuses, statusErr := m.Status(ctx)
+		if statusErr != nil {
+			log.Fatal(statusErr)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATE")
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, state)
+		}
+		err = w.Flush()
+	default:
+		log.Fatal(migrateUsage)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func migrateUp(repo *repository.Repository) {
+	m, err := repo.Migrator()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = m.Up(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+	"todo-list/internal/service"
+)
+
+// purgeTrash empties the trash of tasks past their retention every interval,
+// for as long as the process runs.
+func purgeTrash(svc *service.Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := svc.PurgeTrash(context.Background())
+		if err != nil {
+			log.Printf("purging trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("purged %d tasks from the trash", purged)
+		}
+
+		<-ticker.C
+	}
+}
@@ -1,6 +1,25 @@
 package configs
 
+import "time"
+
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
+)
+
 type Config struct {
-	Port        string `env:"PORT"`
-	PostgresURL string `env:"POSTGRES_URL"`
+	Port           string        `env:"PORT"`
+	Storage        string        `env:"STORAGE" env-default:"postgres"`
+	PostgresURL    string        `env:"POSTGRES_URL"`
+	SQLitePath     string        `env:"SQLITE_PATH" env-default:"todo.db"`
+	MigrateOnStart bool          `env:"MIGRATE_ON_START" env-default:"true"`
+	RequestTimeout time.Duration `env:"REQUEST_TIMEOUT" env-default:"5s"`
+	ExportTimeout  time.Duration `env:"EXPORT_TIMEOUT" env-default:"5m"`
+	ImportTimeout  time.Duration `env:"IMPORT_TIMEOUT" env-default:"5m"`
+	JWTSecret      string        `env:"JWT_SECRET" env-required:"true"`
+	TokenTTL       time.Duration `env:"TOKEN_TTL" env-default:"24h"`
+	RequireIfMatch bool          `env:"REQUIRE_IF_MATCH" env-default:"false"`
+	TrashRetention time.Duration `env:"TRASH_RETENTION" env-default:"720h"`
+	PurgeInterval  time.Duration `env:"PURGE_INTERVAL" env-default:"1h"`
 }
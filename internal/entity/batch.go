@@ -0,0 +1,49 @@
+package entity
+
+// Operations of a task batch.
+const (
+	BatchCreate = "create"
+	BatchUpdate = "update"
+	BatchDelete = "delete"
+)
+
+// BatchOperation is one write of a task batch. Creates and updates carry the
+// whole Task; updates and deletes name the task by ID. Version is the version
+// to replace, as If-Match is for a single write, and Force completes a task
+// with open subtasks.
+type BatchOperation struct {
+	Op      string `json:"op" enums:"create,update,delete" example:"update"`
+	ID      int    `json:"id,omitempty" example:"1"`
+	Version int    `json:"version,omitempty" example:"3"`
+	Force   bool   `json:"force,omitempty" example:"false"`
+	Task    *Task  `json:"task,omitempty"`
+}
+
+// BatchResult is the outcome of one operation of a batch. Status is the HTTP
+// status the operation would have answered on its own, and Version the
+// version it left the task at.
+type BatchResult struct {
+	Op      string `json:"op" example:"update"`
+	ID      int    `json:"id,omitempty" example:"1"`
+	Version int    `json:"version,omitempty" example:"4"`
+	Status  int    `json:"status" example:"200"`
+	Error   string `json:"error,omitempty" example:"task not found"`
+	// Err is why the operation failed, which the handler turns into Status
+	// and Error.
+	Err error `json:"-"`
+}
+
+// TaskBatch holds the results of a batch in the order of its operations.
+// Committed is false when an all-or-nothing batch was rolled back.
+type TaskBatch struct {
+	Committed bool           `json:"committed" example:"true"`
+	Results   []*BatchResult `json:"results"`
+}
+
+// BulkResult lists the tasks a bulk update or delete matched, which it wrote
+// unless DryRun is set.
+type BulkResult struct {
+	Count  int   `json:"count" example:"2"`
+	IDs    []int `json:"ids" example:"3,4"`
+	DryRun bool  `json:"dry_run" example:"false"`
+}
@@ -0,0 +1,118 @@
+package entity
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// WritableFields are the members of a task clients may change, by their JSON
+// name. A patch touching any other member is refused.
+var WritableFields = []string{
+	"project_id",
+	"parent_id",
+	"title",
+	"description",
+	"due_at",
+	"all_day",
+	"completed",
+	"priority",
+	"tags",
+	"blocked_by",
+	"recurrence",
+}
+
+// TaskChanges lists the writable fields that differ between two versions of a
+// task, with their values before and after, for the task's history. before is
+// nil for a task being created. When fields is not nil only those are
+// compared, as a patch writes nothing else.
+func TaskChanges(before, after *Task, fields []string) ([]FieldChange, error) {
+	created := before == nil
+	if created {
+		before = &Task{}
+	}
+
+	oldMembers, err := taskMembers(before)
+	if err != nil {
+		return nil, err
+	}
+	newMembers, err := taskMembers(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []FieldChange{}
+	for _, field := range ChangedFields(before, after) {
+		if fields != nil && !slices.Contains(fields, field) {
+			continue
+		}
+
+		change := FieldChange{Field: field, After: newMembers[field]}
+		if !created {
+			change.Before = oldMembers[field]
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+func taskMembers(task *Task) (map[string]json.RawMessage, error) {
+	doc, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	err = json.Unmarshal(doc, &members)
+	return members, err
+}
+
+// ChangedFields names the writable fields whose values differ between two
+// versions of a task.
+func ChangedFields(before, after *Task) []string {
+	var fields []string
+	for _, field := range WritableFields {
+		if !sameField(before, after, field) {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+func sameField(a, b *Task, field string) bool {
+	switch field {
+	case "project_id":
+		return equalPtr(a.ProjectID, b.ProjectID)
+	case "parent_id":
+		return equalPtr(a.ParentID, b.ParentID)
+	case "title":
+		return a.Title == b.Title
+	case "description":
+		return a.Description == b.Description
+	case "due_at":
+		return a.DueAt.Equal(b.DueAt)
+	case "all_day":
+		return a.AllDay == b.AllDay
+	case "completed":
+		return a.Completed == b.Completed
+	case "priority":
+		return a.Priority == b.Priority
+	case "tags":
+		return slices.Equal(a.Tags, b.Tags)
+	case "blocked_by":
+		return slices.Equal(a.BlockedBy, b.BlockedBy)
+	case "recurrence":
+		return a.Recurrence == b.Recurrence
+	}
+
+	return false
+}
+
+func equalPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
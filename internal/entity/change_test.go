@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskChanges(t *testing.T) {
+	project := 2
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	before := &Task{ID: 1, Title: "Old", DueAt: due, ProjectID: &project, Tags: []string{"home"}, Version: 1}
+	after := &Task{ID: 1, Title: "New", DueAt: due, Tags: []string{"home", "work"}, Version: 2}
+
+	changes, err := TaskChanges(before, after, nil)
+	require.NoError(t, err)
+	doc, err := json.Marshal(changes)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"field": "project_id", "before": 2, "after": null},
+		{"field": "title", "before": "Old", "after": "New"},
+		{"field": "tags", "before": ["home"], "after": ["home", "work"]}
+	]`, string(doc))
+
+	changes, err = TaskChanges(before, after, []string{"title"})
+	require.NoError(t, err)
+	assert.Len(t, changes, 1)
+
+	changes, err = TaskChanges(nil, after, nil)
+	require.NoError(t, err)
+	doc, err = json.Marshal(changes)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"field": "title", "before": null, "after": "New"},
+		{"field": "due_at", "before": null, "after": "2024-01-01T09:00:00Z"},
+		{"field": "tags", "before": null, "after": ["home", "work"]}
+	]`, string(doc))
+
+	changes, err = TaskChanges(after, after, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, changes)
+	assert.Empty(t, changes)
+}
@@ -0,0 +1,17 @@
+package entity
+
+// ImportResult reports a CSV import: the tasks created, which a dry run only
+// counts, and the rows rejected with why.
+type ImportResult struct {
+	Imported int           `json:"imported" example:"2"`
+	IDs      []int         `json:"ids" example:"7,8"`
+	Rejected []RejectedRow `json:"rejected"`
+	DryRun   bool          `json:"dry_run" example:"false"`
+}
+
+// RejectedRow is a row of an imported file that did not become a task. Line
+// is where the row starts in the file, the header being line 1.
+type RejectedRow struct {
+	Line   int    `json:"line" example:"3"`
+	Reason string `json:"reason" example:"due_at: use RFC 3339 or YYYY-MM-DD"`
+}
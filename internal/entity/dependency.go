@@ -0,0 +1,14 @@
+package entity
+
+// Dependency records that a task cannot be completed before its blocker.
+type Dependency struct {
+	TaskID    int `json:"task_id" example:"2"`
+	BlockerID int `json:"blocker_id" example:"1"`
+}
+
+// DependencyGraph holds an owner's dependencies with the tasks taking part in
+// them, ordered so that every blocker comes before the tasks it blocks.
+type DependencyGraph struct {
+	Order        []int        `json:"order" example:"1,2"`
+	Dependencies []Dependency `json:"dependencies"`
+}
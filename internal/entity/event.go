@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Operations recorded in a task's history.
+const (
+	EventCreate  = "create"
+	EventUpdate  = "update"
+	EventDelete  = "delete"
+	EventRestore = "restore"
+)
+
+// TaskEvent is one write to a task: who made it, when, and what it changed.
+// Version is the version the write left the task at.
+type TaskEvent struct {
+	ID        int           `json:"id" example:"12"`
+	TaskID    int           `json:"task_id" example:"1"`
+	ActorID   int           `json:"actor_id" example:"1"`
+	Operation string        `json:"operation" enums:"create,update,delete,restore" example:"update"`
+	Version   int           `json:"version" example:"3"`
+	Changes   []FieldChange `json:"changes"`
+	CreatedAt time.Time     `json:"created_at" example:"2020-01-01T09:30:00Z"`
+}
+
+// FieldChange is the value of one field before and after a write, in the
+// field's JSON form. Before is null for a task being created.
+type FieldChange struct {
+	Field  string          `json:"field" example:"title"`
+	Before json.RawMessage `json:"before" swaggertype:"object"`
+	After  json.RawMessage `json:"after" swaggertype:"object"`
+}
+
+// TaskHistory is one page of a task's history, newest first. NextCursor
+// fetches the older events and is empty when there are none.
+type TaskHistory struct {
+	Events     []*TaskEvent `json:"events"`
+	NextCursor string       `json:"next_cursor,omitempty" example:"12"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+import "encoding/json"
+
+// TaskPatch is a JSON Merge Patch (RFC 7396) of a task: the members to change
+// by their JSON name, each with its new value. A null member resets its field
+// to the zero value, which for title or due_at makes the task invalid.
+type TaskPatch map[string]json.RawMessage
+
+// PatchOperation is one operation of a JSON Patch (RFC 6902). Path is a JSON
+// Pointer (RFC 6901) into the task, such as /title or /tags/0. Value is nil
+// when the operation has no value member, and the JSON null when it is null.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
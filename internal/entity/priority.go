@@ -0,0 +1,44 @@
+package entity
+
+import "fmt"
+
+// Priority orders tasks by urgency. It is stored as a number so that sorting
+// by priority is a plain column sort, and travels as its name in JSON.
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+func (p Priority) String() string {
+	if p < 0 || int(p) >= len(priorityNames) {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+
+	return priorityNames[p]
+}
+
+func (p Priority) MarshalText() ([]byte, error) {
+	if p < 0 || int(p) >= len(priorityNames) {
+		return nil, fmt.Errorf("invalid priority %d", int(p))
+	}
+
+	return []byte(priorityNames[p]), nil
+}
+
+func (p *Priority) UnmarshalText(text []byte) error {
+	for i, name := range priorityNames {
+		if string(text) == name {
+			*p = Priority(i)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid priority %q", text)
+}
@@ -0,0 +1,8 @@
+package entity
+
+type Project struct {
+	ID      int    `json:"id" example:"1"`
+	OwnerID int    `json:"owner_id" example:"1"`
+	Name    string `json:"name" example:"Home"`
+	Inbox   bool   `json:"inbox" example:"false"`
+}
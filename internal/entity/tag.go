@@ -0,0 +1,8 @@
+package entity
+
+type Tag struct {
+	ID      int    `json:"id" example:"1"`
+	OwnerID int    `json:"-"`
+	Name    string `json:"name" example:"home"`
+	Count   int    `json:"count" example:"3"`
+}
@@ -4,8 +4,108 @@ import "time"
 
 type Task struct {
 	ID          int       `json:"id" example:"1"`
+	OwnerID     int       `json:"owner_id" example:"1"`
+	ProjectID   *int      `json:"project_id" example:"1"`
+	ParentID    *int      `json:"parent_id" example:"1"`
 	Title       string    `json:"title" example:"Task title"`
 	Description string    `json:"description" example:"Task description"`
-	Date        time.Time `json:"date" example:"2020-01-01T00:00:00Z"`
+	DueAt       time.Time `json:"due_at" example:"2020-01-01T09:30:00+02:00"`
+	AllDay      bool      `json:"all_day" example:"false"`
 	Completed   bool      `json:"completed" example:"true"`
+	Priority    Priority  `json:"priority" swaggertype:"string" enums:"none,low,medium,high,urgent" example:"high"`
+	Tags        []string  `json:"tags" example:"home,urgent"`
+	// BlockedBy lists the tasks that must be completed before this one.
+	BlockedBy []int `json:"blocked_by" example:"3,4"`
+	// Recurrence is an RFC 5545 RRULE subset; empty for one-off tasks.
+	Recurrence string `json:"recurrence" example:"FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10"`
+	// Version goes up by one with every write to the task. It doubles as
+	// the task's ETag.
+	Version int `json:"version" example:"3"`
+	// DeletedAt is when the task was moved to the trash. It is only set on
+	// tasks listed from the trash.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" example:"2020-01-01T09:30:00Z"`
+	// Snippet is the matching text of a search result, escaped for HTML,
+	// with the matched words wrapped in <b></b>. It is only set by searches.
+	Snippet string `json:"snippet,omitempty" example:"Buy <b>groceries</b>"`
+	// Rank is the relevance of a search result, higher first. It is only set
+	// by searches and stays internal: clients page through ranked results
+	// with cursors.
+	Rank float64 `json:"-"`
+}
+
+// TaskPage is one page of a task list. NextCursor and PrevCursor fetch the
+// neighbouring pages and are empty when there is none.
+type TaskPage struct {
+	Tasks      []*Task `json:"tasks"`
+	NextCursor string  `json:"next_cursor,omitempty" example:"eyJpZCI6MTB9.c2lnbmF0dXJl"`
+	PrevCursor string  `json:"prev_cursor,omitempty" example:"eyJpZCI6MSwiYiI6dHJ1ZX0.c2lnbmF0dXJl"`
+}
+
+// Tag match modes of TaskFilter.TagMatch.
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+// SortField is one key of a task list ordering.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// TaskFilter selects a page of one owner's tasks. Empty fields do not filter.
+//
+// Date, From and To are days as sent by the client ("today" or YYYY-MM-DD);
+// the service resolves them in the caller's time zone into the half-open
+// due_at range [DueFrom, DueTo) that repositories filter on.
+//
+// Completed, Blocked and Overdue select tasks that are, or are not, in that
+// state when set. A task is overdue when it is open and past due: an all-day
+// task once its whole day has passed. DueWithin selects tasks not yet past due
+// that are due within that span. Overdue and DueWithin are measured from Now,
+// which the service sets.
+//
+// Cursor is an opaque position sent by the client; the service verifies it
+// into Keyset. Offset is the deprecated alternative of page numbers.
+//
+// Query is a full-text search. The service normalizes it to lowercase words
+// separated by single spaces, each of which must prefix a word of the title
+// or description; results are ranked by relevance unless Sort is set.
+type TaskFilter struct {
+	OwnerID   int
+	Query     string
+	ProjectID int
+	ParentID  int
+	Completed *bool
+	Blocked   *bool
+	Overdue   *bool
+	DueWithin time.Duration
+	Now       time.Time
+	Date      string
+	From      string
+	To        string
+	DueFrom   time.Time
+	DueTo     time.Time
+	Tags      []string
+	TagMatch  string
+	Sort      []SortField
+	Cursor    string
+	Keyset    *TaskKeyset
+	Offset    int
+	Limit     int
+}
+
+// TaskKeyset is a position in a task list, given by the sort key values of
+// the task there: Rank when ranking a search, the fields named by Sort, and
+// always ID. Only the fields of the active sort keys are meaningful. A filter
+// with a Keyset selects the tasks after that position, or the ones before it
+// when Backward is set; either way they come in list order.
+type TaskKeyset struct {
+	ID        int
+	Title     string
+	DueAt     time.Time
+	Priority  Priority
+	Completed bool
+	Rank      float64
+	Backward  bool
 }
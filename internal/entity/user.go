@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+type User struct {
+	ID           int       `json:"id" example:"1"`
+	Username     string    `json:"username" example:"john"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at" example:"2020-01-01T00:00:00Z"`
+	TimeZone     string    `json:"time_zone" example:"Europe/Berlin"`
+}
+
+// Settings are the account preferences a user may change.
+type Settings struct {
+	TimeZone string `json:"time_zone" example:"Europe/Berlin"`
+}
+
+type Credentials struct {
+	Username string `json:"username" example:"john"`
+	Password string `json:"password" example:"secret-password"`
+}
+
+type Token struct {
+	AccessToken string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenType   string `json:"token_type" example:"Bearer"`
+	ExpiresIn   int64  `json:"expires_in" example:"86400"`
+}
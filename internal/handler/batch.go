@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"todo-list/internal/entity"
+)
+
+type batchRequest struct {
+	Operations []entity.BatchOperation `json:"operations"`
+}
+
+// RunBatch godoc
+//
+//	@Summary		Run a batch of task writes
+//	@Description	Create, update and delete many tasks in one call and one transaction. Each operation is validated as the matching single call would be, sees the writes before it, and gets a result in the order sent with the status it would have answered on its own.
+//	@Description	By default the batch is all-or-nothing: if any operation fails, none is kept, committed is false and the operations that had succeeded answer 424. With best_effort=true the operations that succeed are kept regardless.
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			batch		body		batchRequest	true	"Operations, at most 1000"
+//	@Param			best_effort	query		bool			false	"Keep the operations that succeed even when others fail"	default(false)
+//	@Param			X-Timezone	header		string			false	"Time zone of all-day tasks, defaults to the user's setting"
+//	@Success		200			{object}	entity.TaskBatch
+//	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Failure		504			{object}	map[string]string
+//	@Router			/task/batch [post]
+func (h *Handler) RunBatch(ctx *gin.Context) {
+	bestEffort, err := strconv.ParseBool(ctx.DefaultQuery("best_effort", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid best_effort"})
+		return
+	}
+
+	var request batchRequest
+	err = ctx.ShouldBindJSON(&request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	batch, err := h.TaskService.RunBatch(ctx.Request.Context(), request.Operations, bestEffort)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	for _, result := range batch.Results {
+		switch {
+		case result.Err != nil:
+			result.Status = errorStatus(result.Err)
+			result.Error = result.Err.Error()
+		case result.Op == entity.BatchCreate:
+			result.Status = http.StatusCreated
+		default:
+			result.Status = http.StatusOK
+		}
+	}
+
+	ctx.JSON(http.StatusOK, batch)
+}
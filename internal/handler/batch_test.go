@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunBatch(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	batch := &entity.TaskBatch{Results: []*entity.BatchResult{
+		{Op: entity.BatchCreate, Err: service.ErrBatchAborted},
+		{Op: entity.BatchUpdate, ID: 2, Err: service.ErrVersionMismatch},
+	}}
+	mockService.On("RunBatch", mock.Anything, mock.MatchedBy(func(ops []entity.BatchOperation) bool {
+		return len(ops) == 2 && ops[0].Task.Title == "New" && ops[1].ID == 2 && ops[1].Version == 3
+	}), false).Return(batch, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/task/batch", bytes.NewBufferString(`{"operations": [
+		{"op": "create", "task": {"title": "New", "due_at": "2024-01-01T09:00:00Z"}},
+		{"op": "update", "id": 2, "version": 3, "task": {"title": "Old", "due_at": "2024-01-01T09:00:00Z"}}
+	]}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"committed": false, "results": [
+		{"op": "create", "status": 424, "error": "rolled back because another operation of the batch failed"},
+		{"op": "update", "id": 2, "status": 412, "error": "task has been changed since the given version"}
+	]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/task/batch?best_effort=maybe", bytes.NewBufferString(`{"operations": []}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"todo-list/internal/entity"
+)
+
+// UpdateTasks godoc
+//
+//	@Summary		Update tasks by filter
+//	@Description	Apply a JSON Merge Patch to every task matching the filters of the task list, such as completed=false&to=2024-01-01 with {"completed": true}. Each task is patched as PATCH /task/{id} would, all in one transaction: if one fails, none is changed and the error names it.
+//	@Description	At least one filter is required, and a filter matching more than 1000 tasks is refused. With dry_run=true the writes are made and rolled back, returning the tasks that would change.
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Accept			application/merge-patch+json
+//	@Produce		json
+//	@Param			patch		body		entity.Task	true	"Members of the tasks to change"
+//	@Param			dry_run		query		bool		false	"Only report the tasks that would change"	default(false)
+//	@Param			force		query		bool		false	"Complete tasks even with open subtasks"	default(false)
+//	@Param			q			query		string		false	"Full-text search over title and description"
+//	@Param			completed	query		string		false	"Filter by completion status"	Enums(true, false, any)	default(any)
+//	@Param			blocked		query		string		false	"Filter by whether a task has open blockers"	Enums(true, false, any)	default(any)
+//	@Param			overdue		query		string		false	"Filter by whether a task is open and past due"	Enums(true, false, any)	default(any)
+//	@Param			due_within	query		string		false	"Only tasks not yet past due that are due within this many hours, days or weeks"	example(7d)
+//	@Param			date		query		string		false	"Filter by due day, YYYY-MM-DD or today"
+//	@Param			from		query		string		false	"Earliest due day, YYYY-MM-DD or today"
+//	@Param			to			query		string		false	"Latest due day, YYYY-MM-DD or today"
+//	@Param			X-Timezone	header		string		false	"Time zone days are read in, defaults to the user's setting"
+//	@Param			project		query		int			false	"Filter by project ID"
+//	@Param			tag			query		[]string	false	"Filter by tag, repeatable"	collectionFormat(multi)
+//	@Param			tag_match	query		string		false	"Whether a task needs any or all of the tags"	Enums(any, all)	default(any)
+//	@Success		200			{object}	entity.BulkResult
+//	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
+//	@Failure		404			{object}	map[string]string
+//	@Failure		409			{object}	map[string]string
+//	@Failure		412			{object}	map[string]string
+//	@Failure		415			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Failure		504			{object}	map[string]string
+//	@Router			/task [patch]
+func (h *Handler) UpdateTasks(ctx *gin.Context) {
+	filter, err := taskFilter(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(ctx.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+		return
+	}
+
+	force, err := strconv.ParseBool(ctx.DefaultQuery("force", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid force"})
+		return
+	}
+
+	if ctx.ContentType() != mergePatchType {
+		ctx.Header("Accept-Patch", mergePatchType)
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "bulk patches must be sent as " + mergePatchType})
+		return
+	}
+
+	var patch entity.TaskPatch
+	err = json.NewDecoder(ctx.Request.Body).Decode(&patch)
+	if err != nil || patch == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "a merge patch of a task must be a JSON object"})
+		return
+	}
+
+	result, err := h.TaskService.UpdateTasks(ctx.Request.Context(), filter, patch, force, dryRun)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// DeleteTasks godoc
+//
+//	@Summary		Delete tasks by filter
+//	@Description	Move every task matching the filters of the task list to the trash, with their subtasks, in one transaction.
+//	@Description	At least one filter is required, and a filter matching more than 1000 tasks is refused. With dry_run=true nothing is deleted and the tasks that would be are returned.
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			dry_run		query		bool		false	"Only report the tasks that would be deleted"	default(false)
+//	@Param			q			query		string		false	"Full-text search over title and description"
+//	@Param			completed	query		string		false	"Filter by completion status"	Enums(true, false, any)	default(any)
+//	@Param			blocked		query		string		false	"Filter by whether a task has open blockers"	Enums(true, false, any)	default(any)
+//	@Param			overdue		query		string		false	"Filter by whether a task is open and past due"	Enums(true, false, any)	default(any)
+//	@Param			due_within	query		string		false	"Only tasks not yet past due that are due within this many hours, days or weeks"	example(7d)
+//	@Param			date		query		string		false	"Filter by due day, YYYY-MM-DD or today"
+//	@Param			from		query		string		false	"Earliest due day, YYYY-MM-DD or today"
+//	@Param			to			query		string		false	"Latest due day, YYYY-MM-DD or today"
+//	@Param			X-Timezone	header		string		false	"Time zone days are read in, defaults to the user's setting"
+//	@Param			project		query		int			false	"Filter by project ID"
+//	@Param			tag			query		[]string	false	"Filter by tag, repeatable"	collectionFormat(multi)
+//	@Param			tag_match	query		string		false	"Whether a task needs any or all of the tags"	Enums(any, all)	default(any)
+//	@Success		200			{object}	entity.BulkResult
+//	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Failure		504			{object}	map[string]string
+//	@Router			/task [delete]
+func (h *Handler) DeleteTasks(ctx *gin.Context) {
+	filter, err := taskFilter(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(ctx.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+		return
+	}
+
+	result, err := h.TaskService.DeleteTasks(ctx.Request.Context(), filter, dryRun)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateTasks(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	result := &entity.BulkResult{Count: 2, IDs: []int{3, 4}, DryRun: true}
+	mockService.On("UpdateTasks", mock.Anything, mock.MatchedBy(func(filter entity.TaskFilter) bool {
+		return filter.To == "2024-01-01" && *filter.Completed == false
+	}), entity.TaskPatch{"completed": []byte("true")}, false, true).Return(result, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/task?completed=false&to=2024-01-01&dry_run=true", bytes.NewBufferString(`{"completed":true}`))
+	req.Header.Set("Content-Type", mergePatchType)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"count": 2, "ids": [3, 4], "dry_run": true}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PATCH", "/task?completed=false", bytes.NewBufferString(`[]`))
+	req.Header.Set("Content-Type", jsonPatchType)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteTasks(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	mockService.On("DeleteTasks", mock.Anything, mock.MatchedBy(func(filter entity.TaskFilter) bool {
+		return filter.ProjectID == 2
+	}), false).Return(&entity.BulkResult{Count: 1, IDs: []int{7}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/task?project=2", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"count": 1, "ids": [7], "dry_run": false}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/task?project=2&dry_run=perhaps", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// maxImportSize bounds the request body of a CSV import, in bytes.
+const maxImportSize = 10 << 20
+
+// csvResponse writes a CSV download. Its headers go out with the first bytes,
+// so that an export failing before then can still answer with an error.
+type csvResponse struct {
+	ctx     *gin.Context
+	started bool
+}
+
+func (w *csvResponse) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		w.ctx.Header("Content-Disposition", `attachment; filename="tasks.csv"`)
+		w.ctx.Status(http.StatusOK)
+	}
+
+	return w.ctx.Writer.Write(p)
+}
+
+// ExportTasks godoc
+//
+//	@Summary		Export tasks as CSV
+//	@Description	Stream every task matching the filters of the task list as CSV, in list order, with a header line: id, title, description, due_at, all_day, completed, priority, project_id, parent_id, tags, blocked_by, recurrence and version. Lists are comma-separated within their cell, and all-day tasks are due on a YYYY-MM-DD day of the caller's time zone.
+//	@Description	Title, description and tags cells starting with =, +, - or @ are prefixed with ' so that spreadsheets do not run them as formulas.
+//	@Description	An export has EXPORT_TIMEOUT to finish rather than the usual request timeout. One failing once the file has started is cut short by closing the connection before the end of the body.
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Produce		text/csv
+//	@Param			q			query		string		false	"Full-text search over title and description"
+//	@Param			completed	query		string		false	"Filter by completion status"	Enums(true, false, any)	default(any)
+//	@Param			blocked		query		string		false	"Filter by whether a task has open blockers"	Enums(true, false, any)	default(any)
+//	@Param			overdue		query		string		false	"Filter by whether a task is open and past due"	Enums(true, false, any)	default(any)
+//	@Param			due_within	query		string		false	"Only tasks not yet past due that are due within this many hours, days or weeks"	example(7d)
+//	@Param			date		query		string		false	"Filter by due day, YYYY-MM-DD or today"
+//	@Param			from		query		string		false	"Earliest due day, YYYY-MM-DD or today"
+//	@Param			to			query		string		false	"Latest due day, YYYY-MM-DD or today"
+//	@Param			X-Timezone	header		string		false	"Time zone days are read in, defaults to the user's setting"
+//	@Param			project		query		int			false	"Filter by project ID"
+//	@Param			tag			query		[]string	false	"Filter by tag, repeatable"	collectionFormat(multi)
+//	@Param			tag_match	query		string		false	"Whether a task needs any or all of the tags"	Enums(any, all)	default(any)
+//	@Param			sort		query		string		false	"Comma-separated sort fields (id, title, due_at or its former name date, priority, completed); prefix with - for descending"	example(-priority,due_at)
+//	@Success		200			{string}	string		"CSV file"
+//	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Failure		504			{object}	map[string]string
+//	@Router			/task/export.csv [get]
+func (h *Handler) ExportTasks(ctx *gin.Context) {
+	filter, err := taskFilter(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	w := &csvResponse{ctx: ctx}
+	err = h.TaskService.ExportTasks(ctx.Request.Context(), filter, w)
+	if err != nil && !w.started {
+		errorResponse(ctx, err)
+		return
+	}
+	if err != nil {
+		// The status has gone out, so the client can only tell from the
+		// connection closing before the end of the file.
+		log.Printf("export of tasks cut short: %v", err)
+		_ = ctx.Error(err)
+		cutShort(ctx)
+	}
+}
+
+// cutShort closes the connection under a response already under way, so
+// that the client sees the body end early rather than complete.
+func cutShort(ctx *gin.Context) {
+	ctx.Writer.Flush()
+
+	var w http.ResponseWriter = ctx.Writer
+	if wrapper, ok := w.(interface{ Unwrap() http.ResponseWriter }); ok {
+		w = wrapper.Unwrap()
+	}
+
+	conn, _, err := http.NewResponseController(w).Hijack()
+	if err == nil {
+		_ = conn.Close()
+	}
+}
+
+// ImportTasks godoc
+//
+//	@Summary		Import tasks from CSV
+//	@Description	Create a task from every row of a CSV file whose first line names its columns. Columns named after a task field fill it, as in an export; mapping instead maps column names to fields, such as {"Name": "title", "Deadline": "due_at"}, and leaves the other columns out. title and due_at must be filled.
+//	@Description	due_at is RFC 3339 or a YYYY-MM-DD day, which makes an all-day task unless all_day says otherwise. Lists are comma-separated within their cell. Each row is validated like POST /task; rows that fail are reported with the reason and the others are imported, while any other failure imports nothing. With dry_run=true nothing is kept.
+//	@Description	A ' prefixing an exported title, description or tags cell against formulas is stripped.
+//	@Description	An import has IMPORT_TIMEOUT to finish rather than the usual request timeout.
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file		formData	file	true	"CSV file, at most 10 MB and 10000 rows"
+//	@Param			mapping		formData	string	false	"JSON object mapping column names to task fields"
+//	@Param			dry_run		query		bool	false	"Validate the rows without importing them"	default(false)
+//	@Param			X-Timezone	header		string	false	"Time zone of all-day tasks, defaults to the user's setting"
+//	@Success		200			{object}	entity.ImportResult
+//	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
+//	@Failure		413			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Failure		504			{object}	map[string]string
+//	@Router			/task/import [post]
+func (h *Handler) ImportTasks(ctx *gin.Context) {
+	dryRun, err := strconv.ParseBool(ctx.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportSize)
+	header, err := ctx.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "the file must be at most 10 MB"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "a CSV file is required in the file field"})
+		return
+	}
+
+	var mapping map[string]string
+	if value := ctx.PostForm("mapping"); value != "" {
+		err = json.Unmarshal([]byte(value), &mapping)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "mapping must be a JSON object of column names to task fields"})
+			return
+		}
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+	defer file.Close()
+
+	result, err := h.TaskService.ImportTasks(ctx.Request.Context(), file, mapping, dryRun)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportTasks(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	mockService.On("ExportTasks", mock.Anything, mock.MatchedBy(func(filter entity.TaskFilter) bool {
+		return filter.ProjectID == 2
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		_, _ = io.WriteString(args.Get(2).(io.Writer), "id,title\n1,Report\n")
+	}).Return(nil).Once()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task/export.csv?project=2", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="tasks.csv"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "id,title\n1,Report\n", w.Body.String())
+
+	mockService.On("ExportTasks", mock.Anything, mock.Anything, mock.Anything).Return(service.ErrInvalidData).Once()
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/task/export.csv?project=3", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	mockService.AssertExpectations(t)
+}
+
+func TestExportTasks_CutShort(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	server := httptest.NewServer(setupRouter(handler))
+	defer server.Close()
+
+	mockService.On("ExportTasks", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		_, _ = io.WriteString(args.Get(2).(io.Writer), "id,title\n1,Report\n")
+	}).Return(context.DeadlineExceeded)
+
+	res, err := http.Get(server.URL + "/task/export.csv")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, err := io.ReadAll(res.Body)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, "id,title\n1,Report\n", string(body))
+	mockService.AssertExpectations(t)
+}
+
+// importRequest builds a multipart upload of file, and of mapping unless it
+// is empty.
+func importRequest(t *testing.T, target string, file string, mapping string) *http.Request {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if file != "" {
+		part, err := form.CreateFormFile("file", "tasks.csv")
+		assert.NoError(t, err)
+		_, _ = io.WriteString(part, file)
+	}
+	if mapping != "" {
+		assert.NoError(t, form.WriteField("mapping", mapping))
+	}
+	assert.NoError(t, form.Close())
+
+	req, _ := http.NewRequest("POST", target, &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func TestImportTasks(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	file := "Name,Deadline\nReport,2024-03-05\n,2024-03-06\n"
+	result := &entity.ImportResult{Imported: 1, IDs: []int{}, Rejected: []entity.RejectedRow{{Line: 3, Reason: "title is required"}}, DryRun: true}
+	mockService.On("ImportTasks", mock.Anything, mock.MatchedBy(func(r io.Reader) bool {
+		content, _ := io.ReadAll(r)
+		return string(content) == file
+	}), map[string]string{"Name": "title", "Deadline": "due_at"}, true).Return(result, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, importRequest(t, "/task/import?dry_run=true", file, `{"Name": "title", "Deadline": "due_at"}`))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"imported": 1, "ids": [], "rejected": [{"line": 3, "reason": "title is required"}], "dry_run": true}`, w.Body.String())
+
+	for _, req := range []*http.Request{
+		importRequest(t, "/task/import", "", ""),
+		importRequest(t, "/task/import", file, `["title"]`),
+		importRequest(t, "/task/import?dry_run=perhaps", file, ""),
+	} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, importRequest(t, "/task/import", string(make([]byte, maxImportSize)), ""))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	mockService.AssertExpectations(t)
+}
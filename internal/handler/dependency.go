@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// GetDependencyGraph godoc
+//
+//	@Summary		Get the dependency graph
+//	@Description	Get every dependency between the caller's tasks, with the tasks involved ordered so that blockers come first
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	entity.DependencyGraph
+//	@Failure		401	{object}	map[string]string
+//	@Failure		500	{object}	map[string]string
+//	@Failure		504	{object}	map[string]string
+//	@Router			/task/graph [get]
+func (h *Handler) GetDependencyGraph(ctx *gin.Context) {
+	graph, err := h.TaskService.GetDependencyGraph(ctx.Request.Context())
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, graph)
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetDependencyGraph(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	graph := &entity.DependencyGraph{Order: []int{1, 2}, Dependencies: []entity.Dependency{{TaskID: 2, BlockerID: 1}}}
+	mockService.On("GetDependencyGraph", mock.Anything).Return(graph, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task/graph", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"order":[1,2],"dependencies":[{"task_id":2,"blocker_id":1}]}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTaskList_Blocked(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	mockService.On("GetTaskList", mock.Anything, entity.TaskFilter{Blocked: boolPtr(true), Limit: 10}).Return(&entity.TaskPage{Tasks: []*entity.Task{}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task?blocked=true", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -1,29 +1,88 @@
 package handler
 
 import (
-	"database/sql"
+	"context"
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 	"todo-list/internal/entity"
 	"todo-list/internal/service"
 )
 
-func NewHandler(service TaskService) *Handler {
-	return &Handler{service}
+//	@securityDefinitions.apikey	BearerAuth
+//	@in							header
+//	@name						Authorization
+//	@description				Access token from /auth/login, sent as "Bearer <token>".
+
+func NewHandler(tasks TaskService, users UserService, projects ProjectService, tags TagService) *Handler {
+	return &Handler{tasks, users, projects, tags}
 }
 
 type Handler struct {
 	TaskService
+	UserService
+	ProjectService
+	TagService
 }
 
 type TaskService interface {
-	CreateTask(task *entity.Task) (int64, error)
-	GetTask(id int) (*entity.Task, error)
-	UpdateTask(id int, task *entity.Task) error
-	DeleteTask(id int) error
-	GetTaskList(offset int, completed string, pagesize int, date string) ([]*entity.Task, error)
+	CreateTask(ctx context.Context, task *entity.Task) (int64, error)
+	GetTask(ctx context.Context, id int) (*entity.Task, error)
+	UpdateTask(ctx context.Context, id int, task *entity.Task, force bool) error
+	PatchTask(ctx context.Context, id int, patch entity.TaskPatch, version int, force bool) (*entity.Task, error)
+	JSONPatchTask(ctx context.Context, id int, ops []entity.PatchOperation, version int, force bool) (*entity.Task, error)
+	DeleteTask(ctx context.Context, id int, version int) error
+	GetTaskList(ctx context.Context, filter entity.TaskFilter) (*entity.TaskPage, error)
+	GetOccurrences(ctx context.Context, id int, from, to string) ([]time.Time, error)
+	GetChildren(ctx context.Context, id int, filter entity.TaskFilter) (*entity.TaskPage, error)
+	GetSubtree(ctx context.Context, id int) ([]*entity.Task, error)
+	MoveTask(ctx context.Context, id int, parentID *int) error
+	GetDependencyGraph(ctx context.Context) (*entity.DependencyGraph, error)
+	GetTrash(ctx context.Context) ([]*entity.Task, error)
+	RestoreTask(ctx context.Context, id int) error
+	GetTaskHistory(ctx context.Context, id int, cursor string, limit int) (*entity.TaskHistory, error)
+	GetTaskVersion(ctx context.Context, id int, version int) (*entity.Task, error)
+	GetTaskAsOf(ctx context.Context, id int, at time.Time) (*entity.Task, error)
+	RevertTask(ctx context.Context, id int, version int, expected int, force bool) (*entity.Task, error)
+	RunBatch(ctx context.Context, ops []entity.BatchOperation, bestEffort bool) (*entity.TaskBatch, error)
+	UpdateTasks(ctx context.Context, filter entity.TaskFilter, patch entity.TaskPatch, force bool, dryRun bool) (*entity.BulkResult, error)
+	DeleteTasks(ctx context.Context, filter entity.TaskFilter, dryRun bool) (*entity.BulkResult, error)
+	ExportTasks(ctx context.Context, filter entity.TaskFilter, w io.Writer) error
+	ImportTasks(ctx context.Context, file io.Reader, mapping map[string]string, dryRun bool) (*entity.ImportResult, error)
+}
+
+// errorResponse writes err with the status code matching its kind.
+func errorResponse(ctx *gin.Context, err error) {
+	ctx.JSON(errorStatus(err), gin.H{"error": err.Error()})
+}
+
+// errorStatus returns the status code matching the kind of err.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrInvalidData):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrNotFound), errors.Is(err, service.ErrProjectNotFound), errors.Is(err, service.ErrTagNotFound), errors.Is(err, service.ErrVersionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidCredentials), errors.Is(err, service.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, service.ErrUserExists), errors.Is(err, service.ErrTagExists), errors.Is(err, service.ErrOpenSubtasks), errors.Is(err, service.ErrBlocked), errors.Is(err, service.ErrTestFailed), errors.Is(err, service.ErrParentInTrash):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, service.ErrVersionRequired):
+		return http.StatusPreconditionRequired
+	case errors.Is(err, service.ErrBatchAborted):
+		return http.StatusFailedDependency
+	case errors.Is(err, service.ErrTimeout):
+		return http.StatusGatewayTimeout
+	}
+
+	return http.StatusInternalServerError
 }
 
 // CreateTask godoc
@@ -31,12 +90,16 @@ type TaskService interface {
 //	@Summary		Create a task
 //	@Description	Create a new task
 //	@Tags			tasks
+//	@Security		BearerAuth
 //	@Accept			json
 //	@Produce		json
-//	@Param			task	body		entity.Task	true	"Task"
-//	@Success		201		{object}	map[string]int64
+//	@Param			task		body		entity.Task	true	"Task"
+//	@Param			X-Timezone	header		string		false	"Time zone of all-day tasks, defaults to the user's setting"
+//	@Success		201			{object}	map[string]int64
 //	@Failure		400		{object}	map[string]string
+//	@Failure		401		{object}	map[string]string
 //	@Failure		500		{object}	map[string]string
+//	@Failure		504		{object}	map[string]string
 //	@Router			/task [post]
 func (h *Handler) CreateTask(ctx *gin.Context) {
 	var task entity.Task
@@ -47,10 +110,9 @@ func (h *Handler) CreateTask(ctx *gin.Context) {
 		return
 	}
 
-	id, err := h.TaskService.CreateTask(&task)
-
+	id, err := h.TaskService.CreateTask(ctx.Request.Context(), &task)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		errorResponse(ctx, err)
 		return
 	}
 
@@ -60,14 +122,20 @@ func (h *Handler) CreateTask(ctx *gin.Context) {
 // GetTask godoc
 //
 //	@Summary		Get a task
-//	@Description	Get a task by ID
+//	@Description	Get a task by ID. The ETag header carries the task's version, for If-Match on later writes.
+//	@Description	With as_of the task is returned as it was at that time instead, without an ETag; a task not yet created or in the trash then answers 404.
 //	@Tags			tasks
+//	@Security		BearerAuth
 //	@Produce		json
-//	@Param			id	path		int	true	"Task ID"
-//	@Success		200	{object}	entity.Task
-//	@Failure		400	{object}	map[string]string
-//	@Failure		404	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Param			id		path		int		true	"Task ID"
+//	@Param			as_of	query		string	false	"RFC 3339 time to read the task at"
+//	@Success		200		{object}	entity.Task
+//	@Header			200		{string}	ETag	"Version of the task"
+//	@Failure		400		{object}	map[string]string
+//	@Failure		401		{object}	map[string]string
+//	@Failure		404		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Failure		504		{object}	map[string]string
 //	@Router			/task/{id} [get]
 func (h *Handler) GetTask(ctx *gin.Context) {
 	idParam := ctx.Param("id")
@@ -77,32 +145,80 @@ func (h *Handler) GetTask(ctx *gin.Context) {
 		return
 	}
 
-	task, err := h.TaskService.GetTask(id)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if asOf := ctx.Query("as_of"); asOf != "" {
+		h.getTaskAsOf(ctx, id, asOf)
 		return
 	}
 
-	if err == sql.ErrNoRows {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+	task, err := h.TaskService.GetTask(ctx.Request.Context(), id)
+	if err != nil {
+		errorResponse(ctx, err)
 		return
 	}
 
+	setETag(ctx, task.Version)
 	ctx.JSON(http.StatusOK, task)
 }
 
+// GetOccurrences godoc
+//
+//	@Summary		List occurrences of a task
+//	@Description	Expand a task's recurrence into its due times within a window of days
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id			path		int		true	"Task ID"
+//	@Param			from		query		string	false	"First day, YYYY-MM-DD or today"	default(today)
+//	@Param			to			query		string	false	"Last day, YYYY-MM-DD or today; defaults to 30 days after from"
+//	@Param			X-Timezone	header		string	false	"Time zone days are read in, defaults to the user's setting"
+//	@Success		200			{array}		string
+//	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
+//	@Failure		404			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Failure		504			{object}	map[string]string
+//	@Router			/task/{id}/occurrences [get]
+func (h *Handler) GetOccurrences(ctx *gin.Context) {
+	idParam := ctx.Param("id")
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	occurrences, err := h.TaskService.GetOccurrences(ctx.Request.Context(), id, ctx.Query("from"), ctx.Query("to"))
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, occurrences)
+}
+
 // UpdateTask godoc
 //
 //	@Summary		Update a task
-//	@Description	Update a task by ID
+//	@Description	Update a task by ID. A task with open blockers cannot be completed, nor one with open subtasks unless forced.
+//	@Description	With If-Match the task is only replaced while still at that version.
 //	@Tags			tasks
+//	@Security		BearerAuth
 //	@Accept			json
 //	@Produce		json
 //	@Param			id		path		int			true	"Task ID"
-//	@Param			task	body		entity.Task	true	"Task"
-//	@Success		200		{object}	map[string]int
+//	@Param			task		body		entity.Task	true	"Task"
+//	@Param			force		query		bool		false	"Complete the task even with open subtasks"	default(false)
+//	@Param			X-Timezone	header		string		false	"Time zone of all-day tasks, defaults to the user's setting"
+//	@Param			If-Match	header		string		false	"ETag of the version to replace"
+//	@Success		200			{object}	map[string]int
+//	@Header			200			{string}	ETag	"New version of the task"
 //	@Failure		400		{object}	map[string]string
+//	@Failure		401		{object}	map[string]string
+//	@Failure		404		{object}	map[string]string
+//	@Failure		409		{object}	map[string]string
+//	@Failure		412		{object}	map[string]string
+//	@Failure		428		{object}	map[string]string
 //	@Failure		500		{object}	map[string]string
+//	@Failure		504		{object}	map[string]string
 //	@Router			/task/{id} [put]
 func (h *Handler) UpdateTask(ctx *gin.Context) {
 	idParam := ctx.Param("id")
@@ -112,37 +228,53 @@ func (h *Handler) UpdateTask(ctx *gin.Context) {
 		return
 	}
 
-	var task entity.Task
-	err = ctx.ShouldBindJSON(&task)
+	force, err := strconv.ParseBool(ctx.DefaultQuery("force", "false"))
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid force"})
 		return
 	}
 
-	err = h.TaskService.UpdateTask(id, &task)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, err := h.ifMatch(ctx, id)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	var task entity.Task
+	err = ctx.ShouldBindJSON(&task)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if errors.Is(err, sql.ErrNoRows) {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+	task.Version = version
+	err = h.TaskService.UpdateTask(ctx.Request.Context(), id, &task, force)
+	if err != nil {
+		errorResponse(ctx, err)
 		return
 	}
 
+	setETag(ctx, task.Version)
 	ctx.JSON(http.StatusOK, gin.H{"id": id})
 }
 
 // DeleteTask godoc
 //
 //	@Summary		Delete a task
-//	@Description	Delete a task by ID
+//	@Description	Move a task and its subtasks to the trash, from where POST /task/{id}/restore brings them back. With If-Match the task is only deleted while still at that version.
 //	@Tags			tasks
+//	@Security		BearerAuth
 //	@Produce		json
-//	@Param			id	path		int	true	"Task ID"
-//	@Success		200	{object}	map[string]int
-//	@Failure		400	{object}	map[string]string
-//	@Failure		500	{object}	map[string]string
+//	@Param			id			path		int		true	"Task ID"
+//	@Param			If-Match	header		string	false	"ETag of the version to delete"
+//	@Success		200			{object}	map[string]int
+//	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
+//	@Failure		404			{object}	map[string]string
+//	@Failure		412			{object}	map[string]string
+//	@Failure		428			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Failure		504	{object}	map[string]string
 //	@Router			/task/{id} [delete]
 func (h *Handler) DeleteTask(ctx *gin.Context) {
 	idParam := ctx.Param("id")
@@ -152,14 +284,15 @@ func (h *Handler) DeleteTask(ctx *gin.Context) {
 		return
 	}
 
-	err = h.TaskService.DeleteTask(id)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, err := h.ifMatch(ctx, id)
+	if err != nil {
+		errorResponse(ctx, err)
 		return
 	}
 
-	if errors.Is(err, sql.ErrNoRows) {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+	err = h.TaskService.DeleteTask(ctx.Request.Context(), id, version)
+	if err != nil {
+		errorResponse(ctx, err)
 		return
 	}
 
@@ -169,35 +302,209 @@ func (h *Handler) DeleteTask(ctx *gin.Context) {
 // GetTaskList godoc
 //
 //	@Summary		Get task list
-//	@Description	Get a list of tasks with pagination
+//	@Description	Get a page of tasks. Follow next_cursor and prev_cursor with the cursor parameter to page through the list; page and pageSize are deprecated.
 //	@Tags			tasks
+//	@Security		BearerAuth
 //	@Produce		json
-//	@Param			page		query		int		false	"Page number"				default(1)
-//	@Param			pageSize	query		int		false	"Number of tasks per page"	default(10)
-//	@Param			completed	query		string	false	"Filter by completion status"
-//	@Param			date		query		string	false	"Filter by date"
-//	@Success		200			{array}		entity.Task
+//	@Param			cursor		query		string	false	"Cursor of the page to fetch, from next_cursor or prev_cursor; only valid with the sort it was made for"
+//	@Param			limit		query		int		false	"Number of tasks per page, at most 1000"	default(10)
+//	@Param			page		query		int		false	"Deprecated: page number, use cursor"
+//	@Param			pageSize	query		int		false	"Deprecated: number of tasks per page, use limit"
+//	@Param			q			query		string	false	"Full-text search over title and description; words match as prefixes and results come ranked, with a highlighted snippet"
+//	@Param			completed	query		string	false	"Filter by completion status"	Enums(true, false, any)	default(any)
+//	@Param			blocked		query		string	false	"Filter by whether a task has open blockers"	Enums(true, false, any)	default(any)
+//	@Param			overdue		query		string	false	"Filter by whether a task is open and past due; an all-day task once its day has passed"	Enums(true, false, any)	default(any)
+//	@Param			due_within	query		string	false	"Only tasks not yet past due that are due within this many hours, days or weeks"	example(7d)
+//	@Param			date		query		string	false	"Filter by due day, YYYY-MM-DD or today"
+//	@Param			from		query		string	false	"Earliest due day, YYYY-MM-DD or today"
+//	@Param			to			query		string	false	"Latest due day, YYYY-MM-DD or today"
+//	@Param			X-Timezone	header		string	false	"Time zone days are read in, defaults to the user's setting"
+//	@Param			project		query		int		false	"Filter by project ID"
+//	@Param			tag			query		[]string	false	"Filter by tag, repeatable"	collectionFormat(multi)
+//	@Param			tag_match	query		string	false	"Whether a task needs any or all of the tags"	Enums(any, all)	default(any)
+//	@Param			sort		query		string	false	"Comma-separated sort fields (id, title, due_at or its former name date, priority, completed); prefix with - for descending"	example(-priority,due_at)
+//	@Success		200			{object}	entity.TaskPage
+//	@Header			200			{string}	Deprecation	"true when page or pageSize was used"
 //	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
 //	@Failure		500			{object}	map[string]string
+//	@Failure		504			{object}	map[string]string
 //	@Router			/task [get]
 func (h *Handler) GetTaskList(ctx *gin.Context) {
+	filter, err := taskFilter(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.TaskService.GetTaskList(ctx.Request.Context(), filter)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// taskFilter reads the filter and paging parameters of a task list request.
+// The deprecated page and pageSize still work and mark the response with a
+// Deprecation header.
+func taskFilter(ctx *gin.Context) (entity.TaskFilter, error) {
 	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(ctx.DefaultQuery("pageSize", "10"))
-	completed := ctx.DefaultQuery("completed", "")
-	date := ctx.DefaultQuery("date", "")
+	if _, ok := ctx.GetQuery("page"); ok {
+		ctx.Header("Deprecation", "true")
+	} else if _, ok := ctx.GetQuery("pageSize"); ok {
+		ctx.Header("Deprecation", "true")
+	}
 
-	offset := (page - 1) * pageSize
+	if limit := ctx.Query("limit"); limit != "" {
+		var err error
+		pageSize, err = strconv.Atoi(limit)
+		if err != nil {
+			return entity.TaskFilter{}, errors.New("invalid limit")
+		}
+	}
 
-	tasks, err := h.TaskService.GetTaskList(offset, completed, pageSize, date)
-	if err != nil && !errors.Is(err, service.ErrInvalidData) {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	completed, err := parseTriState(ctx, "completed")
+	if err != nil {
+		return entity.TaskFilter{}, err
+	}
+	blocked, err := parseTriState(ctx, "blocked")
+	if err != nil {
+		return entity.TaskFilter{}, err
+	}
+	overdue, err := parseTriState(ctx, "overdue")
+	if err != nil {
+		return entity.TaskFilter{}, err
 	}
 
-	if errors.Is(err, service.ErrInvalidData) {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+	var dueWithin time.Duration
+	if value := ctx.Query("due_within"); value != "" {
+		dueWithin, err = parseSpan(value)
+		if err != nil {
+			return entity.TaskFilter{}, errors.New("invalid due_within: use a number of hours, days or weeks such as 12h, 7d or 2w")
+		}
+	}
+
+	for _, name := range []string{"date", "from", "to"} {
+		if value := ctx.Query(name); value != "" && !validDay(value) {
+			return entity.TaskFilter{}, fmt.Errorf("invalid %s: use YYYY-MM-DD or today", name)
+		}
+	}
+
+	var projectID int
+	if project := ctx.Query("project"); project != "" {
+		var err error
+		projectID, err = strconv.Atoi(project)
+		if err != nil || projectID <= 0 {
+			return entity.TaskFilter{}, errors.New("invalid project")
+		}
+	}
+
+	sort, err := parseSort(ctx.Query("sort"))
+	if err != nil {
+		return entity.TaskFilter{}, err
+	}
+
+	return entity.TaskFilter{
+		ProjectID: projectID,
+		Query:     ctx.Query("q"),
+		Completed: completed,
+		Blocked:   blocked,
+		Overdue:   overdue,
+		DueWithin: dueWithin,
+		Date:      ctx.Query("date"),
+		From:      ctx.Query("from"),
+		To:        ctx.Query("to"),
+		Tags:      ctx.QueryArray("tag"),
+		TagMatch:  ctx.Query("tag_match"),
+		Sort:      sort,
+		Cursor:    ctx.Query("cursor"),
+		Offset:    (page - 1) * pageSize,
+		Limit:     pageSize,
+	}, nil
+}
+
+// parseSort reads a sort parameter such as "-priority,date". Field names are
+// checked by the service; only the syntax is checked here.
+func parseSort(value string) ([]entity.SortField, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(value, ",")
+	fields := make([]entity.SortField, 0, len(parts))
+	for _, part := range parts {
+		field := entity.SortField{Field: strings.TrimSpace(part)}
+		if strings.HasPrefix(field.Field, "-") {
+			field.Field = field.Field[1:]
+			field.Desc = true
+		}
+		if field.Field == "" {
+			return nil, errors.New("invalid sort")
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// parseTriState reads a filter parameter that is true, false or any. Any, like
+// leaving it out, does not filter.
+func parseTriState(ctx *gin.Context, name string) (*bool, error) {
+	value := ctx.Query(name)
+	if value == "" || value == "any" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: use true, false or any", name)
+	}
+
+	return &b, nil
+}
+
+// spanUnits are the units of a span parameter such as due_within.
+var spanUnits = map[byte]time.Duration{
+	'h': time.Hour,
+	'd': 24 * time.Hour,
+	'w': 7 * 24 * time.Hour,
+}
+
+// parseSpan reads a span such as 12h, 7d or 2w. Its bounds are checked by
+// the service.
+func parseSpan(value string) (time.Duration, error) {
+	if len(value) < 2 {
+		return 0, errors.New("span too short")
+	}
+
+	unit, ok := spanUnits[value[len(value)-1]]
+	if !ok {
+		return 0, errors.New("unknown span unit")
+	}
+
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n <= 0 || n > 100000 {
+		return 0, errors.New("invalid span length")
+	}
+
+	return time.Duration(n) * unit, nil
+}
+
+// validDay reports whether a day parameter is "today", YYYY-MM-DD or an RFC
+// 3339 timestamp, the forms the service reads a day from.
+func validDay(value string) bool {
+	if value == "today" {
+		return true
+	}
+
+	_, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		_, err = time.Parse(time.RFC3339, value)
 	}
 
-	ctx.JSON(http.StatusOK, tasks)
+	return err == nil
 }
@@ -2,13 +2,17 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"todo-list/internal/entity"
+	"todo-list/internal/service"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -17,54 +21,162 @@ type MockTaskService struct {
 	mock.Mock
 }
 
-func (m *MockTaskService) CreateTask(task *entity.Task) (int64, error) {
-	args := m.Called(task)
+func (m *MockTaskService) CreateTask(ctx context.Context, task *entity.Task) (int64, error) {
+	args := m.Called(ctx, task)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockTaskService) GetTask(id int) (*entity.Task, error) {
-	args := m.Called(id)
+func (m *MockTaskService) GetTask(ctx context.Context, id int) (*entity.Task, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(*entity.Task), args.Error(1)
 }
 
-func (m *MockTaskService) UpdateTask(id int, task *entity.Task) error {
-	args := m.Called(id, task)
+func (m *MockTaskService) UpdateTask(ctx context.Context, id int, task *entity.Task, force bool) error {
+	args := m.Called(ctx, id, task, force)
 	return args.Error(0)
 }
 
-func (m *MockTaskService) DeleteTask(id int) error {
-	args := m.Called(id)
+func (m *MockTaskService) PatchTask(ctx context.Context, id int, patch entity.TaskPatch, version int, force bool) (*entity.Task, error) {
+	args := m.Called(ctx, id, patch, version, force)
+	task, _ := args.Get(0).(*entity.Task)
+	return task, args.Error(1)
+}
+
+func (m *MockTaskService) JSONPatchTask(ctx context.Context, id int, ops []entity.PatchOperation, version int, force bool) (*entity.Task, error) {
+	args := m.Called(ctx, id, ops, version, force)
+	task, _ := args.Get(0).(*entity.Task)
+	return task, args.Error(1)
+}
+
+func (m *MockTaskService) DeleteTask(ctx context.Context, id int, version int) error {
+	args := m.Called(ctx, id, version)
+	return args.Error(0)
+}
+
+func (m *MockTaskService) GetTaskList(ctx context.Context, filter entity.TaskFilter) (*entity.TaskPage, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(*entity.TaskPage), args.Error(1)
+}
+
+func (m *MockTaskService) GetOccurrences(ctx context.Context, id int, from, to string) ([]time.Time, error) {
+	args := m.Called(ctx, id, from, to)
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+func (m *MockTaskService) GetChildren(ctx context.Context, id int, filter entity.TaskFilter) (*entity.TaskPage, error) {
+	args := m.Called(ctx, id, filter)
+	return args.Get(0).(*entity.TaskPage), args.Error(1)
+}
+
+func (m *MockTaskService) GetSubtree(ctx context.Context, id int) ([]*entity.Task, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]*entity.Task), args.Error(1)
+}
+
+func (m *MockTaskService) MoveTask(ctx context.Context, id int, parentID *int) error {
+	args := m.Called(ctx, id, parentID)
 	return args.Error(0)
 }
 
-func (m *MockTaskService) GetTaskList(offset int, completed string, pagesize int, date string) ([]*entity.Task, error) {
-	args := m.Called(offset, completed, pagesize, date)
+func (m *MockTaskService) GetDependencyGraph(ctx context.Context) (*entity.DependencyGraph, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*entity.DependencyGraph), args.Error(1)
+}
+
+func (m *MockTaskService) GetTrash(ctx context.Context) ([]*entity.Task, error) {
+	args := m.Called(ctx)
 	return args.Get(0).([]*entity.Task), args.Error(1)
 }
 
+func (m *MockTaskService) RestoreTask(ctx context.Context, id int) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockTaskService) GetTaskHistory(ctx context.Context, id int, cursor string, limit int) (*entity.TaskHistory, error) {
+	args := m.Called(ctx, id, cursor, limit)
+	return args.Get(0).(*entity.TaskHistory), args.Error(1)
+}
+
+func (m *MockTaskService) GetTaskVersion(ctx context.Context, id int, version int) (*entity.Task, error) {
+	args := m.Called(ctx, id, version)
+	return args.Get(0).(*entity.Task), args.Error(1)
+}
+
+func (m *MockTaskService) GetTaskAsOf(ctx context.Context, id int, at time.Time) (*entity.Task, error) {
+	args := m.Called(ctx, id, at)
+	return args.Get(0).(*entity.Task), args.Error(1)
+}
+
+func (m *MockTaskService) RevertTask(ctx context.Context, id int, version int, expected int, force bool) (*entity.Task, error) {
+	args := m.Called(ctx, id, version, expected, force)
+	return args.Get(0).(*entity.Task), args.Error(1)
+}
+
+func (m *MockTaskService) UpdateTasks(ctx context.Context, filter entity.TaskFilter, patch entity.TaskPatch, force bool, dryRun bool) (*entity.BulkResult, error) {
+	args := m.Called(ctx, filter, patch, force, dryRun)
+	return args.Get(0).(*entity.BulkResult), args.Error(1)
+}
+
+func (m *MockTaskService) DeleteTasks(ctx context.Context, filter entity.TaskFilter, dryRun bool) (*entity.BulkResult, error) {
+	args := m.Called(ctx, filter, dryRun)
+	return args.Get(0).(*entity.BulkResult), args.Error(1)
+}
+
+func (m *MockTaskService) ExportTasks(ctx context.Context, filter entity.TaskFilter, w io.Writer) error {
+	args := m.Called(ctx, filter, w)
+	return args.Error(0)
+}
+
+func (m *MockTaskService) ImportTasks(ctx context.Context, file io.Reader, mapping map[string]string, dryRun bool) (*entity.ImportResult, error) {
+	args := m.Called(ctx, file, mapping, dryRun)
+	return args.Get(0).(*entity.ImportResult), args.Error(1)
+}
+
+func (m *MockTaskService) RunBatch(ctx context.Context, ops []entity.BatchOperation, bestEffort bool) (*entity.TaskBatch, error) {
+	args := m.Called(ctx, ops, bestEffort)
+	return args.Get(0).(*entity.TaskBatch), args.Error(1)
+}
+
 func setupRouter(h *Handler) *gin.Engine {
 	r := gin.Default()
 
 	gin.SetMode(gin.ReleaseMode)
 	r.POST("task", h.CreateTask)
+	r.POST("task/batch", h.RunBatch)
+	r.POST("task/import", h.ImportTasks)
+	r.GET("task/export.csv", h.ExportTasks)
 	r.GET("task/:id", h.GetTask)
+	r.GET("task/:id/occurrences", h.GetOccurrences)
+	r.GET("task/:id/children", h.GetChildren)
+	r.GET("task/:id/subtree", h.GetSubtree)
+	r.GET("task/:id/history", h.GetTaskHistory)
+	r.GET("task/:id/versions/:version", h.GetTaskVersion)
+	r.POST("task/:id/revert", h.RevertTask)
+	r.PUT("task/:id/parent", h.MoveTask)
 	r.PUT("task/:id", h.UpdateTask)
+	r.PATCH("task/:id", h.PatchTask)
 	r.DELETE("task/:id", h.DeleteTask)
+	r.POST("task/:id/restore", h.RestoreTask)
 	r.GET("task", h.GetTaskList)
+	r.PATCH("task", h.UpdateTasks)
+	r.DELETE("task", h.DeleteTasks)
+	r.GET("task/graph", h.GetDependencyGraph)
+	r.GET("trash", h.GetTrash)
 
 	return r
 }
 
 func TestCreateTask(t *testing.T) {
 	mockService := new(MockTaskService)
-	handler := NewHandler(mockService)
+	handler := NewHandler(mockService, nil, nil, nil)
 	router := setupRouter(handler)
 
 	task := &entity.Task{
 		Title:       "Test Task",
 		Description: "Test Description",
 	}
-	mockService.On("CreateTask", task).Return(int64(1), nil)
+	mockService.On("CreateTask", mock.Anything, task).Return(int64(1), nil)
 
 	w := httptest.NewRecorder()
 	body, _ := json.Marshal(task)
@@ -77,7 +189,7 @@ func TestCreateTask(t *testing.T) {
 
 func TestGetTask(t *testing.T) {
 	mockService := new(MockTaskService)
-	handler := NewHandler(mockService)
+	handler := NewHandler(mockService, nil, nil, nil)
 	router := setupRouter(handler)
 
 	task := &entity.Task{
@@ -85,7 +197,7 @@ func TestGetTask(t *testing.T) {
 		Title:       "Test Task",
 		Description: "Test Description",
 	}
-	mockService.On("GetTask", 1).Return(task, nil)
+	mockService.On("GetTask", mock.Anything, 1).Return(task, nil)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/task/1", nil)
@@ -100,14 +212,14 @@ func TestGetTask(t *testing.T) {
 
 func TestUpdateTask(t *testing.T) {
 	mockService := new(MockTaskService)
-	handler := NewHandler(mockService)
+	handler := NewHandler(mockService, nil, nil, nil)
 	router := setupRouter(handler)
 
 	task := &entity.Task{
 		Title:       "Updated Task",
 		Description: "Updated Description",
 	}
-	mockService.On("UpdateTask", 1, task).Return(nil)
+	mockService.On("UpdateTask", mock.Anything, 1, task, false).Return(nil)
 
 	w := httptest.NewRecorder()
 	body, _ := json.Marshal(task)
@@ -120,10 +232,10 @@ func TestUpdateTask(t *testing.T) {
 
 func TestDeleteTask(t *testing.T) {
 	mockService := new(MockTaskService)
-	handler := NewHandler(mockService)
+	handler := NewHandler(mockService, nil, nil, nil)
 	router := setupRouter(handler)
 
-	mockService.On("DeleteTask", 1).Return(nil)
+	mockService.On("DeleteTask", mock.Anything, 1, 0).Return(nil)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("DELETE", "/task/1", nil)
@@ -135,7 +247,7 @@ func TestDeleteTask(t *testing.T) {
 
 func TestGetTaskList(t *testing.T) {
 	mockService := new(MockTaskService)
-	handler := NewHandler(mockService)
+	handler := NewHandler(mockService, nil, nil, nil)
 	router := setupRouter(handler)
 
 	tasks := []*entity.Task{
@@ -150,15 +262,194 @@ func TestGetTaskList(t *testing.T) {
 			Description: "Test Description 2",
 		},
 	}
-	mockService.On("GetTaskList", 0, "", 10, "").Return(tasks, nil)
+	page := &entity.TaskPage{Tasks: tasks, NextCursor: "next"}
+	mockService.On("GetTaskList", mock.Anything, entity.TaskFilter{Limit: 10}).Return(page, nil)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/task?page=1&pageSize=10", nil)
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	var responseTasks []*entity.Task
-	_ = json.Unmarshal(w.Body.Bytes(), &responseTasks)
-	assert.Equal(t, tasks, responseTasks)
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	var responsePage entity.TaskPage
+	_ = json.Unmarshal(w.Body.Bytes(), &responsePage)
+	assert.Equal(t, *page, responsePage)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTaskList_Cursor(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupRouter(NewHandler(mockService, nil, nil, nil))
+
+	mockService.On("GetTaskList", mock.Anything, entity.TaskFilter{Cursor: "abc.def", Limit: 25}).Return(&entity.TaskPage{Tasks: []*entity.Task{}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task?cursor=abc.def&limit=25", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.JSONEq(t, `{"tasks":[]}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/task?limit=many", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTask_NotFound(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	mockService.On("GetTask", mock.Anything, 1).Return((*entity.Task)(nil), service.ErrNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task/1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTask_Timeout(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	mockService.On("GetTask", mock.Anything, 1).Return((*entity.Task)(nil), service.ErrTimeout)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task/1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTaskList_Sort(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupRouter(NewHandler(mockService, nil, nil, nil))
+
+	sort := []entity.SortField{{Field: "priority", Desc: true}, {Field: "due_at"}}
+	mockService.On("GetTaskList", mock.Anything, entity.TaskFilter{Sort: sort, Limit: 10}).Return(&entity.TaskPage{Tasks: []*entity.Task{}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task?sort=-priority,due_at", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/task?sort=priority,,date", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateTask_Priority(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupRouter(NewHandler(mockService, nil, nil, nil))
+
+	mockService.On("CreateTask", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+		return task.Priority == entity.PriorityHigh
+	})).Return(int64(1), nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/task", bytes.NewBufferString(`{"title":"Task","date":"2024-01-01T00:00:00Z","priority":"high"}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/task", bytes.NewBufferString(`{"title":"Task","date":"2024-01-01T00:00:00Z","priority":"whenever"}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetOccurrences(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	occurrences := []time.Time{
+		time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 8, 9, 0, 0, 0, time.UTC),
+	}
+	mockService.On("GetOccurrences", mock.Anything, 1, "2024-01-01", "2024-01-08").Return(occurrences, nil)
+	mockService.On("GetOccurrences", mock.Anything, 2, "", "").Return([]time.Time(nil), service.ErrNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task/1/occurrences?from=2024-01-01&to=2024-01-08", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `["2024-01-01T09:00:00Z","2024-01-08T09:00:00Z"]`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/task/2/occurrences", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTaskList_Query(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	mockService.On("GetTaskList", mock.Anything, entity.TaskFilter{Query: "buy milk", Limit: 10}).Return(&entity.TaskPage{Tasks: []*entity.Task{{ID: 1, Snippet: "<b>Buy</b> <b>milk</b>"}}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task?q=buy+milk", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"snippet":"\u003cb\u003eBuy\u003c/b\u003e \u003cb\u003emilk\u003c/b\u003e"`)
+	mockService.AssertExpectations(t)
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestGetTaskList_TypedFilters(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupRouter(NewHandler(mockService, nil, nil, nil))
+
+	mockService.On("GetTaskList", mock.Anything, entity.TaskFilter{Completed: boolPtr(false), Overdue: boolPtr(false), DueWithin: 7 * 24 * time.Hour, From: "today", To: "2024-01-31", Limit: 10}).Return(&entity.TaskPage{Tasks: []*entity.Task{}}, nil)
+	mockService.On("GetTaskList", mock.Anything, entity.TaskFilter{Overdue: boolPtr(true), Limit: 10}).Return(&entity.TaskPage{Tasks: []*entity.Task{}}, nil)
+
+	for _, query := range []string{
+		"completed=false&overdue=0&due_within=7d&from=today&to=2024-01-31",
+		"completed=any&blocked=any&overdue=true",
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/task?"+query, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, query)
+	}
+
+	for query, param := range map[string]string{
+		"completed=maybe":   "completed",
+		"blocked=sometimes": "blocked",
+		"overdue=yes":       "overdue",
+		"due_within=7":      "due_within",
+		"due_within=7m":     "due_within",
+		"due_within=-1d":    "due_within",
+		"from=2024-13-01":   "from",
+		"to=tomorrow":       "to",
+		"date=01/02/2024":   "date",
+		"project=inbox":     "project",
+		"limit=ten":         "limit",
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/task?"+query, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.Contains(t, w.Body.String(), "invalid "+param, query)
+	}
 	mockService.AssertExpectations(t)
 }
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// GetTaskHistory godoc
+//
+//	@Summary		Get task history
+//	@Description	Get the writes to a task, newest first: who made each, when, and the fields it changed with their values before and after. Follow next_cursor with the cursor parameter for older events.
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id		path		int		true	"Task ID"
+//	@Param			cursor	query		string	false	"Cursor of the page to fetch, from next_cursor"
+//	@Param			limit	query		int		false	"Number of events per page, at most 1000"	default(20)
+//	@Success		200		{object}	entity.TaskHistory
+//	@Failure		400		{object}	map[string]string
+//	@Failure		401		{object}	map[string]string
+//	@Failure		404		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Failure		504		{object}	map[string]string
+//	@Router			/task/{id}/history [get]
+func (h *Handler) GetTaskHistory(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit := 0
+	if value := ctx.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	history, err := h.TaskService.GetTaskHistory(ctx.Request.Context(), id, ctx.Query("cursor"), limit)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTaskHistory(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	history := &entity.TaskHistory{
+		Events:     []*entity.TaskEvent{{ID: 3, TaskID: 1, ActorID: 1, Operation: entity.EventUpdate, Changes: []entity.FieldChange{{Field: "title", Before: []byte(`"Old"`), After: []byte(`"New"`)}}}},
+		NextCursor: "3",
+	}
+	mockService.On("GetTaskHistory", mock.Anything, 1, "9", 1).Return(history, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task/1/history?cursor=9&limit=1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"changes":[{"field":"title","before":"Old","after":"New"}]`)
+	assert.Contains(t, w.Body.String(), `"next_cursor":"3"`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/task/1/history?limit=none", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
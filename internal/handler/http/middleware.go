@@ -0,0 +1,19 @@
+package http
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// timeoutMiddleware bounds every request with a deadline that is carried
+// down to the repository through the request context.
+func timeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		c, cancel := context.WithTimeout(ctx.Request.Context(), timeout)
+		defer cancel()
+
+		ctx.Request = ctx.Request.WithContext(c)
+		ctx.Next()
+	}
+}
@@ -14,13 +14,52 @@ func StartListening(cfg *configs.Config, h *handler.Handler) {
 	r := gin.Default()
 	gin.SetMode(gin.ReleaseMode)
 
-	r.POST("task", h.CreateTask)
-	r.GET("task/:id", h.GetTask)
-	r.PUT("task/:id", h.UpdateTask)
-	r.DELETE("task/:id", h.DeleteTask)
-	r.GET("task", h.GetTaskList)
+	// Exports and imports run for longer than a request may take, so each
+	// gets a deadline of its own.
+	timed := r.Group("", timeoutMiddleware(cfg.RequestTimeout))
+	export := r.Group("", timeoutMiddleware(cfg.ExportTimeout), h.Authenticate, h.TimeZone)
+	export.GET("task/export.csv", h.ExportTasks)
+	imports := r.Group("", timeoutMiddleware(cfg.ImportTimeout), h.Authenticate, h.TimeZone)
+	imports.POST("task/import", h.ImportTasks)
 
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	timed.POST("auth/register", h.Register)
+	timed.POST("auth/login", h.Login)
+
+	authorized := timed.Group("", h.Authenticate, h.TimeZone)
+	authorized.GET("me", h.GetCurrentUser)
+	authorized.PUT("me/settings", h.UpdateSettings)
+
+	authorized.POST("task", h.CreateTask)
+	authorized.POST("task/batch", h.RunBatch)
+	authorized.GET("task/:id", h.GetTask)
+	authorized.GET("task/:id/occurrences", h.GetOccurrences)
+	authorized.GET("task/:id/children", h.GetChildren)
+	authorized.GET("task/:id/subtree", h.GetSubtree)
+	authorized.GET("task/:id/history", h.GetTaskHistory)
+	authorized.GET("task/:id/versions/:version", h.GetTaskVersion)
+	authorized.POST("task/:id/revert", h.RevertTask)
+	authorized.PUT("task/:id/parent", h.MoveTask)
+	authorized.PUT("task/:id", h.UpdateTask)
+	authorized.PATCH("task/:id", h.PatchTask)
+	authorized.DELETE("task/:id", h.DeleteTask)
+	authorized.POST("task/:id/restore", h.RestoreTask)
+	authorized.GET("task", h.GetTaskList)
+	authorized.PATCH("task", h.UpdateTasks)
+	authorized.DELETE("task", h.DeleteTasks)
+	authorized.GET("task/graph", h.GetDependencyGraph)
+	authorized.GET("trash", h.GetTrash)
+
+	authorized.POST("projects", h.CreateProject)
+	authorized.GET("projects", h.GetProjectList)
+	authorized.GET("projects/:id", h.GetProject)
+	authorized.PUT("projects/:id", h.UpdateProject)
+	authorized.DELETE("projects/:id", h.DeleteProject)
+
+	authorized.GET("tags", h.GetTagList)
+	authorized.PUT("tags/:id", h.RenameTag)
+	authorized.POST("tags/:id/merge", h.MergeTag)
+
+	timed.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	err := r.Run(cfg.Port)
 	if err != nil {
 		log.Fatal(err)
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"todo-list/internal/entity"
+)
+
+const (
+	// mergePatchType is the media type of a JSON Merge Patch (RFC 7396).
+	mergePatchType = "application/merge-patch+json"
+	// jsonPatchType is the media type of a JSON Patch (RFC 6902).
+	jsonPatchType = "application/json-patch+json"
+)
+
+// PatchTask godoc
+//
+//	@Summary		Patch a task
+//	@Description	Change some fields of a task with a JSON Merge Patch (RFC 7396): members present are set, null resets a field, and absent ones stay as they are.
+//	@Description	Alternatively send a JSON Patch (RFC 6902), an array of add, remove, replace and test operations such as {"op": "add", "path": "/tags/-", "value": "work"}. The operations apply all or not at all, and a failed test answers 409.
+//	@Description	Either way the patched task is validated like a full update, and only the changed fields are written. With If-Match the task is only patched while still at that version.
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Accept			application/merge-patch+json
+//	@Accept			application/json-patch+json
+//	@Produce		json
+//	@Param			id			path		int				true	"Task ID"
+//	@Param			patch		body		entity.Task		true	"Members of the task to change, or a list of JSON Patch operations"
+//	@Param			force		query		bool			false	"Complete the task even with open subtasks"	default(false)
+//	@Param			X-Timezone	header		string			false	"Time zone of all-day tasks, defaults to the user's setting"
+//	@Param			If-Match	header		string			false	"ETag of the version to patch"
+//	@Success		200			{object}	entity.Task
+//	@Header			200			{string}	ETag	"New version of the task"
+//	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
+//	@Failure		404			{object}	map[string]string
+//	@Failure		409			{object}	map[string]string
+//	@Failure		412			{object}	map[string]string
+//	@Failure		415			{object}	map[string]string
+//	@Failure		428			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Failure		504			{object}	map[string]string
+//	@Router			/task/{id} [patch]
+func (h *Handler) PatchTask(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	force, err := strconv.ParseBool(ctx.DefaultQuery("force", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid force"})
+		return
+	}
+
+	version, err := h.ifMatch(ctx, id)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	var task *entity.Task
+	switch ctx.ContentType() {
+	case mergePatchType:
+		var patch entity.TaskPatch
+		err = json.NewDecoder(ctx.Request.Body).Decode(&patch)
+		if err != nil || patch == nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "a merge patch of a task must be a JSON object"})
+			return
+		}
+
+		task, err = h.TaskService.PatchTask(ctx.Request.Context(), id, patch, version, force)
+	case jsonPatchType:
+		var ops []entity.PatchOperation
+		err = json.NewDecoder(ctx.Request.Body).Decode(&ops)
+		if err != nil || ops == nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "a JSON patch must be an array of operations"})
+			return
+		}
+
+		task, err = h.TaskService.JSONPatchTask(ctx.Request.Context(), id, ops, version, force)
+	default:
+		ctx.Header("Accept-Patch", mergePatchType+", "+jsonPatchType)
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "patches must be sent as " + mergePatchType + " or " + jsonPatchType})
+		return
+	}
+
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	setETag(ctx, task.Version)
+	ctx.JSON(http.StatusOK, task)
+}
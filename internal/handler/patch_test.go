@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestPatchTask(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupRouter(NewHandler(mockService, nil, nil, nil))
+
+	patch := entity.TaskPatch{"completed": []byte("true"), "project_id": []byte("null")}
+	mockService.On("PatchTask", mock.Anything, 1, patch, 0, true).Return(&entity.Task{ID: 1, Title: "Task", Completed: true}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/task/1?force=true", strings.NewReader(`{"completed":true,"project_id":null}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json; charset=utf-8")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"completed":true`)
+	mockService.AssertExpectations(t)
+}
+
+func TestPatchTask_BadRequests(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupRouter(NewHandler(mockService, nil, nil, nil))
+
+	mockService.On("PatchTask", mock.Anything, 2, mock.Anything, 0, false).Return(nil, service.ErrNotFound)
+
+	for _, tc := range []struct {
+		path, contentType, body string
+		code                    int
+	}{
+		{"/task/1", "application/json", `{"title":"New"}`, http.StatusUnsupportedMediaType},
+		{"/task/1", "application/json-patch+json", `{"title":"New"}`, http.StatusBadRequest},
+		{"/task/1", "application/json-patch+json", `null`, http.StatusBadRequest},
+		{"/task/1", "application/merge-patch+json", `["title"]`, http.StatusBadRequest},
+		{"/task/1", "application/merge-patch+json", `null`, http.StatusBadRequest},
+		{"/task/1?force=maybe", "application/merge-patch+json", `{}`, http.StatusBadRequest},
+		{"/task/2", "application/merge-patch+json", `{"title":"New"}`, http.StatusNotFound},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PATCH", tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", tc.contentType)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tc.code, w.Code, tc.body)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestPatchTask_JSONPatch(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupRouter(NewHandler(mockService, nil, nil, nil))
+
+	ops := []entity.PatchOperation{
+		{Op: "test", Path: "/title", Value: []byte(`"Task"`)},
+		{Op: "add", Path: "/tags/-", Value: []byte(`"work"`)},
+	}
+	mockService.On("JSONPatchTask", mock.Anything, 1, ops, 0, false).Return(&entity.Task{ID: 1, Title: "Task", Tags: []string{"work"}}, nil)
+	mockService.On("JSONPatchTask", mock.Anything, 2, mock.Anything, 0, false).Return(nil, service.ErrTestFailed)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/task/1", strings.NewReader(`[{"op":"test","path":"/title","value":"Task"},{"op":"add","path":"/tags/-","value":"work"}]`))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"tags":["work"]`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PATCH", "/task/2", strings.NewReader(`[{"op":"test","path":"/title","value":"Other"}]`))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestPatchTask_UnsupportedMediaType(t *testing.T) {
+	router := setupRouter(NewHandler(new(MockTaskService), nil, nil, nil))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/task/1", strings.NewReader(`{"title":"New"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "application/merge-patch+json, application/json-patch+json", w.Header().Get("Accept-Patch"))
+}
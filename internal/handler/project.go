@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"todo-list/internal/entity"
+)
+
+type ProjectService interface {
+	CreateProject(ctx context.Context, project *entity.Project) (int64, error)
+	GetProject(ctx context.Context, id int) (*entity.Project, error)
+	GetProjectList(ctx context.Context) ([]*entity.Project, error)
+	UpdateProject(ctx context.Context, id int, project *entity.Project) error
+	DeleteProject(ctx context.Context, id int, cascade bool) error
+}
+
+// CreateProject godoc
+//
+//	@Summary		Create a project
+//	@Description	Create a new project to group tasks
+//	@Tags			projects
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			project	body		entity.Project	true	"Project"
+//	@Success		201		{object}	map[string]int64
+//	@Failure		400		{object}	map[string]string
+//	@Failure		401		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Failure		504		{object}	map[string]string
+//	@Router			/projects [post]
+func (h *Handler) CreateProject(ctx *gin.Context) {
+	var project entity.Project
+
+	err := ctx.ShouldBindJSON(&project)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := h.ProjectService.CreateProject(ctx.Request.Context(), &project)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+// GetProject godoc
+//
+//	@Summary		Get a project
+//	@Description	Get a project by ID
+//	@Tags			projects
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		int	true	"Project ID"
+//	@Success		200	{object}	entity.Project
+//	@Failure		400	{object}	map[string]string
+//	@Failure		401	{object}	map[string]string
+//	@Failure		404	{object}	map[string]string
+//	@Failure		500	{object}	map[string]string
+//	@Failure		504	{object}	map[string]string
+//	@Router			/projects/{id} [get]
+func (h *Handler) GetProject(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	project, err := h.ProjectService.GetProject(ctx.Request.Context(), id)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, project)
+}
+
+// GetProjectList godoc
+//
+//	@Summary		Get project list
+//	@Description	Get every project of the caller
+//	@Tags			projects
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{array}		entity.Project
+//	@Failure		401	{object}	map[string]string
+//	@Failure		500	{object}	map[string]string
+//	@Failure		504	{object}	map[string]string
+//	@Router			/projects [get]
+func (h *Handler) GetProjectList(ctx *gin.Context) {
+	projects, err := h.ProjectService.GetProjectList(ctx.Request.Context())
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, projects)
+}
+
+// UpdateProject godoc
+//
+//	@Summary		Update a project
+//	@Description	Rename a project by ID
+//	@Tags			projects
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int				true	"Project ID"
+//	@Param			project	body		entity.Project	true	"Project"
+//	@Success		200		{object}	map[string]int
+//	@Failure		400		{object}	map[string]string
+//	@Failure		401		{object}	map[string]string
+//	@Failure		404		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Failure		504		{object}	map[string]string
+//	@Router			/projects/{id} [put]
+func (h *Handler) UpdateProject(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var project entity.Project
+	err = ctx.ShouldBindJSON(&project)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.ProjectService.UpdateProject(ctx.Request.Context(), id, &project)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+// DeleteProject godoc
+//
+//	@Summary		Delete a project
+//	@Description	Delete a project by ID. Its tasks are moved to the inbox project, or to the trash with their subtasks when cascade is true.
+//	@Tags			projects
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id		path		int		true	"Project ID"
+//	@Param			cascade	query		bool	false	"Move the project's tasks to the trash"	default(false)
+//	@Success		200		{object}	map[string]int
+//	@Failure		400		{object}	map[string]string
+//	@Failure		401		{object}	map[string]string
+//	@Failure		404		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Failure		504		{object}	map[string]string
+//	@Router			/projects/{id} [delete]
+func (h *Handler) DeleteProject(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cascade, err := strconv.ParseBool(ctx.DefaultQuery("cascade", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid cascade"})
+		return
+	}
+
+	err = h.ProjectService.DeleteProject(ctx.Request.Context(), id, cascade)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"id": id})
+}
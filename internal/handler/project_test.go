@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+type MockProjectService struct {
+	mock.Mock
+}
+
+func (m *MockProjectService) CreateProject(ctx context.Context, project *entity.Project) (int64, error) {
+	args := m.Called(ctx, project)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockProjectService) GetProject(ctx context.Context, id int) (*entity.Project, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*entity.Project), args.Error(1)
+}
+
+func (m *MockProjectService) GetProjectList(ctx context.Context) ([]*entity.Project, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*entity.Project), args.Error(1)
+}
+
+func (m *MockProjectService) UpdateProject(ctx context.Context, id int, project *entity.Project) error {
+	args := m.Called(ctx, id, project)
+	return args.Error(0)
+}
+
+func (m *MockProjectService) DeleteProject(ctx context.Context, id int, cascade bool) error {
+	args := m.Called(ctx, id, cascade)
+	return args.Error(0)
+}
+
+func setupProjectRouter(h *Handler) *gin.Engine {
+	r := gin.Default()
+
+	gin.SetMode(gin.ReleaseMode)
+	r.POST("projects", h.CreateProject)
+	r.GET("projects", h.GetProjectList)
+	r.GET("projects/:id", h.GetProject)
+	r.PUT("projects/:id", h.UpdateProject)
+	r.DELETE("projects/:id", h.DeleteProject)
+
+	return r
+}
+
+func TestCreateProject(t *testing.T) {
+	mockService := new(MockProjectService)
+	router := setupProjectRouter(NewHandler(nil, nil, mockService, nil))
+
+	project := &entity.Project{Name: "Home"}
+	mockService.On("CreateProject", mock.Anything, project).Return(int64(1), nil)
+
+	body, _ := json.Marshal(project)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/projects", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"id":1}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestGetProject_NotFound(t *testing.T) {
+	mockService := new(MockProjectService)
+	router := setupProjectRouter(NewHandler(nil, nil, mockService, nil))
+
+	mockService.On("GetProject", mock.Anything, 1).Return((*entity.Project)(nil), service.ErrProjectNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/projects/1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteProject(t *testing.T) {
+	mockService := new(MockProjectService)
+	router := setupProjectRouter(NewHandler(nil, nil, mockService, nil))
+
+	mockService.On("DeleteProject", mock.Anything, 1, false).Return(nil)
+	mockService.On("DeleteProject", mock.Anything, 2, true).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("DELETE", "/projects/1", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/projects/2?cascade=true", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/projects/3?cascade=maybe", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTaskList_ProjectFilter(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupRouter(NewHandler(mockService, nil, nil, nil))
+
+	mockService.On("GetTaskList", mock.Anything, entity.TaskFilter{ProjectID: 3, Limit: 10}).Return(&entity.TaskPage{Tasks: []*entity.Task{}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task?project=3", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/task?project=home", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type revertTaskRequest struct {
+	Version int `json:"version" example:"2"`
+}
+
+// getTaskAsOf answers GET /task/{id}?as_of=: the task as it was at a point in
+// time. It carries no ETag, as only the current version can be written to.
+func (h *Handler) getTaskAsOf(ctx *gin.Context, id int, asOf string) {
+	at, err := time.Parse(time.RFC3339, asOf)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid as_of"})
+		return
+	}
+
+	task, err := h.TaskService.GetTaskAsOf(ctx.Request.Context(), id, at)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, task)
+}
+
+// GetTaskVersion godoc
+//
+//	@Summary		Get a task version
+//	@Description	Get a task exactly as it was at one of its versions, as listed in its history. Writes recorded before snapshots were kept have none and answer 404.
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id		path		int	true	"Task ID"
+//	@Param			version	path		int	true	"Version of the task"
+//	@Success		200		{object}	entity.Task
+//	@Failure		400		{object}	map[string]string
+//	@Failure		401		{object}	map[string]string
+//	@Failure		404		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Failure		504		{object}	map[string]string
+//	@Router			/task/{id}/versions/{version} [get]
+func (h *Handler) GetTaskVersion(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid version"})
+		return
+	}
+
+	task, err := h.TaskService.GetTaskVersion(ctx.Request.Context(), id, version)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, task)
+}
+
+// RevertTask godoc
+//
+//	@Summary		Revert a task
+//	@Description	Write an earlier version of a task back as its next version. The old version is validated like a full update: one whose project, parent or blockers are gone cannot be restored, and completing the task is guarded the same way.
+//	@Description	With If-Match the task is only reverted while still at that version.
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		int					true	"Task ID"
+//	@Param			revert		body		revertTaskRequest	true	"Version to restore"
+//	@Param			force		query		bool				false	"Complete the task even with open subtasks"	default(false)
+//	@Param			X-Timezone	header		string				false	"Time zone of all-day tasks, defaults to the user's setting"
+//	@Param			If-Match	header		string				false	"ETag of the version to replace"
+//	@Success		200			{object}	entity.Task
+//	@Header			200			{string}	ETag	"New version of the task"
+//	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
+//	@Failure		404			{object}	map[string]string
+//	@Failure		409			{object}	map[string]string
+//	@Failure		412			{object}	map[string]string
+//	@Failure		428			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Failure		504			{object}	map[string]string
+//	@Router			/task/{id}/revert [post]
+func (h *Handler) RevertTask(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	force, err := strconv.ParseBool(ctx.DefaultQuery("force", "false"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid force"})
+		return
+	}
+
+	expected, err := h.ifMatch(ctx, id)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	var request revertTaskRequest
+	err = ctx.ShouldBindJSON(&request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	task, err := h.TaskService.RevertTask(ctx.Request.Context(), id, request.Version, expected, force)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	setETag(ctx, task.Version)
+	ctx.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTask_AsOf(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	at := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	mockService.On("GetTaskAsOf", mock.Anything, 1, mock.MatchedBy(at.Equal)).Return(&entity.Task{ID: 1, Title: "Old", Version: 2}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task/1?as_of=2024-01-01T10:00:00%2B01:00", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"title":"Old"`)
+	assert.Empty(t, w.Header().Get("ETag"))
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/task/1?as_of=yesterday", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTaskVersion(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	mockService.On("GetTaskVersion", mock.Anything, 1, 2).Return(&entity.Task{ID: 1, Title: "Old", Version: 2}, nil)
+	mockService.On("GetTaskVersion", mock.Anything, 1, 9).Return((*entity.Task)(nil), service.ErrVersionNotFound)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task/1/versions/2", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"version":2`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/task/1/versions/9", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRevertTask(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	mockService.On("RevertTask", mock.Anything, 1, 2, 4, true).Return(&entity.Task{ID: 1, Title: "Old", Version: 5}, nil)
+	mockService.On("RevertTask", mock.Anything, 1, 2, 3, false).Return((*entity.Task)(nil), service.ErrVersionMismatch)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/task/1/revert?force=true", bytes.NewBufferString(`{"version": 2}`))
+	req.Header.Set("If-Match", `"4"`)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"5"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"title":"Old"`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/task/1/revert", bytes.NewBufferString(`{"version": 2}`))
+	req.Header.Set("If-Match", `"3"`)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type moveTaskRequest struct {
+	ParentID *int `json:"parent_id" example:"1"`
+}
+
+// GetChildren godoc
+//
+//	@Summary		Get subtasks
+//	@Description	Get the direct subtasks of a task, with the filters and paging of the task list
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id			path		int			true	"Task ID"
+//	@Param			cursor		query		string		false	"Cursor of the page to fetch, from next_cursor or prev_cursor"
+//	@Param			limit		query		int			false	"Number of tasks per page, at most 1000"	default(10)
+//	@Param			page		query		int			false	"Deprecated: page number, use cursor"
+//	@Param			pageSize	query		int			false	"Deprecated: number of tasks per page, use limit"
+//	@Param			completed	query		string		false	"Filter by completion status"
+//	@Param			sort		query		string		false	"Comma-separated sort fields; prefix with - for descending"
+//	@Success		200			{object}	entity.TaskPage
+//	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
+//	@Failure		404			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Failure		504			{object}	map[string]string
+//	@Router			/task/{id}/children [get]
+func (h *Handler) GetChildren(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := taskFilter(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.TaskService.GetChildren(ctx.Request.Context(), id, filter)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// GetSubtree godoc
+//
+//	@Summary		Get a task tree
+//	@Description	Get a task followed by all of its descendants; parent_id links them into a tree
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		int	true	"Task ID"
+//	@Success		200	{array}		entity.Task
+//	@Failure		400	{object}	map[string]string
+//	@Failure		401	{object}	map[string]string
+//	@Failure		404	{object}	map[string]string
+//	@Failure		500	{object}	map[string]string
+//	@Failure		504	{object}	map[string]string
+//	@Router			/task/{id}/subtree [get]
+func (h *Handler) GetSubtree(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tasks, err := h.TaskService.GetSubtree(ctx.Request.Context(), id)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tasks)
+}
+
+// MoveTask godoc
+//
+//	@Summary		Reparent a task
+//	@Description	Move a task under another task, or to the top level with a null parent_id. A task cannot move into its own subtree.
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int				true	"Task ID"
+//	@Param			parent	body		moveTaskRequest	true	"New parent"
+//	@Success		200		{object}	map[string]int
+//	@Failure		400		{object}	map[string]string
+//	@Failure		401		{object}	map[string]string
+//	@Failure		404		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Failure		504		{object}	map[string]string
+//	@Router			/task/{id}/parent [put]
+func (h *Handler) MoveTask(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var request moveTaskRequest
+	err = ctx.ShouldBindJSON(&request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.TaskService.MoveTask(ctx.Request.Context(), id, request.ParentID)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"id": id})
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetChildren(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	mockService.On("GetChildren", mock.Anything, 1, entity.TaskFilter{Completed: boolPtr(false), Offset: 10, Limit: 10}).Return(&entity.TaskPage{Tasks: []*entity.Task{}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task/1/children?completed=false&page=2", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetSubtree(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	parent := 1
+	mockService.On("GetSubtree", mock.Anything, 1).Return([]*entity.Task{{ID: 1}, {ID: 2, ParentID: &parent}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task/1/subtree", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"parent_id":1`)
+	mockService.AssertExpectations(t)
+}
+
+func TestMoveTask(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	parent := 2
+	mockService.On("MoveTask", mock.Anything, 1, &parent).Return(nil)
+	mockService.On("MoveTask", mock.Anything, 1, (*int)(nil)).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/task/1/parent", bytes.NewBufferString(`{"parent_id":2}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/task/1/parent", bytes.NewBufferString(`{"parent_id":null}`))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateTask_Force(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	task := &entity.Task{Title: "Parent", DueAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Completed: true}
+	mockService.On("UpdateTask", mock.Anything, 1, task, false).Return(service.ErrOpenSubtasks)
+	mockService.On("UpdateTask", mock.Anything, 1, task, true).Return(nil)
+
+	body := `{"title":"Parent","due_at":"2024-01-01T00:00:00Z","completed":true}`
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/task/1", bytes.NewBufferString(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/task/1?force=true", bytes.NewBufferString(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/task/1?force=maybe", bytes.NewBufferString(body))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"todo-list/internal/entity"
+)
+
+type TagService interface {
+	GetTagList(ctx context.Context) ([]*entity.Tag, error)
+	RenameTag(ctx context.Context, id int, name string) error
+	MergeTag(ctx context.Context, id int, into int) error
+}
+
+type renameTagRequest struct {
+	Name string `json:"name" example:"errands"`
+}
+
+type mergeTagRequest struct {
+	Into int `json:"into" example:"2"`
+}
+
+// GetTagList godoc
+//
+//	@Summary		Get tag list
+//	@Description	Get every tag of the caller with the number of tasks carrying it
+//	@Tags			tags
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{array}		entity.Tag
+//	@Failure		401	{object}	map[string]string
+//	@Failure		500	{object}	map[string]string
+//	@Failure		504	{object}	map[string]string
+//	@Router			/tags [get]
+func (h *Handler) GetTagList(ctx *gin.Context) {
+	tags, err := h.TagService.GetTagList(ctx.Request.Context())
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tags)
+}
+
+// RenameTag godoc
+//
+//	@Summary		Rename a tag
+//	@Description	Rename a tag on every task carrying it
+//	@Tags			tags
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int					true	"Tag ID"
+//	@Param			tag		body		renameTagRequest	true	"New name"
+//	@Success		200		{object}	map[string]int
+//	@Failure		400		{object}	map[string]string
+//	@Failure		401		{object}	map[string]string
+//	@Failure		404		{object}	map[string]string
+//	@Failure		409		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Failure		504		{object}	map[string]string
+//	@Router			/tags/{id} [put]
+func (h *Handler) RenameTag(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var request renameTagRequest
+	err = ctx.ShouldBindJSON(&request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.TagService.RenameTag(ctx.Request.Context(), id, request.Name)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"id": id})
+}
+
+// MergeTag godoc
+//
+//	@Summary		Merge a tag
+//	@Description	Move every task carrying the tag over to another tag, then delete it
+//	@Tags			tags
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		int				true	"Tag ID"
+//	@Param			target	body		mergeTagRequest	true	"Tag to merge into"
+//	@Success		200		{object}	map[string]int
+//	@Failure		400		{object}	map[string]string
+//	@Failure		401		{object}	map[string]string
+//	@Failure		404		{object}	map[string]string
+//	@Failure		500		{object}	map[string]string
+//	@Failure		504		{object}	map[string]string
+//	@Router			/tags/{id}/merge [post]
+func (h *Handler) MergeTag(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var request mergeTagRequest
+	err = ctx.ShouldBindJSON(&request)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.TagService.MergeTag(ctx.Request.Context(), id, request.Into)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"id": request.Into})
+}
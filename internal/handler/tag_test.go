@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+type MockTagService struct {
+	mock.Mock
+}
+
+func (m *MockTagService) GetTagList(ctx context.Context) ([]*entity.Tag, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*entity.Tag), args.Error(1)
+}
+
+func (m *MockTagService) RenameTag(ctx context.Context, id int, name string) error {
+	args := m.Called(ctx, id, name)
+	return args.Error(0)
+}
+
+func (m *MockTagService) MergeTag(ctx context.Context, id int, into int) error {
+	args := m.Called(ctx, id, into)
+	return args.Error(0)
+}
+
+func setupTagRouter(h *Handler) *gin.Engine {
+	r := gin.Default()
+
+	gin.SetMode(gin.ReleaseMode)
+	r.GET("tags", h.GetTagList)
+	r.PUT("tags/:id", h.RenameTag)
+	r.POST("tags/:id/merge", h.MergeTag)
+
+	return r
+}
+
+func TestGetTagList(t *testing.T) {
+	mockService := new(MockTagService)
+	router := setupTagRouter(NewHandler(nil, nil, nil, mockService))
+
+	mockService.On("GetTagList", mock.Anything).Return([]*entity.Tag{{ID: 1, OwnerID: 1, Name: "home", Count: 2}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tags", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"id":1,"name":"home","count":2}]`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestRenameTag_Conflict(t *testing.T) {
+	mockService := new(MockTagService)
+	router := setupTagRouter(NewHandler(nil, nil, nil, mockService))
+
+	mockService.On("RenameTag", mock.Anything, 1, "work").Return(service.ErrTagExists)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/tags/1", bytes.NewBufferString(`{"name":"work"}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestMergeTag(t *testing.T) {
+	mockService := new(MockTagService)
+	router := setupTagRouter(NewHandler(nil, nil, nil, mockService))
+
+	mockService.On("MergeTag", mock.Anything, 1, 2).Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tags/1/merge", bytes.NewBufferString(`{"into":2}`))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTaskList_TagFilter(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupRouter(NewHandler(mockService, nil, nil, nil))
+
+	mockService.On("GetTaskList", mock.Anything, entity.TaskFilter{Tags: []string{"home", "work"}, TagMatch: "all", Limit: 10}).Return(&entity.TaskPage{Tasks: []*entity.Task{}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task?tag=home&tag=work&tag_match=all", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// GetTrash godoc
+//
+//	@Summary		Get the trash
+//	@Description	Get the deleted tasks, most recently deleted first. They are purged for good once the retention period has passed.
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{array}		entity.Task
+//	@Failure		401	{object}	map[string]string
+//	@Failure		500	{object}	map[string]string
+//	@Failure		504	{object}	map[string]string
+//	@Router			/trash [get]
+func (h *Handler) GetTrash(ctx *gin.Context) {
+	tasks, err := h.TaskService.GetTrash(ctx.Request.Context())
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tasks)
+}
+
+// RestoreTask godoc
+//
+//	@Summary		Restore a task
+//	@Description	Take a task out of the trash, together with the subtasks deleted along with it. A subtask cannot be restored while its parent is in the trash.
+//	@Tags			tasks
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Param			id	path		int	true	"Task ID"
+//	@Success		200	{object}	map[string]int
+//	@Failure		400	{object}	map[string]string
+//	@Failure		401	{object}	map[string]string
+//	@Failure		404	{object}	map[string]string
+//	@Failure		409	{object}	map[string]string
+//	@Failure		500	{object}	map[string]string
+//	@Failure		504	{object}	map[string]string
+//	@Router			/task/{id}/restore [post]
+func (h *Handler) RestoreTask(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.TaskService.RestoreTask(ctx.Request.Context(), id)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"id": id})
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTrash(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	deletedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	mockService.On("GetTrash", mock.Anything).Return([]*entity.Task{{ID: 1, DeletedAt: &deletedAt}}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/trash", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"deleted_at":"2024-01-01T09:00:00Z"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestRestoreTask(t *testing.T) {
+	mockService := new(MockTaskService)
+	handler := NewHandler(mockService, nil, nil, nil)
+	router := setupRouter(handler)
+
+	mockService.On("RestoreTask", mock.Anything, 1).Return(nil)
+	mockService.On("RestoreTask", mock.Anything, 2).Return(service.ErrParentInTrash)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/task/1/restore", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/task/2/restore", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+type UserService interface {
+	Register(ctx context.Context, credentials *entity.Credentials) (int64, error)
+	Login(ctx context.Context, credentials *entity.Credentials) (*entity.Token, error)
+	ParseToken(token string) (*entity.User, error)
+	GetCurrentUser(ctx context.Context) (*entity.User, error)
+	UpdateSettings(ctx context.Context, settings *entity.Settings) error
+}
+
+// Register godoc
+//
+//	@Summary		Register a user
+//	@Description	Create a new user account
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			credentials	body		entity.Credentials	true	"Credentials"
+//	@Success		201			{object}	map[string]int64
+//	@Failure		400			{object}	map[string]string
+//	@Failure		409			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Failure		504			{object}	map[string]string
+//	@Router			/auth/register [post]
+func (h *Handler) Register(ctx *gin.Context) {
+	var credentials entity.Credentials
+
+	err := ctx.ShouldBindJSON(&credentials)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := h.UserService.Register(ctx.Request.Context(), &credentials)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"id": id})
+}
+
+// Login godoc
+//
+//	@Summary		Log in
+//	@Description	Exchange credentials for an access token
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			credentials	body		entity.Credentials	true	"Credentials"
+//	@Success		200			{object}	entity.Token
+//	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Failure		504			{object}	map[string]string
+//	@Router			/auth/login [post]
+func (h *Handler) Login(ctx *gin.Context) {
+	var credentials entity.Credentials
+
+	err := ctx.ShouldBindJSON(&credentials)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.UserService.Login(ctx.Request.Context(), &credentials)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, token)
+}
+
+// Authenticate rejects requests without a valid bearer token and puts the
+// token's user into the request context for the service layer.
+func (h *Handler) Authenticate(ctx *gin.Context) {
+	scheme, token, ok := strings.Cut(ctx.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		ctx.Header("WWW-Authenticate", "Bearer")
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": service.ErrUnauthorized.Error()})
+		return
+	}
+
+	user, err := h.UserService.ParseToken(token)
+	if err != nil {
+		ctx.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Request = ctx.Request.WithContext(service.WithUser(ctx.Request.Context(), user))
+	ctx.Next()
+}
+
+// TimeZone reads the X-Timezone header, when sent, into the request context
+// so days in this request are read in that zone instead of the user's own.
+func (h *Handler) TimeZone(ctx *gin.Context) {
+	name := ctx.GetHeader("X-Timezone")
+	if name == "" {
+		ctx.Next()
+		return
+	}
+
+	loc, err := service.LoadTimeZone(name)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Request = ctx.Request.WithContext(service.WithLocation(ctx.Request.Context(), loc))
+	ctx.Next()
+}
+
+// GetCurrentUser godoc
+//
+//	@Summary		Get the current user
+//	@Description	Get the account of the caller
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Produce		json
+//	@Success		200	{object}	entity.User
+//	@Failure		401	{object}	map[string]string
+//	@Failure		500	{object}	map[string]string
+//	@Failure		504	{object}	map[string]string
+//	@Router			/me [get]
+func (h *Handler) GetCurrentUser(ctx *gin.Context) {
+	user, err := h.UserService.GetCurrentUser(ctx.Request.Context())
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// UpdateSettings godoc
+//
+//	@Summary		Update settings
+//	@Description	Change the caller's time zone, used to read days in task filters and all-day tasks
+//	@Tags			auth
+//	@Security		BearerAuth
+//	@Accept			json
+//	@Produce		json
+//	@Param			settings	body		entity.Settings	true	"Settings"
+//	@Success		200			{object}	entity.Settings
+//	@Failure		400			{object}	map[string]string
+//	@Failure		401			{object}	map[string]string
+//	@Failure		500			{object}	map[string]string
+//	@Failure		504			{object}	map[string]string
+//	@Router			/me/settings [put]
+func (h *Handler) UpdateSettings(ctx *gin.Context) {
+	var settings entity.Settings
+
+	err := ctx.ShouldBindJSON(&settings)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.UserService.UpdateSettings(ctx.Request.Context(), &settings)
+	if err != nil {
+		errorResponse(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+type MockUserService struct {
+	mock.Mock
+}
+
+func (m *MockUserService) Register(ctx context.Context, credentials *entity.Credentials) (int64, error) {
+	args := m.Called(ctx, credentials)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserService) Login(ctx context.Context, credentials *entity.Credentials) (*entity.Token, error) {
+	args := m.Called(ctx, credentials)
+	return args.Get(0).(*entity.Token), args.Error(1)
+}
+
+func (m *MockUserService) ParseToken(token string) (*entity.User, error) {
+	args := m.Called(token)
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserService) GetCurrentUser(ctx context.Context) (*entity.User, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserService) UpdateSettings(ctx context.Context, settings *entity.Settings) error {
+	args := m.Called(ctx, settings)
+	return args.Error(0)
+}
+
+func setupAuthRouter(h *Handler) *gin.Engine {
+	r := gin.Default()
+
+	gin.SetMode(gin.ReleaseMode)
+	r.POST("auth/register", h.Register)
+	r.POST("auth/login", h.Login)
+	r.GET("me", h.Authenticate, func(ctx *gin.Context) {
+		user, _ := service.UserFromContext(ctx.Request.Context())
+		ctx.JSON(http.StatusOK, user)
+	})
+
+	return r
+}
+
+func TestRegister(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupAuthRouter(NewHandler(nil, mockService, nil, nil))
+
+	credentials := &entity.Credentials{Username: "john", Password: "password1"}
+	mockService.On("Register", mock.Anything, credentials).Return(int64(1), nil)
+
+	w := httptest.NewRecorder()
+	body, _ := json.Marshal(credentials)
+	req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRegister_UserExists(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupAuthRouter(NewHandler(nil, mockService, nil, nil))
+
+	credentials := &entity.Credentials{Username: "john", Password: "password1"}
+	mockService.On("Register", mock.Anything, credentials).Return(int64(-1), service.ErrUserExists)
+
+	w := httptest.NewRecorder()
+	body, _ := json.Marshal(credentials)
+	req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestLogin(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupAuthRouter(NewHandler(nil, mockService, nil, nil))
+
+	credentials := &entity.Credentials{Username: "john", Password: "password1"}
+	token := &entity.Token{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 3600}
+	mockService.On("Login", mock.Anything, credentials).Return(token, nil)
+
+	w := httptest.NewRecorder()
+	body, _ := json.Marshal(credentials)
+	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var responseToken entity.Token
+	_ = json.Unmarshal(w.Body.Bytes(), &responseToken)
+	assert.Equal(t, token, &responseToken)
+	mockService.AssertExpectations(t)
+}
+
+func TestLogin_InvalidCredentials(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupAuthRouter(NewHandler(nil, mockService, nil, nil))
+
+	credentials := &entity.Credentials{Username: "john", Password: "wrong"}
+	mockService.On("Login", mock.Anything, credentials).Return((*entity.Token)(nil), service.ErrInvalidCredentials)
+
+	w := httptest.NewRecorder()
+	body, _ := json.Marshal(credentials)
+	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAuthenticate(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupAuthRouter(NewHandler(nil, mockService, nil, nil))
+
+	user := &entity.User{ID: 1, Username: "john"}
+	mockService.On("ParseToken", "good").Return(user, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer good")
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var responseUser entity.User
+	_ = json.Unmarshal(w.Body.Bytes(), &responseUser)
+	assert.Equal(t, user.ID, responseUser.ID)
+	mockService.AssertExpectations(t)
+}
+
+func TestAuthenticate_Rejected(t *testing.T) {
+	mockService := new(MockUserService)
+	router := setupAuthRouter(NewHandler(nil, mockService, nil, nil))
+
+	mockService.On("ParseToken", "bad").Return((*entity.User)(nil), service.ErrUnauthorized)
+
+	for _, header := range []string{"", "Basic abc", "Bearer", "Bearer bad"} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/me", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code, header)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestTimeZone(t *testing.T) {
+	r := gin.Default()
+	r.GET("tz", (&Handler{}).TimeZone, func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/tz", nil)
+	req.Header.Set("X-Timezone", "Europe/Berlin")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/tz", nil)
+	req.Header.Set("X-Timezone", "Mars/Olympus")
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdateSettings(t *testing.T) {
+	mockService := new(MockUserService)
+	r := gin.Default()
+	r.PUT("me/settings", NewHandler(nil, mockService, nil, nil).UpdateSettings)
+
+	mockService.On("UpdateSettings", mock.Anything, &entity.Settings{TimeZone: "Mars/Olympus"}).Return(service.ErrInvalidData)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/me/settings", bytes.NewBufferString(`{"time_zone":"Mars/Olympus"}`))
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+	"todo-list/internal/service"
+)
+
+var errInvalidIfMatch = fmt.Errorf("%w: invalid If-Match", service.ErrInvalidData)
+
+// ifMatch reads the version of task id an If-Match header names: 0 without
+// the header and service.AnyVersion for "*". A list of entity tags is compared
+// strongly, so weak tags never match; when it names several versions the
+// task is read to pick the current one, and ErrVersionMismatch is returned
+// when none is.
+func (h *Handler) ifMatch(ctx *gin.Context, id int) (int, error) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	switch header {
+	case "":
+		return 0, nil
+	case "*":
+		return service.AnyVersion, nil
+	}
+
+	tags, err := strongETags(header)
+	if err != nil {
+		return 0, err
+	}
+
+	var versions []int
+	for _, tag := range tags {
+		version, err := strconv.Atoi(tag)
+		if err == nil && version > 0 {
+			versions = append(versions, version)
+		}
+	}
+
+	switch len(versions) {
+	case 0:
+		return 0, service.ErrVersionMismatch
+	case 1:
+		return versions[0], nil
+	}
+
+	task, err := h.TaskService.GetTask(ctx.Request.Context(), id)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, version := range versions {
+		if version == task.Version {
+			return version, nil
+		}
+	}
+
+	return 0, service.ErrVersionMismatch
+}
+
+// strongETags parses a comma-separated list of entity tags and returns the
+// opaque values of its strong ones.
+func strongETags(header string) ([]string, error) {
+	var tags []string
+	parsed := 0
+	rest := header
+	for {
+		rest = strings.TrimLeft(rest, " \t,")
+		if rest == "" {
+			if parsed == 0 {
+				return nil, errInvalidIfMatch
+			}
+			return tags, nil
+		}
+
+		weak := strings.HasPrefix(rest, "W/")
+		rest = strings.TrimPrefix(rest, "W/")
+		if !strings.HasPrefix(rest, `"`) {
+			return nil, errInvalidIfMatch
+		}
+
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return nil, errInvalidIfMatch
+		}
+
+		if !weak {
+			tags = append(tags, rest[1:end+1])
+		}
+		parsed++
+
+		rest = strings.TrimLeft(rest[end+2:], " \t")
+		if rest != "" && !strings.HasPrefix(rest, ",") {
+			return nil, errInvalidIfMatch
+		}
+	}
+}
+
+// setETag sends a task version as the response's ETag.
+func setETag(ctx *gin.Context, version int) {
+	ctx.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTask_ETag(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupRouter(NewHandler(mockService, nil, nil, nil))
+
+	mockService.On("GetTask", mock.Anything, 1).Return(&entity.Task{ID: 1, Title: "Task", Version: 3}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/task/1", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+	assert.Contains(t, w.Body.String(), `"version":3`)
+}
+
+func TestIfMatch(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupRouter(NewHandler(mockService, nil, nil, nil))
+
+	mockService.On("DeleteTask", mock.Anything, 1, 3).Return(nil)
+	mockService.On("DeleteTask", mock.Anything, 1, service.AnyVersion).Return(nil)
+	mockService.On("DeleteTask", mock.Anything, 1, 2).Return(service.ErrVersionMismatch)
+	mockService.On("DeleteTask", mock.Anything, 1, 0).Return(service.ErrVersionRequired)
+	mockService.On("GetTask", mock.Anything, 1).Return(&entity.Task{ID: 1, Title: "Task", Version: 3}, nil)
+
+	for _, tc := range []struct {
+		header string
+		code   int
+	}{
+		{`"3"`, http.StatusOK},
+		{`*`, http.StatusOK},
+		{`"2"`, http.StatusPreconditionFailed},
+		{``, http.StatusPreconditionRequired},
+		{`3`, http.StatusBadRequest},
+		{`W/"3"`, http.StatusPreconditionFailed},
+		{`"0"`, http.StatusPreconditionFailed},
+		{`"2", "3"`, http.StatusOK},
+		{`"1","2"`, http.StatusPreconditionFailed},
+		{`W/"3", "2"`, http.StatusPreconditionFailed},
+		{`W/"3", "3"`, http.StatusOK},
+		{`"3", 4`, http.StatusBadRequest},
+		{`"3" "4"`, http.StatusBadRequest},
+		{`*, "3"`, http.StatusBadRequest},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("DELETE", "/task/1", nil)
+		req.Header.Set("If-Match", tc.header)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, tc.code, w.Code, tc.header)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateTask_IfMatch(t *testing.T) {
+	mockService := new(MockTaskService)
+	router := setupRouter(NewHandler(mockService, nil, nil, nil))
+
+	mockService.On("UpdateTask", mock.Anything, 1, mock.MatchedBy(func(task *entity.Task) bool { return task.Version == 3 }), false).
+		Run(func(args mock.Arguments) { args.Get(2).(*entity.Task).Version = 4 }).
+		Return(nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/task/1", strings.NewReader(`{"title":"Task","due_at":"2024-01-01T09:00:00Z","version":9}`))
+	req.Header.Set("If-Match", `"3"`)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+	mockService.AssertExpectations(t)
+}
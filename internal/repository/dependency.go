@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"todo-list/internal/entity"
+)
+
+// openBlockerCondition matches tasks with at least one blocker not yet
+// completed. Blockers in the trash no longer count.
+const openBlockerCondition = "EXISTS (SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id WHERE d.task_id = tasks.id AND NOT b.completed AND b.deleted_at IS NULL)"
+
+// addTaskBlockers records the tasks blocking a task.
+func addTaskBlockers(ctx context.Context, tx dbTx, taskID int64, blockers []int) error {
+	for _, blockerID := range blockers {
+		_, err := tx.ExecContext(ctx, "INSERT INTO task_dependencies(task_id, blocker_id) VALUES ($1, $2)", taskID, blockerID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replaceTaskBlockers sets the live tasks blocking a task to blockers. The
+// dependencies on blockers in the trash are kept, as loadBlockers leaves them
+// out of the task they were read with and restoring them brings them back.
+func replaceTaskBlockers(ctx context.Context, tx dbTx, taskID int64, blockers []int) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM task_dependencies WHERE task_id = $1 AND blocker_id IN (SELECT id FROM tasks WHERE deleted_at IS NULL)", taskID)
+	if err != nil {
+		return err
+	}
+
+	return addTaskBlockers(ctx, tx, taskID, blockers)
+}
+
+// loadBlockers fills in the blockers of every task with a single query,
+// leaving out blockers in the trash.
+func loadBlockers(ctx context.Context, q querier, tasks []*entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*entity.Task, len(tasks))
+	args := make([]interface{}, len(tasks))
+	placeholders := make([]string, len(tasks))
+	for i, task := range tasks {
+		byID[task.ID] = task
+		args[i] = task.ID
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	rows, err := q.QueryContext(ctx, "SELECT d.task_id, d.blocker_id FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id WHERE d.task_id IN ("+strings.Join(placeholders, ", ")+") AND b.deleted_at IS NULL ORDER BY d.blocker_id", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID, blockerID int
+		err = rows.Scan(&taskID, &blockerID)
+		if err != nil {
+			return err
+		}
+
+		if task, ok := byID[taskID]; ok {
+			task.BlockedBy = append(task.BlockedBy, blockerID)
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *Repository) GetDependencies(ctx context.Context, ownerID int) ([]entity.Dependency, error) {
+	rows, err := r.QueryContext(ctx, "SELECT d.task_id, d.blocker_id FROM task_dependencies d JOIN tasks t ON t.id = d.task_id JOIN tasks b ON b.id = d.blocker_id WHERE t.owner_id = $1 AND t.deleted_at IS NULL AND b.deleted_at IS NULL ORDER BY d.task_id, d.blocker_id", ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dependencies []entity.Dependency
+	for rows.Next() {
+		var dependency entity.Dependency
+		err = rows.Scan(&dependency.TaskID, &dependency.BlockerID)
+		if err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	return dependencies, rows.Err()
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+// actorID names who is writing: the user the request runs as, or the owner of
+// the task when there is none.
+func actorID(ctx context.Context, ownerID int) int {
+	if user, ok := service.UserFromContext(ctx); ok {
+		return user.ID
+	}
+
+	return ownerID
+}
+
+// recordEvent adds an entry to the history of task id, with a snapshot of
+// the task as the write being recorded left it.
+func recordEvent(ctx context.Context, tx dbTx, ownerID int, id int64, operation string, changes []entity.FieldChange) error {
+	if changes == nil {
+		changes = []entity.FieldChange{}
+	}
+
+	doc, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	task, err := scanTask(tx.QueryRowContext(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id = $1", id))
+	if err != nil {
+		return err
+	}
+
+	err = loadTaskDetails(ctx, tx, []*entity.Task{task})
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO task_events(task_id, actor_id, operation, version, changes, snapshot, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)", id, actorID(ctx, ownerID), operation, task.Version, string(doc), string(snapshot), time.Now().UTC())
+	return err
+}
+
+// recordEvents adds the same entry to the history of several tasks, given as
+// the id rows of an UPDATE ... RETURNING.
+func recordEvents(ctx context.Context, tx dbTx, ownerID int, rows *sql.Rows, operation string, changes []entity.FieldChange) error {
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		err := rows.Scan(&id)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, id := range ids {
+		err := recordEvent(ctx, tx, ownerID, id, operation, changes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkTaskOwner reports ErrNotFound unless the owner has task id, in the
+// trash or not.
+func (r *Repository) checkTaskOwner(ctx context.Context, ownerID int, id int) error {
+	var exists int
+	err := r.QueryRowContext(ctx, "SELECT 1 FROM tasks WHERE id = $1 AND owner_id = $2", id, ownerID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrNotFound
+	}
+
+	return err
+}
+
+func (r *Repository) GetTaskHistory(ctx context.Context, ownerID int, id int, before int, limit int) ([]*entity.TaskEvent, error) {
+	err := r.checkTaskOwner(ctx, ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT id, task_id, actor_id, operation, version, changes, created_at FROM task_events WHERE task_id = $1"
+	args := []any{id}
+	if before > 0 {
+		args = append(args, before)
+		query += fmt.Sprintf(" AND id < $%d", len(args))
+	}
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d", len(args))
+
+	rows, err := r.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entity.TaskEvent
+	for rows.Next() {
+		var event entity.TaskEvent
+		var changes string
+		err = rows.Scan(&event.ID, &event.TaskID, &event.ActorID, &event.Operation, &event.Version, &changes, &event.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		err = json.Unmarshal([]byte(changes), &event.Changes)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *Repository) GetTaskVersion(ctx context.Context, ownerID int, id int, version int) (*entity.Task, error) {
+	err := r.checkTaskOwner(ctx, ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot sql.NullString
+	err = r.QueryRowContext(ctx, "SELECT snapshot FROM task_events WHERE task_id = $1 AND version = $2 ORDER BY id DESC LIMIT 1", id, version).Scan(&snapshot)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrVersionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSnapshot(snapshot)
+}
+
+func (r *Repository) GetTaskAsOf(ctx context.Context, ownerID int, id int, at time.Time) (*entity.Task, error) {
+	err := r.checkTaskOwner(ctx, ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var operation string
+	var snapshot sql.NullString
+	err = r.QueryRowContext(ctx, "SELECT operation, snapshot FROM task_events WHERE task_id = $1 AND created_at <= $2 ORDER BY id DESC LIMIT 1", id, at.UTC()).Scan(&operation, &snapshot)
+	if errors.Is(err, sql.ErrNoRows) || operation == entity.EventDelete {
+		return nil, service.ErrVersionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSnapshot(snapshot)
+}
+
+// decodeSnapshot reads back a task stored by recordEvent. Events recorded
+// before snapshots were kept have none, and count as a missing version.
+func decodeSnapshot(snapshot sql.NullString) (*entity.Task, error) {
+	if !snapshot.Valid {
+		return nil, service.ErrVersionNotFound
+	}
+
+	var task entity.Task
+	err := json.Unmarshal([]byte(snapshot.String), &task)
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
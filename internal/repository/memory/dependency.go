@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"todo-list/internal/entity"
+)
+
+func (r *Repository) GetDependencies(ctx context.Context, ownerID int) ([]entity.Dependency, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var dependencies []entity.Dependency
+	for _, task := range r.tasks {
+		if task.OwnerID != ownerID || task.DeletedAt != nil {
+			continue
+		}
+		for _, blockerID := range r.liveBlockers(task.BlockedBy) {
+			dependencies = append(dependencies, entity.Dependency{TaskID: task.ID, BlockerID: blockerID})
+		}
+	}
+
+	slices.SortFunc(dependencies, func(a, b entity.Dependency) int {
+		if a.TaskID != b.TaskID {
+			return a.TaskID - b.TaskID
+		}
+		return a.BlockerID - b.BlockerID
+	})
+
+	return dependencies, nil
+}
+
+// blocked reports whether any blocker of task is still open. Blockers in the
+// trash no longer count. The caller holds mu.
+func (r *Repository) blocked(task entity.Task) bool {
+	for _, blockerID := range task.BlockedBy {
+		if blocker, ok := r.tasks[blockerID]; ok && !blocker.Completed && blocker.DeletedAt == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deleteTask removes a task with its history and the dependencies on it, as
+// the foreign keys cascade in SQL. The caller holds mu.
+func (r *Repository) deleteTask(id int) {
+	delete(r.tasks, id)
+	r.events = slices.DeleteFunc(r.events, func(event taskEvent) bool { return event.TaskID == id })
+
+	for taskID, task := range r.tasks {
+		if slices.Contains(task.BlockedBy, id) {
+			task.BlockedBy = slices.DeleteFunc(cloneBlockers(task.BlockedBy), func(blockerID int) bool { return blockerID == id })
+			r.tasks[taskID] = task
+		}
+	}
+}
+
+// liveBlockers copies blockers, leaving out the ones in the trash. The caller
+// holds mu.
+func (r *Repository) liveBlockers(blockers []int) []int {
+	var kept []int
+	for _, blockerID := range blockers {
+		if blocker, ok := r.tasks[blockerID]; ok && blocker.DeletedAt == nil {
+			kept = append(kept, blockerID)
+		}
+	}
+
+	return kept
+}
+
+// replaceBlockers returns blockers along with the blockers in the trash among
+// stored, which a write keeps as SQL does. The caller holds mu.
+func (r *Repository) replaceBlockers(stored []int, blockers []int) []int {
+	kept := cloneBlockers(blockers)
+	for _, blockerID := range stored {
+		if blocker, ok := r.tasks[blockerID]; ok && blocker.DeletedAt != nil && !slices.Contains(kept, blockerID) {
+			kept = append(kept, blockerID)
+		}
+	}
+
+	return kept
+}
+
+func cloneBlockers(blockers []int) []int {
+	return append([]int(nil), blockers...)
+}
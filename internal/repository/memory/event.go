@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"time"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+// recordEvent adds an entry to the history of task id, with a snapshot of
+// the task as stored now. The caller holds mu.
+func (r *Repository) recordEvent(ctx context.Context, ownerID int, id int, operation string, changes []entity.FieldChange) {
+	actorID := ownerID
+	if user, ok := service.UserFromContext(ctx); ok {
+		actorID = user.ID
+	}
+	if changes == nil {
+		changes = []entity.FieldChange{}
+	}
+
+	snapshot := r.tasks[id]
+	snapshot.Tags = cloneTags(snapshot.Tags)
+	snapshot.BlockedBy = r.liveBlockers(snapshot.BlockedBy)
+	snapshot.DeletedAt = nil
+
+	r.events = append(r.events, taskEvent{
+		TaskEvent: entity.TaskEvent{
+			ID:        r.nextEventID,
+			TaskID:    id,
+			ActorID:   actorID,
+			Operation: operation,
+			Version:   snapshot.Version,
+			Changes:   changes,
+			CreatedAt: time.Now().UTC(),
+		},
+		snapshot: snapshot,
+	})
+	r.nextEventID++
+}
+
+func (r *Repository) GetTaskHistory(ctx context.Context, ownerID int, id int, before int, limit int) ([]*entity.TaskEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if task, ok := r.tasks[id]; !ok || task.OwnerID != ownerID {
+		return nil, service.ErrNotFound
+	}
+
+	var events []*entity.TaskEvent
+	for i := len(r.events) - 1; i >= 0 && len(events) < limit; i-- {
+		event := r.events[i].TaskEvent
+		if event.TaskID != id || before > 0 && event.ID >= before {
+			continue
+		}
+
+		event.Changes = append([]entity.FieldChange(nil), event.Changes...)
+		events = append(events, &event)
+	}
+
+	return events, nil
+}
+
+func (r *Repository) GetTaskVersion(ctx context.Context, ownerID int, id int, version int) (*entity.Task, error) {
+	event, err := r.lastEvent(ctx, ownerID, id, func(event *taskEvent) bool { return event.Version == version })
+	if err != nil {
+		return nil, err
+	}
+
+	return event.copySnapshot(), nil
+}
+
+func (r *Repository) GetTaskAsOf(ctx context.Context, ownerID int, id int, at time.Time) (*entity.Task, error) {
+	event, err := r.lastEvent(ctx, ownerID, id, func(event *taskEvent) bool { return !event.CreatedAt.After(at) })
+	if err != nil {
+		return nil, err
+	}
+	if event.Operation == entity.EventDelete {
+		return nil, service.ErrVersionNotFound
+	}
+
+	return event.copySnapshot(), nil
+}
+
+// lastEvent returns the newest event of task id that matches, or
+// ErrVersionNotFound when none does.
+func (r *Repository) lastEvent(ctx context.Context, ownerID int, id int, match func(*taskEvent) bool) (*taskEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if task, ok := r.tasks[id]; !ok || task.OwnerID != ownerID {
+		return nil, service.ErrNotFound
+	}
+
+	for i := len(r.events) - 1; i >= 0; i-- {
+		event := r.events[i]
+		if event.TaskID == id && match(&event) {
+			return &event, nil
+		}
+	}
+
+	return nil, service.ErrVersionNotFound
+}
+
+func (event *taskEvent) copySnapshot() *entity.Task {
+	task := event.snapshot
+	task.Tags = cloneTags(task.Tags)
+	task.BlockedBy = cloneBlockers(task.BlockedBy)
+
+	return &task
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+// Repository keeps tasks in process memory. It is meant for demos and local
+// runs: nothing survives a restart.
+type Repository struct {
+	mu         sync.RWMutex
+	tasks      map[int]entity.Task
+	nextID     int
+	users      map[int]entity.User
+	nextUserID int
+
+	projects      map[int]entity.Project
+	nextProjectID int
+
+	tags      map[int]entity.Tag
+	nextTagID int
+
+	// events is the history of every task, oldest first.
+	events      []taskEvent
+	nextEventID int
+}
+
+// taskEvent is an entry of a task's history with the task as the write left
+// it.
+type taskEvent struct {
+	entity.TaskEvent
+	snapshot entity.Task
+}
+
+func NewRepository() *Repository {
+	return &Repository{
+		tasks:      make(map[int]entity.Task),
+		nextID:     1,
+		users:      make(map[int]entity.User),
+		nextUserID: 1,
+
+		projects:      make(map[int]entity.Project),
+		nextProjectID: 1,
+
+		tags:      make(map[int]entity.Tag),
+		nextTagID: 1,
+
+		nextEventID: 1,
+	}
+}
+
+// Transaction runs fn on a copy of the repository, which takes its place when
+// fn returns nil. Other calls wait for fn to return, as they would for the
+// write lock of a database.
+func (r *Repository) Transaction(ctx context.Context, fn func(repo service.Repository) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx := &Repository{
+		tasks:         maps.Clone(r.tasks),
+		nextID:        r.nextID,
+		users:         maps.Clone(r.users),
+		nextUserID:    r.nextUserID,
+		projects:      maps.Clone(r.projects),
+		nextProjectID: r.nextProjectID,
+		tags:          maps.Clone(r.tags),
+		nextTagID:     r.nextTagID,
+		events:        slices.Clone(r.events),
+		nextEventID:   r.nextEventID,
+	}
+
+	err := fn(tx)
+	if err != nil {
+		return err
+	}
+
+	r.tasks, r.nextID = tx.tasks, tx.nextID
+	r.users, r.nextUserID = tx.users, tx.nextUserID
+	r.projects, r.nextProjectID = tx.projects, tx.nextProjectID
+	r.tags, r.nextTagID = tx.tags, tx.nextTagID
+	r.events, r.nextEventID = tx.events, tx.nextEventID
+
+	return nil
+}
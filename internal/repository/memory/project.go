@@ -0,0 +1,175 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+func (r *Repository) InsertProject(ctx context.Context, project *entity.Project) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return int64(r.insertProject(*project)), nil
+}
+
+func (r *Repository) insertProject(project entity.Project) int {
+	project.ID = r.nextProjectID
+	r.projects[project.ID] = project
+	r.nextProjectID++
+
+	return project.ID
+}
+
+func (r *Repository) GetProject(ctx context.Context, ownerID int, id int) (*entity.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	project, ok := r.projects[id]
+	if !ok || project.OwnerID != ownerID {
+		return nil, service.ErrProjectNotFound
+	}
+
+	return &project, nil
+}
+
+func (r *Repository) GetProjectList(ctx context.Context, ownerID int) ([]*entity.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var projects []*entity.Project
+	for _, project := range r.projects {
+		if project.OwnerID != ownerID {
+			continue
+		}
+
+		project := project
+		projects = append(projects, &project)
+	}
+
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+
+	return projects, nil
+}
+
+func (r *Repository) UpdateProject(ctx context.Context, ownerID int, id int, project *entity.Project) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.projects[id]
+	if !ok || existing.OwnerID != ownerID {
+		return service.ErrProjectNotFound
+	}
+
+	existing.Name = project.Name
+	r.projects[id] = existing
+
+	return nil
+}
+
+func (r *Repository) DeleteProject(ctx context.Context, ownerID int, id int, moveTasksTo int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.projects[id]; !ok || existing.OwnerID != ownerID {
+		return service.ErrProjectNotFound
+	}
+
+	if moveTasksTo == 0 {
+		r.trashProject(ctx, ownerID, id)
+		delete(r.projects, id)
+		return nil
+	}
+
+	for taskID, task := range r.tasks {
+		if task.OwnerID != ownerID || task.ProjectID == nil || *task.ProjectID != id {
+			continue
+		}
+
+		target := moveTasksTo
+		changes, err := entity.TaskChanges(&task, &entity.Task{ProjectID: &target}, []string{"project_id"})
+		if err != nil {
+			return err
+		}
+		task.ProjectID = &target
+		task.Version++
+		r.tasks[taskID] = task
+		r.recordEvent(ctx, ownerID, taskID, entity.EventUpdate, changes)
+	}
+	delete(r.projects, id)
+
+	return nil
+}
+
+// trashProject moves the tasks of project id, and their subtasks wherever
+// they are, to the trash as DeleteTask would, and takes every task out of the
+// project, which is about to go. The caller holds mu.
+func (r *Repository) trashProject(ctx context.Context, ownerID int, id int) {
+	now := time.Now().UTC()
+	for taskID, task := range r.tasks {
+		if task.OwnerID != ownerID || task.ProjectID == nil || *task.ProjectID != id || task.DeletedAt != nil {
+			continue
+		}
+
+		for _, task := range r.subtree(ownerID, taskID, live) {
+			deletedAt := now
+			task.DeletedAt = &deletedAt
+			if task.ProjectID != nil && *task.ProjectID == id {
+				task.ProjectID = nil
+			}
+			task.Version++
+			r.tasks[task.ID] = *task
+			r.recordEvent(ctx, ownerID, task.ID, entity.EventDelete, nil)
+		}
+	}
+
+	// The tasks that were in the trash already keep their history as it is.
+	for taskID, task := range r.tasks {
+		if task.ProjectID != nil && *task.ProjectID == id {
+			task.ProjectID = nil
+			r.tasks[taskID] = task
+		}
+	}
+}
+
+func (r *Repository) EnsureInboxProject(ctx context.Context, ownerID int) (*entity.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, project := range r.projects {
+		if project.OwnerID == ownerID && project.Inbox {
+			return &project, nil
+		}
+	}
+
+	project := entity.Project{OwnerID: ownerID, Name: "Inbox", Inbox: true}
+	project.ID = r.insertProject(project)
+
+	return &project, nil
+}
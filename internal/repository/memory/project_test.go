@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjects(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	id, err := repo.InsertProject(ctx, &entity.Project{OwnerID: 1, Name: "Home"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+
+	assert.NoError(t, repo.UpdateProject(ctx, 1, 1, &entity.Project{Name: "House"}))
+	result, err := repo.GetProject(ctx, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "House", result.Name)
+
+	_, err = repo.GetProject(ctx, 2, 1)
+	assert.ErrorIs(t, err, service.ErrProjectNotFound)
+	assert.ErrorIs(t, repo.UpdateProject(ctx, 2, 1, &entity.Project{Name: "Hijacked"}), service.ErrProjectNotFound)
+	assert.ErrorIs(t, repo.DeleteProject(ctx, 2, 1, 0), service.ErrProjectNotFound)
+
+	inbox, err := repo.EnsureInboxProject(ctx, 1)
+	assert.NoError(t, err)
+	again, err := repo.EnsureInboxProject(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, inbox.ID, again.ID)
+
+	projects, err := repo.GetProjectList(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, projects, 2)
+}
+
+func TestDeleteProject(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	inbox, _ := repo.EnsureInboxProject(ctx, 1)
+	moved, cascaded := 2, 3
+	_, _ = repo.InsertProject(ctx, &entity.Project{OwnerID: 1, Name: "Moved"})
+	_, _ = repo.InsertProject(ctx, &entity.Project{OwnerID: 1, Name: "Cascaded"})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, ProjectID: &moved, Title: "Moved", DueAt: time.Now()})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, ProjectID: &cascaded, Title: "Cascaded", DueAt: time.Now()})
+
+	assert.NoError(t, repo.DeleteProject(ctx, 1, moved, inbox.ID))
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, ProjectID: inbox.ID, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, ids(result))
+
+	// A subtask in another project, and a task already in the trash.
+	parent := 2
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, ProjectID: &inbox.ID, ParentID: &parent, Title: "Subtask", DueAt: time.Now()})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, ProjectID: &cascaded, Title: "Trashed", DueAt: time.Now()})
+	assert.NoError(t, repo.DeleteTask(ctx, 1, 4, 0))
+
+	assert.NoError(t, repo.DeleteProject(ctx, 1, cascaded, 0))
+	_, err = repo.GetTask(ctx, 1, 2)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	trash, err := repo.GetTrash(ctx, 1)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int{2, 3, 4}, ids(trash))
+	history, err := repo.GetTaskHistory(ctx, 1, 2, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.EventDelete, history[0].Operation)
+
+	assert.NoError(t, repo.RestoreTask(ctx, 1, 2))
+	restored, err := repo.GetTask(ctx, 1, 2)
+	assert.NoError(t, err)
+	assert.Nil(t, restored.ProjectID)
+	subtask, err := repo.GetTask(ctx, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, inbox.ID, *subtask.ProjectID)
+	assert.NoError(t, repo.RestoreTask(ctx, 1, 4))
+	restored, err = repo.GetTask(ctx, 1, 4)
+	assert.NoError(t, err)
+	assert.Nil(t, restored.ProjectID)
+}
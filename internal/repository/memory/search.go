@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"html"
+	"strings"
+	"todo-list/internal/entity"
+	"unicode"
+)
+
+// snippetWords is how many words a search snippet spans at most.
+const snippetWords = 12
+
+// search scores task against the normalized words of a query, the way the
+// SQL engines do in spirit: every word must prefix a word of the title or
+// description, and title hits weigh double. A score of 0 means no match.
+func search(task entity.Task, query string) (int, string) {
+	terms := strings.Fields(query)
+	title := strings.Fields(task.Title)
+	description := strings.Fields(task.Description)
+
+	score := 0
+	for _, term := range terms {
+		hits := 2*countMatches(title, term) + countMatches(description, term)
+		if hits == 0 {
+			return 0, ""
+		}
+		score += hits
+	}
+
+	return score, snippet(append(title, description...), terms)
+}
+
+func countMatches(words []string, term string) int {
+	count := 0
+	for _, word := range words {
+		if matchesTerm(word, term) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// matchesTerm reports whether a word of text starts with term, ignoring case
+// and surrounding punctuation.
+func matchesTerm(word string, term string) bool {
+	word = strings.TrimFunc(strings.ToLower(word), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.HasPrefix(word, term)
+}
+
+// snippet cuts a window of words around the first match, escapes them for
+// HTML and wraps every matching word in <b></b>.
+func snippet(words []string, terms []string) string {
+	first := -1
+	highlighted := make([]string, len(words))
+	for i, word := range words {
+		highlighted[i] = html.EscapeString(word)
+		for _, term := range terms {
+			if matchesTerm(word, term) {
+				highlighted[i] = "<b>" + highlighted[i] + "</b>"
+				if first < 0 {
+					first = i
+				}
+				break
+			}
+		}
+	}
+
+	start := max(0, first-snippetWords/4)
+	end := min(len(words), start+snippetWords)
+	result := strings.Join(highlighted[start:end], " ")
+	if start > 0 {
+		result = "…" + result
+	}
+	if end < len(words) {
+		result += "…"
+	}
+
+	return result
+}
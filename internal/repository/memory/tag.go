@@ -0,0 +1,194 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+// ensureTags registers the owner's tags that do not exist yet and returns a
+// copy of tags safe to store. The caller must hold r.mu for writing.
+func (r *Repository) ensureTags(ownerID int, tags []string) []string {
+	for _, name := range tags {
+		if _, ok := r.findTag(ownerID, name); ok {
+			continue
+		}
+
+		r.tags[r.nextTagID] = entity.Tag{ID: r.nextTagID, OwnerID: ownerID, Name: name}
+		r.nextTagID++
+	}
+
+	return cloneTags(tags)
+}
+
+func (r *Repository) findTag(ownerID int, name string) (entity.Tag, bool) {
+	for _, tag := range r.tags {
+		if tag.OwnerID == ownerID && tag.Name == name {
+			return tag, true
+		}
+	}
+
+	return entity.Tag{}, false
+}
+
+func cloneTags(tags []string) []string {
+	return append([]string(nil), tags...)
+}
+
+// matchTags reports whether a task carrying tags passes a tag filter.
+func matchTags(tags []string, wanted []string, match string) bool {
+	found := 0
+	for _, name := range wanted {
+		if contains(tags, name) {
+			found++
+		}
+	}
+
+	if match == entity.TagMatchAll {
+		return found == len(wanted)
+	}
+
+	return found > 0
+}
+
+func (r *Repository) GetTagList(ctx context.Context, ownerID int) ([]*entity.Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, task := range r.tasks {
+		if task.OwnerID != ownerID || task.DeletedAt != nil {
+			continue
+		}
+		for _, name := range task.Tags {
+			counts[name]++
+		}
+	}
+
+	var tags []*entity.Tag
+	for _, tag := range r.tags {
+		if tag.OwnerID != ownerID {
+			continue
+		}
+
+		tag := tag
+		tag.Count = counts[tag.Name]
+		tags = append(tags, &tag)
+	}
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	return tags, nil
+}
+
+func (r *Repository) RenameTag(ctx context.Context, ownerID int, id int, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tag, ok := r.tags[id]
+	if !ok || tag.OwnerID != ownerID {
+		return service.ErrTagNotFound
+	}
+	if existing, ok := r.findTag(ownerID, name); ok && existing.ID != id {
+		return service.ErrTagExists
+	}
+
+	err := r.retag(ctx, ownerID, tag.Name, name)
+	if err != nil {
+		return err
+	}
+	tag.Name = name
+	r.tags[id] = tag
+
+	return nil
+}
+
+func (r *Repository) MergeTag(ctx context.Context, ownerID int, id int, into int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tag, ok := r.tags[id]
+	if !ok || tag.OwnerID != ownerID {
+		return service.ErrTagNotFound
+	}
+	target, ok := r.tags[into]
+	if !ok || target.OwnerID != ownerID {
+		return service.ErrTagNotFound
+	}
+
+	err := r.retag(ctx, ownerID, tag.Name, target.Name)
+	if err != nil {
+		return err
+	}
+	delete(r.tags, id)
+
+	return nil
+}
+
+// retag replaces the tag from with to on every task of the owner, keeping
+// each task's tags sorted and free of duplicates. Live tasks get a new
+// version and an entry in their history; tasks in the trash keep their
+// history as it is.
+func (r *Repository) retag(ctx context.Context, ownerID int, from string, to string) error {
+	for id, task := range r.tasks {
+		if task.OwnerID != ownerID {
+			continue
+		}
+
+		var tags []string
+		changed := false
+		for _, name := range task.Tags {
+			if name == from {
+				name = to
+				changed = true
+			}
+			if !contains(tags, name) {
+				tags = append(tags, name)
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		sort.Strings(tags)
+		before := task
+		task.Tags = tags
+		if task.DeletedAt != nil {
+			r.tasks[id] = task
+			continue
+		}
+
+		changes, err := entity.TaskChanges(&before, &task, []string{"tags"})
+		if err != nil {
+			return err
+		}
+		task.Version++
+		r.tasks[id] = task
+		r.recordEvent(ctx, ownerID, id, entity.EventUpdate, changes)
+	}
+
+	return nil
+}
+
+func contains(tags []string, name string) bool {
+	for _, tag := range tags {
+		if tag == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTags(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	for _, tags := range [][]string{{"home"}, {"home", "urgent"}, {"work"}} {
+		_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Task", DueAt: time.Now(), Tags: tags})
+	}
+
+	anyOf, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Tags: []string{"urgent", "work"}, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, ids(anyOf))
+
+	allOf, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Tags: []string{"home", "urgent"}, TagMatch: entity.TagMatchAll, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, ids(allOf))
+
+	tags, err := repo.GetTagList(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, tags, 3)
+	assert.Equal(t, "home", tags[0].Name)
+	assert.Equal(t, 2, tags[0].Count)
+
+	home, urgent, work := tags[0].ID, tags[1].ID, tags[2].ID
+	assert.ErrorIs(t, repo.RenameTag(ctx, 1, work, "home"), service.ErrTagExists)
+	assert.NoError(t, repo.RenameTag(ctx, 1, work, "office"))
+	result, _ := repo.GetTask(ctx, 1, 3)
+	assert.Equal(t, []string{"office"}, result.Tags)
+	assert.Equal(t, 2, result.Version)
+	history, err := repo.GetTaskHistory(ctx, 1, 3, 0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.FieldChange{{Field: "tags", Before: []byte(`["work"]`), After: []byte(`["office"]`)}}, history[0].Changes)
+
+	assert.NoError(t, repo.MergeTag(ctx, 1, urgent, home))
+	result, _ = repo.GetTask(ctx, 1, 2)
+	assert.Equal(t, []string{"home"}, result.Tags)
+
+	assert.ErrorIs(t, repo.MergeTag(ctx, 2, work, home), service.ErrTagNotFound)
+}
@@ -0,0 +1,580 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+func (r *Repository) InsertTask(ctx context.Context, task *entity.Task) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.insertTask(ctx, task)
+}
+
+// insertTask stores a copy of task under a new id. The caller holds mu.
+func (r *Repository) insertTask(ctx context.Context, task *entity.Task) (int64, error) {
+	changes, err := entity.TaskChanges(nil, task, nil)
+	if err != nil {
+		return -1, err
+	}
+
+	stored := *task
+	stored.ID = r.nextID
+	stored.Version = 1
+	stored.Tags = r.ensureTags(stored.OwnerID, stored.Tags)
+	stored.BlockedBy = cloneBlockers(stored.BlockedBy)
+	r.tasks[stored.ID] = stored
+	r.nextID++
+	r.recordEvent(ctx, stored.OwnerID, stored.ID, entity.EventCreate, changes)
+
+	return int64(stored.ID), nil
+}
+
+func (r *Repository) GetTask(ctx context.Context, ownerID int, id int) (*entity.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, ok := r.liveTask(ownerID, id)
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+	task.Tags = cloneTags(task.Tags)
+	task.BlockedBy = r.liveBlockers(task.BlockedBy)
+
+	return &task, nil
+}
+
+func (r *Repository) UpdateTask(ctx context.Context, ownerID int, id int, task *entity.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.updateTask(ctx, ownerID, id, task)
+}
+
+func (r *Repository) PatchTask(ctx context.Context, ownerID int, id int, task *entity.Task, fields []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.liveTask(ownerID, id)
+	if !ok {
+		return service.ErrNotFound
+	}
+	blockers := stored.BlockedBy
+	stored.BlockedBy = r.liveBlockers(stored.BlockedBy)
+	before := stored
+
+	err := bumpVersion(&stored, task)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		switch field {
+		case "project_id":
+			stored.ProjectID = task.ProjectID
+		case "parent_id":
+			stored.ParentID = task.ParentID
+		case "title":
+			stored.Title = task.Title
+		case "description":
+			stored.Description = task.Description
+		case "due_at":
+			stored.DueAt = task.DueAt
+		case "all_day":
+			stored.AllDay = task.AllDay
+		case "completed":
+			stored.Completed = task.Completed
+		case "priority":
+			stored.Priority = task.Priority
+		case "tags":
+			stored.Tags = r.ensureTags(ownerID, task.Tags)
+		case "blocked_by":
+			stored.BlockedBy = task.BlockedBy
+		case "recurrence":
+			stored.Recurrence = task.Recurrence
+		default:
+			return fmt.Errorf("unknown task field %q", field)
+		}
+	}
+
+	changes, err := entity.TaskChanges(&before, &stored, fields)
+	if err != nil {
+		return err
+	}
+	stored.BlockedBy = r.replaceBlockers(blockers, stored.BlockedBy)
+	r.tasks[id] = stored
+	r.recordEvent(ctx, ownerID, id, entity.EventUpdate, changes)
+
+	return nil
+}
+
+func (r *Repository) CompleteOccurrence(ctx context.Context, ownerID int, id int, task *entity.Task, next *entity.Task) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.updateTask(ctx, ownerID, id, task)
+	if err != nil {
+		return -1, err
+	}
+
+	return r.insertTask(ctx, next)
+}
+
+// updateTask replaces one of the owner's tasks. The caller holds mu.
+func (r *Repository) updateTask(ctx context.Context, ownerID int, id int, task *entity.Task) error {
+	existing, ok := r.liveTask(ownerID, id)
+	if !ok {
+		return service.ErrNotFound
+	}
+	blockers := existing.BlockedBy
+	existing.BlockedBy = r.liveBlockers(existing.BlockedBy)
+
+	err := bumpVersion(&existing, task)
+	if err != nil {
+		return err
+	}
+
+	changes, err := entity.TaskChanges(&existing, task, nil)
+	if err != nil {
+		return err
+	}
+
+	stored := *task
+	stored.ID = id
+	stored.OwnerID = ownerID
+	stored.Tags = r.ensureTags(ownerID, stored.Tags)
+	stored.BlockedBy = r.replaceBlockers(blockers, stored.BlockedBy)
+	r.tasks[id] = stored
+	r.recordEvent(ctx, ownerID, id, entity.EventUpdate, changes)
+
+	return nil
+}
+
+func (r *Repository) DeleteTask(ctx context.Context, ownerID int, id int, version int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.liveTask(ownerID, id)
+	if !ok {
+		return service.ErrNotFound
+	}
+	if version != 0 && existing.Version != version {
+		return service.ErrVersionMismatch
+	}
+
+	// Subtasks go to the trash with their parent, stamped alike so that
+	// RestoreTask brings them back together.
+	now := time.Now().UTC()
+	for _, task := range r.subtree(ownerID, id, live) {
+		deletedAt := now
+		task.DeletedAt = &deletedAt
+		task.Version++
+		r.tasks[task.ID] = *task
+		r.recordEvent(ctx, ownerID, task.ID, entity.EventDelete, nil)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetTrash(ctx context.Context, ownerID int) ([]*entity.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tasks []*entity.Task
+	for _, task := range r.tasks {
+		if task.OwnerID != ownerID || task.DeletedAt == nil {
+			continue
+		}
+
+		task := task
+		deletedAt := *task.DeletedAt
+		task.DeletedAt = &deletedAt
+		task.Tags = cloneTags(task.Tags)
+		task.BlockedBy = r.liveBlockers(task.BlockedBy)
+		tasks = append(tasks, &task)
+	}
+
+	slices.SortFunc(tasks, func(a, b *entity.Task) int {
+		if c := b.DeletedAt.Compare(*a.DeletedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return tasks, nil
+}
+
+func (r *Repository) RestoreTask(ctx context.Context, ownerID int, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.tasks[id]
+	if !ok || existing.OwnerID != ownerID || existing.DeletedAt == nil {
+		return service.ErrNotFound
+	}
+	if existing.ParentID != nil {
+		if parent, ok := r.tasks[*existing.ParentID]; ok && parent.DeletedAt != nil {
+			return service.ErrParentInTrash
+		}
+	}
+
+	deletedAt := *existing.DeletedAt
+	deletedWith := func(task entity.Task) bool {
+		return task.DeletedAt != nil && task.DeletedAt.Equal(deletedAt)
+	}
+	for _, task := range r.subtree(ownerID, id, deletedWith) {
+		task.DeletedAt = nil
+		task.Version++
+		r.tasks[task.ID] = *task
+		r.recordEvent(ctx, ownerID, task.ID, entity.EventRestore, nil)
+	}
+
+	return nil
+}
+
+func (r *Repository) PurgeTasks(ctx context.Context, before time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var purged int64
+	for id, task := range r.tasks {
+		if task.DeletedAt != nil && task.DeletedAt.Before(before) {
+			r.deleteTask(id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (r *Repository) GetSubtree(ctx context.Context, ownerID int, id int) ([]*entity.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.liveTask(ownerID, id); !ok {
+		return nil, service.ErrNotFound
+	}
+
+	tasks := r.subtree(ownerID, id, live)
+	sort.Slice(tasks[1:], func(i, j int) bool { return tasks[i+1].ID < tasks[j+1].ID })
+	for _, task := range tasks {
+		task.Tags = cloneTags(task.Tags)
+		task.BlockedBy = r.liveBlockers(task.BlockedBy)
+	}
+
+	return tasks, nil
+}
+
+func (r *Repository) CountOpenSubtasks(ctx context.Context, ownerID int, id int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, task := range r.subtree(ownerID, id, live) {
+		if task.ID != id && !task.Completed {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *Repository) SetTaskParent(ctx context.Context, ownerID int, id int, parentID *int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, ok := r.liveTask(ownerID, id)
+	if !ok {
+		return service.ErrNotFound
+	}
+
+	changes, err := entity.TaskChanges(&task, &entity.Task{ParentID: parentID}, []string{"parent_id"})
+	if err != nil {
+		return err
+	}
+	task.ParentID = parentID
+	task.Version++
+	r.tasks[id] = task
+	r.recordEvent(ctx, ownerID, id, entity.EventUpdate, changes)
+
+	return nil
+}
+
+// bumpVersion moves stored, the task about to be overwritten, to its next
+// version and records it in task. When task.Version is set, stored must be at
+// that version.
+func bumpVersion(stored *entity.Task, task *entity.Task) error {
+	if task.Version != 0 && stored.Version != task.Version {
+		return service.ErrVersionMismatch
+	}
+
+	stored.Version++
+	task.Version = stored.Version
+	return nil
+}
+
+// liveTask returns one of the owner's tasks unless it is in the trash. The
+// caller holds mu.
+func (r *Repository) liveTask(ownerID int, id int) (entity.Task, bool) {
+	task, ok := r.tasks[id]
+	if !ok || task.OwnerID != ownerID || task.DeletedAt != nil {
+		return entity.Task{}, false
+	}
+
+	return task, true
+}
+
+func live(task entity.Task) bool {
+	return task.DeletedAt == nil
+}
+
+// subtree returns copies of task id and its descendants that match, the task
+// first. The caller holds mu.
+func (r *Repository) subtree(ownerID int, id int, match func(entity.Task) bool) []*entity.Task {
+	var tasks []*entity.Task
+	seen := make(map[int]bool)
+	queue := []int{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+
+		task, ok := r.tasks[current]
+		if !ok || task.OwnerID != ownerID || !match(task) {
+			continue
+		}
+		tasks = append(tasks, &task)
+
+		for _, child := range r.tasks {
+			if child.ParentID != nil && *child.ParentID == current {
+				queue = append(queue, child.ID)
+			}
+		}
+	}
+
+	return tasks
+}
+
+func (r *Repository) GetTaskList(ctx context.Context, filter entity.TaskFilter) ([]*entity.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tasks []*entity.Task
+	for _, task := range r.tasks {
+		if task.OwnerID != filter.OwnerID || task.DeletedAt != nil {
+			continue
+		}
+		if filter.Query != "" {
+			score, snippet := search(task, filter.Query)
+			if score == 0 {
+				continue
+			}
+			task.Rank = float64(score)
+			task.Snippet = snippet
+		}
+		if filter.ProjectID != 0 && (task.ProjectID == nil || *task.ProjectID != filter.ProjectID) {
+			continue
+		}
+		if filter.ParentID != 0 && (task.ParentID == nil || *task.ParentID != filter.ParentID) {
+			continue
+		}
+		if filter.Completed != nil && task.Completed != *filter.Completed {
+			continue
+		}
+		if filter.Blocked != nil && r.blocked(task) != *filter.Blocked {
+			continue
+		}
+		if filter.Overdue != nil && (!task.Completed && pastDue(&task, filter.Now)) != *filter.Overdue {
+			continue
+		}
+		if filter.DueWithin > 0 && (pastDue(&task, filter.Now) || !task.DueAt.Before(filter.Now.Add(filter.DueWithin))) {
+			continue
+		}
+		if !filter.DueFrom.IsZero() && task.DueAt.Before(filter.DueFrom) {
+			continue
+		}
+		if !filter.DueTo.IsZero() && !task.DueAt.Before(filter.DueTo) {
+			continue
+		}
+		if len(filter.Tags) > 0 && !matchTags(task.Tags, filter.Tags, filter.TagMatch) {
+			continue
+		}
+
+		task := task
+		task.Tags = cloneTags(task.Tags)
+		task.BlockedBy = r.liveBlockers(task.BlockedBy)
+		tasks = append(tasks, &task)
+	}
+
+	ranked := filter.Query != "" && len(filter.Sort) == 0
+	compare := func(a, b *entity.Task) int {
+		return compareTasks(a, b, filter.Sort, ranked)
+	}
+	slices.SortFunc(tasks, compare)
+
+	if filter.Keyset != nil {
+		tasks = afterKeyset(tasks, filter.Keyset, compare, filter.Limit)
+	}
+
+	return paginate(tasks, filter.Offset, filter.Limit), nil
+}
+
+// pastDue reports whether a task is past due at now: a timed task once its due
+// time has come, an all-day task once its whole day has passed.
+func pastDue(task *entity.Task, now time.Time) bool {
+	if task.AllDay {
+		return !task.DueAt.Add(24 * time.Hour).After(now)
+	}
+
+	return task.DueAt.Before(now)
+}
+
+// compareTasks orders two tasks by their rank when ranked, then by the sort
+// keys, falling back to their ids.
+func compareTasks(a, b *entity.Task, keys []entity.SortField, ranked bool) int {
+	if ranked {
+		if c := cmp.Compare(b.Rank, a.Rank); c != 0 {
+			return c
+		}
+	}
+
+	for _, key := range keys {
+		c := compareField(a, b, key.Field)
+		if c == 0 {
+			continue
+		}
+
+		if key.Desc {
+			return -c
+		}
+		return c
+	}
+
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// afterKeyset cuts sorted tasks to the ones past keyset: all that follow it,
+// or the last limit before it when reading backwards.
+func afterKeyset(tasks []*entity.Task, keyset *entity.TaskKeyset, compare func(a, b *entity.Task) int, limit int) []*entity.Task {
+	at := &entity.Task{
+		ID:        keyset.ID,
+		Title:     keyset.Title,
+		DueAt:     keyset.DueAt,
+		Priority:  keyset.Priority,
+		Completed: keyset.Completed,
+		Rank:      keyset.Rank,
+	}
+
+	if keyset.Backward {
+		end, _ := slices.BinarySearchFunc(tasks, at, compare)
+		return tasks[max(0, end-limit):end]
+	}
+
+	start, found := slices.BinarySearchFunc(tasks, at, compare)
+	if found {
+		start++
+	}
+
+	return tasks[start:]
+}
+
+func compareField(a, b *entity.Task, field string) int {
+	switch field {
+	case "id":
+		return cmp.Compare(a.ID, b.ID)
+	case "title":
+		return strings.Compare(a.Title, b.Title)
+	case "due_at":
+		return a.DueAt.Compare(b.DueAt)
+	case "priority":
+		return cmp.Compare(a.Priority, b.Priority)
+	case "completed":
+		return cmp.Compare(boolRank(a.Completed), boolRank(b.Completed))
+	}
+
+	return 0
+}
+
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func paginate(tasks []*entity.Task, offset int, pagesize int) []*entity.Task {
+	if offset >= len(tasks) {
+		return nil
+	}
+
+	end := offset + pagesize
+	if end > len(tasks) {
+		end = len(tasks)
+	}
+
+	return tasks[offset:end]
+}
@@ -0,0 +1,476 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertAndGetTask(t *testing.T) {
+	repo := NewRepository()
+
+	task := &entity.Task{
+		OwnerID:     1,
+		Title:       "Test Task",
+		Description: "Test Description",
+		DueAt:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	id, err := repo.InsertTask(context.Background(), task)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+
+	result, err := repo.GetTask(context.Background(), 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result.ID)
+	assert.Equal(t, task.Title, result.Title)
+
+	result.Title = "Changed"
+	stored, _ := repo.GetTask(context.Background(), 1, 1)
+	assert.Equal(t, "Test Task", stored.Title)
+}
+
+func TestUpdateTask(t *testing.T) {
+	repo := NewRepository()
+
+	_, err := repo.InsertTask(context.Background(), &entity.Task{OwnerID: 1, Title: "Test Task", DueAt: time.Now()})
+	assert.NoError(t, err)
+
+	err = repo.UpdateTask(context.Background(), 1, 1, &entity.Task{Title: "Updated Task", DueAt: time.Now(), Completed: true})
+	assert.NoError(t, err)
+
+	result, err := repo.GetTask(context.Background(), 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Updated Task", result.Title)
+	assert.Equal(t, 1, result.OwnerID)
+	assert.True(t, result.Completed)
+}
+
+func TestUpdateTask_NotFound(t *testing.T) {
+	repo := NewRepository()
+
+	err := repo.UpdateTask(context.Background(), 1, 1, &entity.Task{Title: "Updated Task", DueAt: time.Now()})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestCompleteOccurrence(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	_, err := repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Standup", DueAt: time.Now(), Recurrence: "FREQ=DAILY"})
+	assert.NoError(t, err)
+
+	id, err := repo.CompleteOccurrence(ctx, 1, 1, &entity.Task{Title: "Standup", DueAt: time.Now(), Completed: true},
+		&entity.Task{OwnerID: 1, Title: "Standup", DueAt: time.Now().AddDate(0, 0, 1), Recurrence: "FREQ=DAILY"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), id)
+
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Completed: boolPtr(false), Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, ids(result))
+
+	_, err = repo.CompleteOccurrence(ctx, 2, 1, &entity.Task{Title: "Hijacked"}, &entity.Task{OwnerID: 2, Title: "Hijacked"})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	assert.Len(t, repo.tasks, 2)
+}
+
+func TestSubtasks(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	root, child := 1, 2
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Root", DueAt: time.Now()})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Child", DueAt: time.Now(), ParentID: &root, Completed: true})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Grandchild", DueAt: time.Now(), ParentID: &child})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Other", DueAt: time.Now()})
+
+	subtree, err := repo.GetSubtree(ctx, 1, root)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, ids(subtree))
+
+	open, err := repo.CountOpenSubtasks(ctx, 1, root)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, open)
+
+	assert.NoError(t, repo.SetTaskParent(ctx, 1, 4, &root))
+	children, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, ParentID: root, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 4}, ids(children))
+
+	assert.NoError(t, repo.DeleteTask(ctx, 1, root, 0))
+	tasks, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+}
+
+func TestDependencies(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Design", DueAt: time.Now()})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Review", DueAt: time.Now(), Completed: true})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Build", DueAt: time.Now(), BlockedBy: []int{1, 2}})
+
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Blocked: boolPtr(true), Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3}, ids(result))
+
+	dependencies, err := repo.GetDependencies(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.Dependency{{TaskID: 3, BlockerID: 1}, {TaskID: 3, BlockerID: 2}}, dependencies)
+
+	assert.NoError(t, repo.DeleteTask(ctx, 1, 1, 0))
+	task, err := repo.GetTask(ctx, 1, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, task.BlockedBy)
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Blocked: boolPtr(false), Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, ids(result))
+}
+
+func TestGetTaskList_Keyset(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Low", DueAt: day, Priority: entity.PriorityLow})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Urgent later", DueAt: day.AddDate(0, 0, 1), Priority: entity.PriorityUrgent})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Urgent", DueAt: day, Priority: entity.PriorityUrgent})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "None", DueAt: day})
+
+	// The list reads 3, 2, 1, 4.
+	sort := []entity.SortField{{Field: "priority", Desc: true}, {Field: "due_at"}}
+	after := &entity.TaskKeyset{ID: 2, DueAt: day.AddDate(0, 0, 1), Priority: entity.PriorityUrgent}
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Sort: sort, Keyset: after, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 4}, ids(result))
+
+	// A keyset need not be a task of the list.
+	before := &entity.TaskKeyset{ID: 9, DueAt: day, Priority: entity.PriorityLow, Backward: true}
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Sort: sort, Keyset: before, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 2, 1}, ids(result))
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Sort: sort, Keyset: before, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 1}, ids(result))
+}
+
+func TestGetTaskList_Search(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Call the bank", Description: "ask about groceries budget", DueAt: time.Now()})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Groceries", Description: "milk, bread and more groceries", DueAt: time.Now()})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Write report", DueAt: time.Now()})
+
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Query: "grocer", Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 1}, ids(result))
+	assert.Equal(t, "<b>Groceries</b> milk, bread and more <b>groceries</b>", result[0].Snippet)
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Query: "grocer milk", Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, ids(result))
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Query: "grocer", Sort: []entity.SortField{{Field: "id"}}, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, ids(result))
+
+	stored, _ := repo.GetTask(ctx, 1, 2)
+	assert.Empty(t, stored.Snippet)
+
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "<i>Pay</i> rent & bills", DueAt: time.Now()})
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Query: "rent", Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, "&lt;i&gt;Pay&lt;/i&gt; <b>rent</b> &amp; bills", result[0].Snippet)
+}
+
+func TestPatchTask(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	id, _ := repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Task", Description: "Keep me", DueAt: due, Tags: []string{"home"}})
+
+	patch := &entity.Task{Title: "Patched", Completed: true, Tags: []string{"work"}}
+	err := repo.PatchTask(ctx, 1, int(id), patch, []string{"title", "completed"})
+	assert.NoError(t, err)
+
+	task, _ := repo.GetTask(ctx, 1, int(id))
+	assert.Equal(t, "Patched", task.Title)
+	assert.True(t, task.Completed)
+	assert.Equal(t, "Keep me", task.Description)
+	assert.Equal(t, []string{"home"}, task.Tags)
+
+	assert.ErrorIs(t, repo.PatchTask(ctx, 2, int(id), patch, []string{"title"}), service.ErrNotFound)
+}
+
+func TestVersions(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	id, _ := repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Task", DueAt: time.Now()})
+	task, _ := repo.GetTask(ctx, 1, int(id))
+	assert.Equal(t, 1, task.Version)
+
+	task.Title = "Renamed"
+	assert.NoError(t, repo.UpdateTask(ctx, 1, int(id), task))
+	assert.Equal(t, 2, task.Version)
+
+	stale := &entity.Task{Title: "Stale", Version: 1}
+	assert.ErrorIs(t, repo.PatchTask(ctx, 1, int(id), stale, []string{"title"}), service.ErrVersionMismatch)
+	assert.ErrorIs(t, repo.DeleteTask(ctx, 1, int(id), 1), service.ErrVersionMismatch)
+
+	patch := &entity.Task{Title: "Patched", Version: 2}
+	assert.NoError(t, repo.PatchTask(ctx, 1, int(id), patch, []string{"title"}))
+	assert.Equal(t, 3, patch.Version)
+
+	task, _ = repo.GetTask(ctx, 1, int(id))
+	assert.Equal(t, "Patched", task.Title)
+	assert.Equal(t, 3, task.Version)
+	assert.NoError(t, repo.DeleteTask(ctx, 1, int(id), 3))
+}
+
+func TestDeleteTask(t *testing.T) {
+	repo := NewRepository()
+
+	_, err := repo.InsertTask(context.Background(), &entity.Task{OwnerID: 1, Title: "Test Task", DueAt: time.Now()})
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.DeleteTask(context.Background(), 1, 1, 0))
+	assert.ErrorIs(t, repo.DeleteTask(context.Background(), 1, 1, 0), service.ErrNotFound)
+
+	_, err = repo.GetTask(context.Background(), 1, 1)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestTrash(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	root := 1
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Root", DueAt: time.Now()})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Child", DueAt: time.Now(), ParentID: &root})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Blocked", DueAt: time.Now(), BlockedBy: []int{root}})
+
+	assert.NoError(t, repo.DeleteTask(ctx, 1, root, 0))
+	_, err := repo.GetTask(ctx, 1, 2)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	task, _ := repo.GetTask(ctx, 1, 3)
+	assert.Empty(t, task.BlockedBy)
+	// Writes to a task keep its dependencies on blockers in the trash.
+	task.Title = "Renamed"
+	assert.NoError(t, repo.UpdateTask(ctx, 1, 3, task))
+	assert.NoError(t, repo.PatchTask(ctx, 1, 3, &entity.Task{}, []string{"blocked_by"}))
+
+	trash, err := repo.GetTrash(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, ids(trash))
+	assert.ErrorIs(t, repo.RestoreTask(ctx, 1, 2), service.ErrParentInTrash)
+
+	assert.NoError(t, repo.RestoreTask(ctx, 1, root))
+	subtree, err := repo.GetSubtree(ctx, 1, root)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, ids(subtree))
+	task, _ = repo.GetTask(ctx, 1, 3)
+	assert.Equal(t, []int{root}, task.BlockedBy)
+
+	assert.NoError(t, repo.DeleteTask(ctx, 1, root, 0))
+	purged, err := repo.PurgeTasks(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	task, _ = repo.GetTask(ctx, 1, 3)
+	assert.Empty(t, task.BlockedBy)
+	assert.Len(t, repo.tasks, 1)
+}
+
+func TestHistory(t *testing.T) {
+	repo := NewRepository()
+	ctx := service.WithUser(context.Background(), &entity.User{ID: 7})
+
+	id, _ := repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Task", DueAt: time.Now()})
+	assert.NoError(t, repo.PatchTask(ctx, 1, int(id), &entity.Task{Title: "Renamed"}, []string{"title"}))
+	assert.NoError(t, repo.DeleteTask(ctx, 1, int(id), 0))
+
+	events, err := repo.GetTaskHistory(ctx, 1, int(id), 0, 2)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, entity.EventDelete, events[0].Operation)
+	assert.Equal(t, []entity.FieldChange{{Field: "title", Before: []byte(`"Task"`), After: []byte(`"Renamed"`)}}, events[1].Changes)
+	assert.Equal(t, 7, events[1].ActorID)
+
+	events, err = repo.GetTaskHistory(ctx, 1, int(id), events[1].ID, 2)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, entity.EventCreate, events[0].Operation)
+
+	_, err = repo.PurgeTasks(ctx, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, repo.events)
+}
+
+func TestSnapshots(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	id, _ := repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Task", DueAt: time.Now(), Tags: []string{"home"}})
+	created := time.Now()
+	assert.NoError(t, repo.PatchTask(ctx, 1, int(id), &entity.Task{Title: "Renamed"}, []string{"title"}))
+
+	task, err := repo.GetTaskVersion(ctx, 1, int(id), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Task", task.Title)
+	task.Tags[0] = "work"
+
+	task, err = repo.GetTaskAsOf(ctx, 1, int(id), created)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"home"}, task.Tags)
+
+	assert.NoError(t, repo.DeleteTask(ctx, 1, int(id), 0))
+	_, err = repo.GetTaskAsOf(ctx, 1, int(id), time.Now())
+	assert.ErrorIs(t, err, service.ErrVersionNotFound)
+	_, err = repo.GetTaskVersion(ctx, 1, int(id), 9)
+	assert.ErrorIs(t, err, service.ErrVersionNotFound)
+	_, err = repo.GetTaskVersion(ctx, 2, int(id), 1)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestTransaction(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	err := repo.Transaction(ctx, func(tx service.Repository) error {
+		_, err := tx.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Kept", DueAt: time.Now()})
+		return err
+	})
+	assert.NoError(t, err)
+
+	err = repo.Transaction(ctx, func(tx service.Repository) error {
+		_, err := tx.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Rolled back", DueAt: time.Now()})
+		assert.NoError(t, err)
+		return service.ErrInvalidData
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidData)
+
+	tasks, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1}, ids(tasks))
+	assert.Len(t, repo.events, 1)
+}
+
+func TestGetTaskList(t *testing.T) {
+	repo := NewRepository()
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		_, err := repo.InsertTask(context.Background(), &entity.Task{
+			OwnerID:   1,
+			Title:     "Test Task",
+			DueAt:     day.AddDate(0, 0, i%2),
+			Completed: i%2 == 0,
+		})
+		assert.NoError(t, err)
+	}
+
+	result, err := repo.GetTaskList(context.Background(), entity.TaskFilter{OwnerID: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, result, 5)
+
+	result, err = repo.GetTaskList(context.Background(), entity.TaskFilter{OwnerID: 1, Offset: 1, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, ids(result))
+
+	result, err = repo.GetTaskList(context.Background(), entity.TaskFilter{OwnerID: 1, Completed: boolPtr(true), Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 3, 5}, ids(result))
+
+	result, err = repo.GetTaskList(context.Background(), entity.TaskFilter{OwnerID: 1, DueFrom: day.AddDate(0, 0, 1), DueTo: day.AddDate(0, 0, 2), Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 4}, ids(result))
+}
+
+func TestTaskIsolation(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	id, err := repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "John's Task", DueAt: time.Now()})
+	assert.NoError(t, err)
+
+	_, err = repo.GetTask(ctx, 2, int(id))
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	assert.ErrorIs(t, repo.UpdateTask(ctx, 2, int(id), &entity.Task{Title: "Hijacked", DueAt: time.Now()}), service.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteTask(ctx, 2, int(id), 0), service.ErrNotFound)
+
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 2, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+
+	task, err := repo.GetTask(ctx, 1, int(id))
+	assert.NoError(t, err)
+	assert.Equal(t, "John's Task", task.Title)
+}
+
+func ids(tasks []*entity.Task) []int {
+	var result []int
+	for _, task := range tasks {
+		result = append(result, task.ID)
+	}
+
+	return result
+}
+
+func TestGetTaskList_Overdue(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Missed", DueAt: today.Add(9 * time.Hour)})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Done", DueAt: today.Add(9 * time.Hour), Completed: true})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Today, all day", DueAt: today, AllDay: true})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Yesterday, all day", DueAt: today.AddDate(0, 0, -1), AllDay: true})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "In two days", DueAt: today.AddDate(0, 0, 2)})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "In ten days", DueAt: today.AddDate(0, 0, 10)})
+
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Overdue: boolPtr(true), Now: now, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 4}, ids(result))
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Overdue: boolPtr(false), Now: now, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 5, 6}, ids(result))
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, DueWithin: 7 * 24 * time.Hour, Now: now, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 5}, ids(result))
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestGetTaskList_Sort(t *testing.T) {
+	repo := NewRepository()
+	ctx := context.Background()
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Low", DueAt: day, Priority: entity.PriorityLow})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Urgent later", DueAt: day.AddDate(0, 0, 1), Priority: entity.PriorityUrgent})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "Urgent", DueAt: day, Priority: entity.PriorityUrgent})
+	_, _ = repo.InsertTask(ctx, &entity.Task{OwnerID: 1, Title: "None", DueAt: day})
+
+	sort := []entity.SortField{{Field: "priority", Desc: true}, {Field: "due_at"}}
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Sort: sort, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 2, 1, 4}, ids(result))
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: 1, Sort: []entity.SortField{{Field: "title"}}, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 4, 3, 2}, ids(result))
+}
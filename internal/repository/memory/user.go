@@ -0,0 +1,81 @@
+package memory
+
+import (
+	"context"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+func (r *Repository) InsertUser(ctx context.Context, user *entity.User) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Username == user.Username {
+			return -1, service.ErrUserExists
+		}
+	}
+
+	stored := *user
+	stored.ID = r.nextUserID
+	r.users[stored.ID] = stored
+	r.nextUserID++
+
+	return int64(stored.ID), nil
+}
+
+func (r *Repository) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+
+	return nil, service.ErrNotFound
+}
+
+func (r *Repository) GetUser(ctx context.Context, id int) (*entity.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, service.ErrNotFound
+	}
+
+	return &user, nil
+}
+
+func (r *Repository) UpdateUserTimeZone(ctx context.Context, id int, timeZone string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return service.ErrNotFound
+	}
+
+	user.TimeZone = timeZone
+	r.users[id] = user
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertAndGetUser(t *testing.T) {
+	repo := NewRepository()
+
+	id, err := repo.InsertUser(context.Background(), &entity.User{Username: "john", PasswordHash: "hash"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+
+	_, err = repo.InsertUser(context.Background(), &entity.User{Username: "john", PasswordHash: "other"})
+	assert.ErrorIs(t, err, service.ErrUserExists)
+
+	user, err := repo.GetUserByUsername(context.Background(), "john")
+	assert.NoError(t, err)
+	assert.Equal(t, "hash", user.PasswordHash)
+
+	_, err = repo.GetUserByUsername(context.Background(), "jane")
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestUpdateUserTimeZone(t *testing.T) {
+	repo := NewRepository()
+
+	_, _ = repo.InsertUser(context.Background(), &entity.User{Username: "john", PasswordHash: "hash", TimeZone: "UTC"})
+
+	assert.NoError(t, repo.UpdateUserTimeZone(context.Background(), 1, "Europe/Berlin"))
+	user, err := repo.GetUser(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", user.TimeZone)
+
+	assert.ErrorIs(t, repo.UpdateUserTimeZone(context.Background(), 2, "UTC"), service.ErrNotFound)
+}
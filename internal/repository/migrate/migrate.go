@@ -0,0 +1,297 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// The version table uses the same layout as golang-migrate, so databases
+// migrated by the old migrate container are picked up where they left off.
+const versionTable = `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+var ErrDirty = errors.New("database is in a dirty migration state")
+
+type Migration struct {
+	Version uint64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version uint64 `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+}
+
+// Locker serialises migrations across processes sharing a database. It is
+// given the connection migrations will run on and returns a release func.
+type Locker func(ctx context.Context, conn *sql.Conn) (func() error, error)
+
+type Migrator struct {
+	db         *sql.DB
+	lock       Locker
+	migrations []Migration
+}
+
+// New reads NNN_name.up.sql / NNN_name.down.sql pairs from the root of fsys.
+// A nil lock means the database serialises writers by itself.
+func New(db *sql.DB, fsys fs.FS, lock Locker) (*Migrator, error) {
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, lock: lock, migrations: migrations}, nil
+}
+
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", entry.Name(), err)
+		}
+
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files named %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up applies every pending migration, each in its own transaction together
+// with the version bump.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.locked(ctx, func(conn *sql.Conn) error {
+		for _, migration := range m.migrations {
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				current, err := version(ctx, tx)
+				if err != nil || migration.Version <= current {
+					return err
+				}
+
+				err = apply(ctx, tx, migration.Up, migration.Version, true)
+				if err != nil {
+					return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+				}
+				log.Printf("applied migration %d_%s", migration.Version, migration.Name)
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Down rolls back the given number of applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.locked(ctx, func(conn *sql.Conn) error {
+		for ; steps > 0; steps-- {
+			done := false
+			err := inTx(ctx, conn, func(tx *sql.Tx) error {
+				current, err := version(ctx, tx)
+				if err != nil {
+					return err
+				}
+				if current == 0 {
+					done = true
+					return nil
+				}
+
+				i := m.index(current)
+				if i < 0 {
+					return fmt.Errorf("database is at version %d, which this binary does not know", current)
+				}
+
+				migration := m.migrations[i]
+				if migration.Down == "" {
+					return fmt.Errorf("migration %d_%s has no down file", migration.Version, migration.Name)
+				}
+
+				var previous uint64
+				if i > 0 {
+					previous = m.migrations[i-1].Version
+				}
+
+				err = apply(ctx, tx, migration.Down, previous, i > 0)
+				if err != nil {
+					return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+				}
+				log.Printf("rolled back migration %d_%s", migration.Version, migration.Name)
+
+				return nil
+			})
+			if err != nil || done {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		current, err := version(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			statuses = append(statuses, Status{
+				Version: migration.Version,
+				Name:    migration.Name,
+				Applied: migration.Version <= current,
+			})
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.lock != nil {
+		unlock, err := m.lock(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		defer unlock()
+	}
+
+	_, err = conn.ExecContext(ctx, versionTable)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) index(version uint64) int {
+	for i, migration := range m.migrations {
+		if migration.Version == version {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// version returns the last applied migration, or 0 when none is.
+func version(ctx context.Context, q queryer) (uint64, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := q.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if dirty {
+		return 0, fmt.Errorf("%w at version %d", ErrDirty, version)
+	}
+
+	return uint64(version), nil
+}
+
+// apply runs a migration script and records the resulting version. keep is
+// false when the first migration is rolled back and no version remains.
+func apply(ctx context.Context, tx *sql.Tx, script string, version uint64, keep bool) error {
+	_, err := tx.ExecContext(ctx, script)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations")
+	if err != nil {
+		return err
+	}
+
+	if !keep {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)", int64(version), false)
+	return err
+}
@@ -0,0 +1,129 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+var testMigrations = fstest.MapFS{
+	"000001_init.up.sql":        {Data: []byte("CREATE TABLE tasks (id INTEGER PRIMARY KEY);")},
+	"000001_init.down.sql":      {Data: []byte("DROP TABLE tasks;")},
+	"000002_title.up.sql":       {Data: []byte("ALTER TABLE tasks ADD COLUMN title TEXT;")},
+	"000002_title.down.sql":     {Data: []byte("ALTER TABLE tasks DROP COLUMN title;")},
+	"README.md":                 {Data: []byte("not a migration")},
+	"000003_broken.up.sql.orig": {Data: []byte("ignored")},
+}
+
+func newTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := load(testMigrations)
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, uint64(1), migrations[0].Version)
+	assert.Equal(t, "init", migrations[0].Name)
+	assert.Equal(t, uint64(2), migrations[1].Version)
+	assert.Contains(t, migrations[1].Down, "DROP COLUMN")
+}
+
+func TestLoad_MissingUp(t *testing.T) {
+	_, err := load(fstest.MapFS{"000001_init.down.sql": {Data: []byte("DROP TABLE tasks;")}})
+	assert.Error(t, err)
+}
+
+func TestUpDownStatus(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	m, err := New(db, testMigrations, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, m.Up(ctx))
+	require.NoError(t, m.Up(ctx))
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Status{{1, "init", true}, {2, "title", true}}, statuses)
+
+	_, err = db.Exec("INSERT INTO tasks (id, title) VALUES (1, 'a')")
+	require.NoError(t, err)
+
+	require.NoError(t, m.Down(ctx, 1))
+	statuses, err = m.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Status{{1, "init", true}, {2, "title", false}}, statuses)
+
+	require.NoError(t, m.Down(ctx, 5))
+	statuses, err = m.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Status{{1, "init", false}, {2, "title", false}}, statuses)
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM sqlite_master WHERE name = 'tasks'").Scan(&count))
+	assert.Equal(t, 0, count)
+}
+
+func TestUp_ContinuesFromExistingVersion(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	_, err := db.Exec(versionTable)
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE tasks (id INTEGER PRIMARY KEY); INSERT INTO schema_migrations (version, dirty) VALUES (1, false)")
+	require.NoError(t, err)
+
+	m, err := New(db, testMigrations, nil)
+	require.NoError(t, err)
+	require.NoError(t, m.Up(ctx))
+
+	_, err = db.Exec("INSERT INTO tasks (id, title) VALUES (1, 'a')")
+	assert.NoError(t, err)
+}
+
+func TestUp_Dirty(t *testing.T) {
+	db := newTestDB(t)
+
+	_, err := db.Exec(versionTable)
+	require.NoError(t, err)
+	_, err = db.Exec("INSERT INTO schema_migrations (version, dirty) VALUES (1, true)")
+	require.NoError(t, err)
+
+	m, err := New(db, testMigrations, nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, m.Up(context.Background()), ErrDirty)
+}
+
+func TestUp_FailedMigrationIsRolledBack(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	broken := fstest.MapFS{
+		"000001_init.up.sql":   {Data: []byte("CREATE TABLE tasks (id INTEGER PRIMARY KEY);")},
+		"000002_broken.up.sql": {Data: []byte("CREATE TABLE notes (id INTEGER); SELECT * FROM missing;")},
+	}
+
+	m, err := New(db, broken, nil)
+	require.NoError(t, err)
+	assert.Error(t, m.Up(ctx))
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []Status{{1, "init", true}, {2, "broken", false}}, statuses)
+
+	var count int
+	require.NoError(t, db.QueryRow("SELECT count(*) FROM sqlite_master WHERE name = 'notes'").Scan(&count))
+	assert.Equal(t, 0, count)
+}
@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"io/fs"
+)
+
+// advisoryLockKey identifies the migration lock among other advisory locks
+// that may be taken on the same database.
+const advisoryLockKey = 7_432_001
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrations returns the schema migrations shipped with the binary.
+func Migrations() fs.FS {
+	sub, _ := fs.Sub(migrations, "migrations")
+	return sub
+}
+
+// AdvisoryLock holds a session-level advisory lock on conn, so replicas
+// booting together apply migrations one at a time.
+func AdvisoryLock(ctx context.Context, conn *sql.Conn) (func() error, error) {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockKey)
+		return err
+	}, nil
+}
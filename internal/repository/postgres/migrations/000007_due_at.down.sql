@@ -0,0 +1,8 @@
+ALTER TABLE users DROP COLUMN IF EXISTS time_zone;
+
+DROP INDEX IF EXISTS tasks_owner_id_due_at_idx;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS date DATE NULL;
+UPDATE tasks SET date = (due_at AT TIME ZONE 'UTC')::date;
+ALTER TABLE tasks ALTER COLUMN date SET NOT NULL;
+ALTER TABLE tasks DROP COLUMN IF EXISTS all_day;
+ALTER TABLE tasks DROP COLUMN IF EXISTS due_at;
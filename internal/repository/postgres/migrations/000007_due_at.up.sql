@@ -0,0 +1,9 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ NULL;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS all_day BOOLEAN NOT NULL DEFAULT false;
+-- Existing tasks only carry a day, so they become all-day tasks at midnight UTC.
+UPDATE tasks SET due_at = date::timestamp AT TIME ZONE 'UTC', all_day = true;
+ALTER TABLE tasks ALTER COLUMN due_at SET NOT NULL;
+ALTER TABLE tasks DROP COLUMN IF EXISTS date;
+CREATE INDEX IF NOT EXISTS tasks_owner_id_due_at_idx ON tasks (owner_id, due_at);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS tasks_search_idx;
+ALTER TABLE tasks DROP COLUMN IF EXISTS search;
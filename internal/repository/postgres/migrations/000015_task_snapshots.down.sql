@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS task_events_version_idx;
+ALTER TABLE task_events DROP COLUMN IF EXISTS snapshot;
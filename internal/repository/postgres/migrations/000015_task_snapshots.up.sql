@@ -0,0 +1,3 @@
+-- The task as each write left it. Events recorded before this have none.
+ALTER TABLE task_events ADD COLUMN IF NOT EXISTS snapshot JSONB NULL;
+CREATE INDEX IF NOT EXISTS task_events_version_idx ON task_events (task_id, version);
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+const projectColumns = "id, owner_id, name, inbox"
+
+func scanProject(row scanner) (*entity.Project, error) {
+	var project entity.Project
+	err := row.Scan(&project.ID, &project.OwnerID, &project.Name, &project.Inbox)
+	if err != nil {
+		return nil, err
+	}
+
+	return &project, nil
+}
+
+func (r *Repository) InsertProject(ctx context.Context, project *entity.Project) (int64, error) {
+	var id int64
+	err := r.QueryRowContext(ctx, "INSERT INTO projects(owner_id, name, inbox) VALUES ($1, $2, $3) RETURNING id", project.OwnerID, project.Name, project.Inbox).Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+func (r *Repository) GetProject(ctx context.Context, ownerID int, id int) (*entity.Project, error) {
+	project, err := scanProject(r.QueryRowContext(ctx, "SELECT "+projectColumns+" FROM projects WHERE id = $1 AND owner_id = $2", id, ownerID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrProjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func (r *Repository) GetProjectList(ctx context.Context, ownerID int) ([]*entity.Project, error) {
+	rows, err := r.QueryContext(ctx, "SELECT "+projectColumns+" FROM projects WHERE owner_id = $1 ORDER BY id", ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []*entity.Project
+	for rows.Next() {
+		project, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+
+	return projects, rows.Err()
+}
+
+func (r *Repository) UpdateProject(ctx context.Context, ownerID int, id int, project *entity.Project) error {
+	res, err := r.ExecContext(ctx, "UPDATE projects SET name=$1 WHERE id = $2 AND owner_id = $3", project.Name, id, ownerID)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res, service.ErrProjectNotFound)
+}
+
+func (r *Repository) DeleteProject(ctx context.Context, ownerID int, id int, moveTasksTo int) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if moveTasksTo != 0 {
+		changes, err := entity.TaskChanges(&entity.Task{ProjectID: &id}, &entity.Task{ProjectID: &moveTasksTo}, []string{"project_id"})
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, "UPDATE tasks SET project_id=$1, version = version + 1 WHERE project_id = $2 AND owner_id = $3 RETURNING id", moveTasksTo, id, ownerID)
+		if err != nil {
+			return err
+		}
+
+		err = recordEvents(ctx, tx, ownerID, rows, entity.EventUpdate, changes)
+		if err != nil {
+			return err
+		}
+	}
+
+	if moveTasksTo == 0 {
+		err = trashProject(ctx, tx, ownerID, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM projects WHERE id = $1 AND owner_id = $2", id, ownerID)
+	if err != nil {
+		return err
+	}
+
+	err = checkAffected(res, service.ErrProjectNotFound)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// projectSubtreeQuery is subtreeQuery for the live tasks of project $1 and
+// all of their descendants.
+const projectSubtreeQuery = "WITH RECURSIVE subtree(id) AS (" +
+	"SELECT id FROM tasks WHERE project_id = $1 AND owner_id = $2 AND deleted_at IS NULL " +
+	"UNION SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id WHERE t.owner_id = $2 AND t.deleted_at IS NULL) "
+
+// trashProject moves the tasks of project id, and their subtasks wherever
+// they are, to the trash as DeleteTask would, and takes every task out of the
+// project, so that deleting it cascades to none of them.
+func trashProject(ctx context.Context, tx dbTx, ownerID int, id int) error {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	rows, err := tx.QueryContext(ctx, projectSubtreeQuery+"UPDATE tasks SET deleted_at = $3, project_id = CASE WHEN project_id = $1 THEN NULL ELSE project_id END, version = version + 1 WHERE id IN (SELECT id FROM subtree) RETURNING id", id, ownerID, now)
+	if err != nil {
+		return err
+	}
+
+	err = recordEvents(ctx, tx, ownerID, rows, entity.EventDelete, nil)
+	if err != nil {
+		return err
+	}
+
+	// The tasks that were in the trash already keep their history as it is.
+	_, err = tx.ExecContext(ctx, "UPDATE tasks SET project_id = NULL WHERE project_id = $1 AND owner_id = $2", id, ownerID)
+	return err
+}
+
+func (r *Repository) EnsureInboxProject(ctx context.Context, ownerID int) (*entity.Project, error) {
+	_, err := r.ExecContext(ctx, "INSERT INTO projects(owner_id, name, inbox) VALUES ($1, $2, true) ON CONFLICT (owner_id) WHERE inbox DO NOTHING", ownerID, "Inbox")
+	if err != nil {
+		return nil, err
+	}
+
+	return scanProject(r.QueryRowContext(ctx, "SELECT "+projectColumns+" FROM projects WHERE owner_id = $1 AND inbox", ownerID))
+}
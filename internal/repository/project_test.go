@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProject_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	mock.ExpectQuery("SELECT id, owner_id, name, inbox FROM projects WHERE id = \\$1 AND owner_id = \\$2").
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "name", "inbox"}))
+
+	result, err := repo.GetProject(context.Background(), 2, 1)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, service.ErrProjectNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteProject_MovesTasks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE tasks SET project_id=\\$1, version = version \\+ 1 WHERE project_id = \\$2 AND owner_id = \\$3 RETURNING id").
+		WithArgs(5, 2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7).AddRow(8))
+	expectEvent(mock, 7, entity.EventUpdate, 2, `[{"field":"project_id","before":2,"after":5}]`)
+	expectEvent(mock, 8, entity.EventUpdate, 3, `[{"field":"project_id","before":2,"after":5}]`)
+	mock.ExpectExec("DELETE FROM projects WHERE id = \\$1 AND owner_id = \\$2").
+		WithArgs(2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err = repo.DeleteProject(context.Background(), 1, 2, 5)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteProject_NotFoundRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH RECURSIVE subtree\\(id\\) AS \\(SELECT id FROM tasks WHERE project_id = \\$1 .* UPDATE tasks SET deleted_at = \\$3, project_id = CASE WHEN project_id = \\$1 THEN NULL ELSE project_id END").
+		WithArgs(2, 1, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("UPDATE tasks SET project_id = NULL WHERE project_id = \\$1 AND owner_id = \\$2").
+		WithArgs(2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM projects").
+		WithArgs(2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.DeleteProject(context.Background(), 1, 2, 0)
+	assert.ErrorIs(t, err, service.ErrProjectNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,13 +3,61 @@ package repository
 import (
 	"database/sql"
 	"todo-list/configs"
+	"todo-list/internal/repository/migrate"
 	"todo-list/internal/repository/postgres"
+	"todo-list/internal/repository/sqlite"
+)
+
+// dialect marks the SQL flavour a Repository talks to. Queries are written
+// for both; the few places where Postgres and SQLite disagree switch on it.
+type dialect int
+
+const (
+	dialectPostgres dialect = iota
+	dialectSQLite
 )
 
 type Repository struct {
 	*sql.DB
+	dialect dialect
+	// tx is the transaction r runs in when it was handed out by Transaction,
+	// nil otherwise.
+	tx dbTx
 }
 
 func NewRepository(cfg *configs.Config) *Repository {
-	return &Repository{postgres.ConnectToPostgres(cfg.PostgresURL)}
+	if cfg.Storage == configs.StorageSQLite {
+		return &Repository{DB: sqlite.ConnectToSQLite(cfg.SQLitePath), dialect: dialectSQLite}
+	}
+
+	return &Repository{DB: postgres.ConnectToPostgres(cfg.PostgresURL), dialect: dialectPostgres}
+}
+
+// Migrator returns the schema migrator for the database behind r.
+func (r *Repository) Migrator() (*migrate.Migrator, error) {
+	if r.dialect == dialectSQLite {
+		return migrate.New(r.DB, sqlite.Migrations(), nil)
+	}
+
+	return migrate.New(r.DB, postgres.Migrations(), postgres.AdvisoryLock)
+}
+
+// isUniqueViolation reports whether err was raised by a UNIQUE constraint.
+func (d dialect) isUniqueViolation(err error) bool {
+	if d == dialectSQLite {
+		return sqlite.IsUniqueViolation(err)
+	}
+
+	return postgres.IsUniqueViolation(err)
+}
+
+// forUpdate is the clause locking the rows a SELECT reads until the
+// transaction ends. SQLite has none and needs none, as its transactions take
+// the write lock up front.
+func (d dialect) forUpdate() string {
+	if d == dialectSQLite {
+		return ""
+	}
+
+	return " FOR UPDATE"
 }
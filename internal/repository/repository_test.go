@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrator_EmbeddedMigrationsLoad(t *testing.T) {
+	for _, d := range []dialect{dialectPostgres, dialectSQLite} {
+		_, err := (&Repository{dialect: d}).Migrator()
+		assert.NoError(t, err)
+	}
+}
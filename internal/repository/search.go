@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// The engines mark matches in a snippet with these private-use characters,
+// which highlightSnippet turns into <b></b> once the text is escaped.
+const (
+	matchStart = "\ue000"
+	matchEnd   = "\ue001"
+)
+
+// searchSQL returns the SQL of a full-text search bound as parameter $n: the
+// condition matching tasks, the relevance of each match, higher first, and
+// an expression for its highlighted snippet. Title matches weigh twice as
+// much as description matches.
+func (d dialect) searchSQL(n int) (match, rank, snippet string) {
+	if d == dialectSQLite {
+		fts := fmt.Sprintf("FROM tasks_fts WHERE tasks_fts MATCH $%d", n)
+		// bm25 scores better matches lower.
+		return "id IN (SELECT rowid " + fts + ")",
+			"(SELECT -bm25(tasks_fts, 2.0, 1.0) " + fts + " AND rowid = tasks.id)",
+			"(SELECT snippet(tasks_fts, -1, '" + matchStart + "', '" + matchEnd + "', '…', 12) " + fts + " AND rowid = tasks.id)"
+	}
+
+	query := fmt.Sprintf("to_tsquery('simple', $%d)", n)
+	// The weights are of labels D, C, B and A; the title is A, the description B.
+	return "search @@ " + query,
+		"ts_rank('{0, 0, 0.5, 1.0}', search, " + query + ")",
+		"ts_headline('simple', title || ' ' || coalesce(description, ''), " + query + ", 'StartSel=" + matchStart + ", StopSel=" + matchEnd + ", MaxWords=20, MinWords=5')"
+}
+
+// highlightSnippet escapes a snippet of task text for HTML and wraps the
+// matches the engine marked in <b></b>, so that the text cannot carry markup
+// of its own.
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, matchStart, "<b>")
+	return strings.ReplaceAll(escaped, matchEnd, "</b>")
+}
+
+// searchQuery writes the normalized words of a search as a query of the
+// dialect's engine, matching every word as a prefix.
+func (d dialect) searchQuery(query string) string {
+	terms := strings.Fields(query)
+	for i, term := range terms {
+		if d == dialectSQLite {
+			terms[i] = `"` + term + `"*`
+		} else {
+			terms[i] = term + ":*"
+		}
+	}
+
+	if d == dialectSQLite {
+		return strings.Join(terms, " ")
+	}
+
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/url"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+func ConnectToSQLite(path string) *sql.DB {
+	db, err := sql.Open("sqlite", dsn(path))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = db.PingContext(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return db
+}
+
+// dsn enables foreign keys and WAL on every pooled connection, waits on a
+// locked database instead of failing, and stores times in a sortable form.
+// Transactions take the write lock up front, which also keeps two processes
+// from applying the same migration.
+func dsn(path string) string {
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Set("_time_format", "sqlite")
+	params.Set("_txlock", "immediate")
+
+	return "file:" + path + "?" + params.Encode()
+}
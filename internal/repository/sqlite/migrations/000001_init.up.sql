@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS tasks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(255) NOT NULL,
+    description VARCHAR(255) NULL,
+    date DATE NOT NULL,
+    completed BOOLEAN DEFAULT false
+);
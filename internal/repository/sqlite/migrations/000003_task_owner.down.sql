@@ -0,0 +1,13 @@
+-- SQLite cannot drop a column that carries a foreign key, so the table is rebuilt.
+DROP INDEX IF EXISTS tasks_owner_id_idx;
+CREATE TABLE tasks_without_owner (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(255) NOT NULL,
+    description VARCHAR(255) NULL,
+    date DATE NOT NULL,
+    completed BOOLEAN DEFAULT false
+);
+INSERT INTO tasks_without_owner (id, title, description, date, completed)
+SELECT id, title, description, date, completed FROM tasks;
+DROP TABLE tasks;
+ALTER TABLE tasks_without_owner RENAME TO tasks;
@@ -0,0 +1,4 @@
+-- Tasks created before accounts existed have no owner and stay hidden until
+-- they are assigned to a user by hand.
+ALTER TABLE tasks ADD COLUMN owner_id INTEGER NULL REFERENCES users(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS tasks_owner_id_idx ON tasks (owner_id, id);
@@ -0,0 +1,18 @@
+-- SQLite cannot drop a column that carries a foreign key, so the table is rebuilt.
+DROP INDEX IF EXISTS tasks_project_id_idx;
+DROP INDEX IF EXISTS tasks_owner_id_idx;
+CREATE TABLE tasks_without_project (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title VARCHAR(255) NOT NULL,
+    description VARCHAR(255) NULL,
+    date DATE NOT NULL,
+    completed BOOLEAN DEFAULT false,
+    owner_id INTEGER NULL REFERENCES users(id) ON DELETE CASCADE
+);
+INSERT INTO tasks_without_project (id, title, description, date, completed, owner_id)
+SELECT id, title, description, date, completed, owner_id FROM tasks;
+DROP TABLE tasks;
+ALTER TABLE tasks_without_project RENAME TO tasks;
+CREATE INDEX IF NOT EXISTS tasks_owner_id_idx ON tasks (owner_id, id);
+
+DROP TABLE IF EXISTS projects;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    owner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    inbox BOOLEAN NOT NULL DEFAULT false
+);
+CREATE INDEX IF NOT EXISTS projects_owner_id_idx ON projects (owner_id, id);
+CREATE UNIQUE INDEX IF NOT EXISTS projects_inbox_idx ON projects (owner_id) WHERE inbox;
+
+ALTER TABLE tasks ADD COLUMN project_id INTEGER NULL REFERENCES projects(id) ON DELETE CASCADE;
+CREATE INDEX IF NOT EXISTS tasks_project_id_idx ON tasks (project_id);
@@ -0,0 +1,7 @@
+ALTER TABLE users DROP COLUMN time_zone;
+
+DROP INDEX IF EXISTS tasks_owner_id_due_at_idx;
+ALTER TABLE tasks ADD COLUMN date DATE NOT NULL DEFAULT '1970-01-01';
+UPDATE tasks SET date = substr(due_at, 1, 10);
+ALTER TABLE tasks DROP COLUMN all_day;
+ALTER TABLE tasks DROP COLUMN due_at;
@@ -0,0 +1,8 @@
+ALTER TABLE tasks ADD COLUMN due_at DATETIME NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+ALTER TABLE tasks ADD COLUMN all_day BOOLEAN NOT NULL DEFAULT false;
+-- Existing tasks only carry a day, so they become all-day tasks at midnight UTC.
+UPDATE tasks SET due_at = substr(date, 1, 10) || ' 00:00:00+00:00', all_day = true;
+ALTER TABLE tasks DROP COLUMN date;
+CREATE INDEX IF NOT EXISTS tasks_owner_id_due_at_idx ON tasks (owner_id, due_at);
+
+ALTER TABLE users ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
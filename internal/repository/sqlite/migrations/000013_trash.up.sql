@@ -0,0 +1,3 @@
+-- Deleted tasks stay in the trash until purged; live rows have no deleted_at.
+ALTER TABLE tasks ADD COLUMN deleted_at DATETIME NULL;
+CREATE INDEX IF NOT EXISTS tasks_deleted_at_idx ON tasks (deleted_at) WHERE deleted_at IS NOT NULL;
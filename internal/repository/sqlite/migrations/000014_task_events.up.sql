@@ -0,0 +1,12 @@
+-- One row per write to a task, with the fields it changed as a JSON list of
+-- {field, before, after}.
+CREATE TABLE IF NOT EXISTS task_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    actor_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    operation VARCHAR(16) NOT NULL,
+    version INTEGER NOT NULL,
+    changes TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS task_events_task_id_idx ON task_events (task_id, id);
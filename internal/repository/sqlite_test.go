@@ -0,0 +1,929 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+	"todo-list/internal/entity"
+	"todo-list/internal/repository/sqlite"
+	"todo-list/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLiteRepository(t *testing.T) *Repository {
+	db := sqlite.ConnectToSQLite(filepath.Join(t.TempDir(), "todo.db"))
+	t.Cleanup(func() { db.Close() })
+
+	repo := &Repository{DB: db, dialect: dialectSQLite}
+	m, err := repo.Migrator()
+	require.NoError(t, err)
+	require.NoError(t, m.Up(context.Background()))
+
+	return repo
+}
+
+func newSQLiteUser(t *testing.T, repo *Repository, username string) int {
+	id, err := repo.InsertUser(context.Background(), &entity.User{Username: username, PasswordHash: "hash", CreatedAt: time.Now()})
+	require.NoError(t, err)
+
+	return int(id)
+}
+
+func TestSQLite_TaskLifecycle(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+
+	task := &entity.Task{
+		OwnerID:     owner,
+		Title:       "Test Task",
+		Description: "Test Description",
+		DueAt:       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	id, err := repo.InsertTask(ctx, task)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+
+	result, err := repo.GetTask(ctx, owner, 1)
+	require.NoError(t, err)
+	assert.Equal(t, task.Title, result.Title)
+	assert.Equal(t, owner, result.OwnerID)
+	assert.True(t, task.DueAt.Equal(result.DueAt))
+
+	task.Completed = true
+	require.NoError(t, repo.UpdateTask(ctx, owner, 1, task))
+	result, err = repo.GetTask(ctx, owner, 1)
+	require.NoError(t, err)
+	assert.True(t, result.Completed)
+
+	require.NoError(t, repo.DeleteTask(ctx, owner, 1, 0))
+	_, err = repo.GetTask(ctx, owner, 1)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteTask(ctx, owner, 1, 0), service.ErrNotFound)
+}
+
+func TestSQLite_PatchTask(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	id, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Task", Description: "Keep me", DueAt: due, Tags: []string{"home"}})
+	require.NoError(t, err)
+	blocker, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Blocker", DueAt: due})
+	require.NoError(t, err)
+
+	// Fields not named are left alone, whatever the task passed holds.
+	patch := &entity.Task{Title: "Patched", Priority: entity.PriorityHigh, Tags: []string{"work"}, BlockedBy: []int{int(blocker)}}
+	require.NoError(t, repo.PatchTask(ctx, owner, int(id), patch, []string{"title", "priority", "blocked_by"}))
+
+	task, err := repo.GetTask(ctx, owner, int(id))
+	require.NoError(t, err)
+	assert.Equal(t, "Patched", task.Title)
+	assert.Equal(t, entity.PriorityHigh, task.Priority)
+	assert.Equal(t, "Keep me", task.Description)
+	assert.True(t, task.DueAt.Equal(due))
+	assert.Equal(t, []string{"home"}, task.Tags)
+	assert.Equal(t, []int{int(blocker)}, task.BlockedBy)
+
+	assert.ErrorIs(t, repo.PatchTask(ctx, owner+1, int(id), patch, []string{"title"}), service.ErrNotFound)
+}
+
+func TestSQLite_Versions(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	id, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Task", DueAt: due})
+	require.NoError(t, err)
+
+	task, err := repo.GetTask(ctx, owner, int(id))
+	require.NoError(t, err)
+	assert.Equal(t, 1, task.Version)
+
+	// A write that does not name a version goes through and moves it on.
+	task.Version = 0
+	task.Title = "Renamed"
+	require.NoError(t, repo.UpdateTask(ctx, owner, int(id), task))
+	assert.Equal(t, 2, task.Version)
+
+	patch := &entity.Task{Title: "Patched", Tags: []string{"home"}, Version: 2}
+	require.NoError(t, repo.PatchTask(ctx, owner, int(id), patch, []string{"title", "tags"}))
+	assert.Equal(t, 3, patch.Version)
+
+	// A stale version changes nothing, tags included.
+	stale := &entity.Task{Title: "Stale", Tags: []string{"work"}, Version: 2}
+	assert.ErrorIs(t, repo.PatchTask(ctx, owner, int(id), stale, []string{"title", "tags"}), service.ErrVersionMismatch)
+	assert.ErrorIs(t, repo.UpdateTask(ctx, owner, int(id), stale), service.ErrVersionMismatch)
+	assert.ErrorIs(t, repo.DeleteTask(ctx, owner, int(id), 2), service.ErrVersionMismatch)
+
+	task, err = repo.GetTask(ctx, owner, int(id))
+	require.NoError(t, err)
+	assert.Equal(t, "Patched", task.Title)
+	assert.Equal(t, []string{"home"}, task.Tags)
+	assert.Equal(t, 3, task.Version)
+
+	require.NoError(t, repo.SetTaskParent(ctx, owner, int(id), nil))
+	require.NoError(t, repo.DeleteTask(ctx, owner, int(id), 4))
+	assert.ErrorIs(t, repo.DeleteTask(ctx, owner, int(id), 4), service.ErrNotFound)
+}
+
+func TestSQLite_Trash(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	rootID, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Root", DueAt: due, Tags: []string{"home"}})
+	require.NoError(t, err)
+	root := int(rootID)
+	childID, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Child", DueAt: due, ParentID: &root})
+	require.NoError(t, err)
+	child := int(childID)
+	blockedID, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Blocked", DueAt: due, BlockedBy: []int{root}})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.DeleteTask(ctx, owner, root, 1))
+
+	// Deleted tasks are gone from every read.
+	_, err = repo.GetTask(ctx, owner, child)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	tasks, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Empty(t, tasks[0].BlockedBy)
+	// Writes to a task keep its dependencies on blockers in the trash.
+	tasks[0].Title = "Renamed"
+	require.NoError(t, repo.UpdateTask(ctx, owner, int(blockedID), tasks[0]))
+	require.NoError(t, repo.PatchTask(ctx, owner, int(blockedID), &entity.Task{}, []string{"blocked_by"}))
+	blocked := true
+	tasks, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Blocked: &blocked, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+	tags, err := repo.GetTagList(ctx, owner)
+	require.NoError(t, err)
+	assert.Equal(t, 0, tags[0].Count)
+	assert.ErrorIs(t, repo.UpdateTask(ctx, owner, child, &entity.Task{Title: "Child", DueAt: due}), service.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteTask(ctx, owner, root, 0), service.ErrNotFound)
+
+	trash, err := repo.GetTrash(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, trash, 2)
+	assert.Equal(t, []int{root, child}, []int{trash[0].ID, trash[1].ID})
+	require.NotNil(t, trash[0].DeletedAt)
+	assert.Equal(t, []string{"home"}, trash[0].Tags)
+
+	assert.ErrorIs(t, repo.RestoreTask(ctx, owner, child), service.ErrParentInTrash)
+	assert.ErrorIs(t, repo.RestoreTask(ctx, owner, int(blockedID)), service.ErrNotFound)
+
+	// Restoring the root brings back the subtask deleted along with it.
+	require.NoError(t, repo.RestoreTask(ctx, owner, root))
+	task, err := repo.GetTask(ctx, owner, child)
+	require.NoError(t, err)
+	assert.Equal(t, 3, task.Version)
+	task, err = repo.GetTask(ctx, owner, int(blockedID))
+	require.NoError(t, err)
+	assert.Equal(t, []int{root}, task.BlockedBy)
+
+	// A subtask deleted on its own stays in the trash when its parent is
+	// deleted and restored.
+	require.NoError(t, repo.DeleteTask(ctx, owner, child, 0))
+	require.NoError(t, repo.DeleteTask(ctx, owner, root, 0))
+	require.NoError(t, repo.RestoreTask(ctx, owner, root))
+	_, err = repo.GetTask(ctx, owner, child)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	require.NoError(t, repo.DeleteTask(ctx, owner, root, 0))
+	purged, err := repo.PurgeTasks(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+	purged, err = repo.PurgeTasks(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+
+	trash, err = repo.GetTrash(ctx, owner)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+}
+
+func TestSQLite_History(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	owner := newSQLiteUser(t, repo, "john")
+	ctx := service.WithUser(context.Background(), &entity.User{ID: owner})
+
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	id, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Task", DueAt: due})
+	require.NoError(t, err)
+	parentID, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Parent", DueAt: due})
+	require.NoError(t, err)
+	parent := int(parentID)
+
+	require.NoError(t, repo.PatchTask(ctx, owner, int(id), &entity.Task{Title: "Renamed", Tags: []string{"home"}}, []string{"title", "tags"}))
+	require.NoError(t, repo.UpdateTask(ctx, owner, int(id), &entity.Task{Title: "Renamed", DueAt: due, Completed: true, Tags: []string{"home"}}))
+	require.NoError(t, repo.SetTaskParent(ctx, owner, int(id), &parent))
+	require.NoError(t, repo.DeleteTask(ctx, owner, parent, 0))
+	require.NoError(t, repo.RestoreTask(ctx, owner, parent))
+
+	events, err := repo.GetTaskHistory(ctx, owner, int(id), 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 6)
+
+	var operations []string
+	for i, event := range events {
+		operations = append(operations, event.Operation)
+		assert.Equal(t, owner, event.ActorID)
+		assert.Equal(t, len(events)-i, event.Version)
+	}
+	assert.Equal(t, []string{"restore", "delete", "update", "update", "update", "create"}, operations)
+
+	changes := func(event *entity.TaskEvent) string {
+		doc, err := json.Marshal(event.Changes)
+		require.NoError(t, err)
+		return string(doc)
+	}
+	assert.Equal(t, `[]`, changes(events[0]))
+	assert.Equal(t, fmt.Sprintf(`[{"field":"parent_id","before":null,"after":%d}]`, parent), changes(events[2]))
+	assert.Equal(t, `[{"field":"completed","before":false,"after":true}]`, changes(events[3]))
+	assert.Equal(t, `[{"field":"title","before":"Task","after":"Renamed"},{"field":"tags","before":null,"after":["home"]}]`, changes(events[4]))
+	assert.Equal(t, `[{"field":"title","before":null,"after":"Task"},{"field":"due_at","before":null,"after":"2024-01-01T09:00:00Z"}]`, changes(events[5]))
+
+	older, err := repo.GetTaskHistory(ctx, owner, int(id), events[3].ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, events[4:], older)
+
+	_, err = repo.GetTaskHistory(ctx, owner+1, int(id), 0, 10)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestSQLite_Snapshots(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	owner := newSQLiteUser(t, repo, "john")
+	ctx := context.Background()
+
+	before := time.Now()
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	id, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Task", DueAt: due, Tags: []string{"home"}})
+	require.NoError(t, err)
+	created := time.Now()
+	require.NoError(t, repo.PatchTask(ctx, owner, int(id), &entity.Task{Title: "Renamed"}, []string{"title", "tags"}))
+	require.NoError(t, repo.DeleteTask(ctx, owner, int(id), 0))
+
+	task, err := repo.GetTaskVersion(ctx, owner, int(id), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Task", task.Title)
+	assert.Equal(t, []string{"home"}, task.Tags)
+	assert.True(t, due.Equal(task.DueAt))
+
+	task, err = repo.GetTaskVersion(ctx, owner, int(id), 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", task.Title)
+	assert.Empty(t, task.Tags)
+
+	_, err = repo.GetTaskVersion(ctx, owner, int(id), 9)
+	assert.ErrorIs(t, err, service.ErrVersionNotFound)
+
+	task, err = repo.GetTaskAsOf(ctx, owner, int(id), created)
+	require.NoError(t, err)
+	assert.Equal(t, 1, task.Version)
+
+	_, err = repo.GetTaskAsOf(ctx, owner, int(id), before)
+	assert.ErrorIs(t, err, service.ErrVersionNotFound)
+	_, err = repo.GetTaskAsOf(ctx, owner, int(id), time.Now())
+	assert.ErrorIs(t, err, service.ErrVersionNotFound)
+
+	_, err = repo.GetTaskVersion(ctx, owner+1, int(id), 1)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestSQLite_Transaction(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	owner := newSQLiteUser(t, repo, "john")
+	ctx := context.Background()
+
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	err := repo.Transaction(ctx, func(tx service.Repository) error {
+		id, err := tx.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Kept", DueAt: due})
+		require.NoError(t, err)
+
+		err = tx.UpdateTask(ctx, owner, int(id), &entity.Task{Title: "Lost", DueAt: due, Tags: []string{"home"}, Version: 5})
+		assert.ErrorIs(t, err, service.ErrVersionMismatch)
+
+		task, err := tx.GetTask(ctx, owner, int(id))
+		require.NoError(t, err)
+		assert.Equal(t, "Kept", task.Title)
+		return nil
+	})
+	require.NoError(t, err)
+
+	err = repo.Transaction(ctx, func(tx service.Repository) error {
+		_, err := tx.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Rolled back", DueAt: due})
+		require.NoError(t, err)
+		return service.ErrInvalidData
+	})
+	assert.ErrorIs(t, err, service.ErrInvalidData)
+
+	tasks, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Kept", tasks[0].Title)
+	assert.Equal(t, 1, tasks[0].Version)
+	assert.Empty(t, tasks[0].Tags)
+
+	events, err := repo.GetTaskHistory(ctx, owner, tasks[0].ID, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+func TestSQLite_GetTaskList(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		_, err := repo.InsertTask(ctx, &entity.Task{
+			OwnerID:   owner,
+			Title:     "Test Task",
+			DueAt:     day.AddDate(0, 0, i%2),
+			Completed: i%2 == 0,
+		})
+		require.NoError(t, err)
+	}
+
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, result, 5)
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Offset: 1, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, taskIDs(result))
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Completed: boolPtr(true), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 3, 5}, taskIDs(result))
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, DueFrom: day.AddDate(0, 0, 1), DueTo: day.AddDate(0, 0, 2), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 4}, taskIDs(result))
+}
+
+func TestSQLite_Overdue(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	for _, task := range []*entity.Task{
+		{Title: "Missed", DueAt: today.Add(9 * time.Hour)},
+		{Title: "Done", DueAt: today.Add(9 * time.Hour), Completed: true},
+		{Title: "Today, all day", DueAt: today, AllDay: true},
+		{Title: "Yesterday, all day", DueAt: today.AddDate(0, 0, -1), AllDay: true},
+		{Title: "In two days", DueAt: today.AddDate(0, 0, 2)},
+		{Title: "In ten days", DueAt: today.AddDate(0, 0, 10)},
+	} {
+		task.OwnerID = owner
+		_, err := repo.InsertTask(ctx, task)
+		require.NoError(t, err)
+	}
+
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Overdue: boolPtr(true), Now: now, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 4}, taskIDs(result))
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Overdue: boolPtr(false), Now: now, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 5, 6}, taskIDs(result))
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, DueWithin: 7 * 24 * time.Hour, Now: now, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 5}, taskIDs(result))
+}
+
+func TestSQLite_TaskIsolation(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	john := newSQLiteUser(t, repo, "john")
+	jane := newSQLiteUser(t, repo, "jane")
+
+	task := &entity.Task{OwnerID: john, Title: "John's Task", DueAt: time.Now()}
+	id, err := repo.InsertTask(ctx, task)
+	require.NoError(t, err)
+
+	_, err = repo.GetTask(ctx, jane, int(id))
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	assert.ErrorIs(t, repo.UpdateTask(ctx, jane, int(id), &entity.Task{Title: "Hijacked", DueAt: time.Now()}), service.ErrNotFound)
+	assert.ErrorIs(t, repo.DeleteTask(ctx, jane, int(id), 0), service.ErrNotFound)
+
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: jane, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, result)
+
+	result2, err := repo.GetTask(ctx, john, int(id))
+	require.NoError(t, err)
+	assert.Equal(t, "John's Task", result2.Title)
+}
+
+func TestSQLite_Projects(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+	other := newSQLiteUser(t, repo, "jane")
+
+	id, err := repo.InsertProject(ctx, &entity.Project{OwnerID: owner, Name: "Home"})
+	require.NoError(t, err)
+	project := int(id)
+
+	require.NoError(t, repo.UpdateProject(ctx, owner, project, &entity.Project{Name: "House"}))
+	result, err := repo.GetProject(ctx, owner, project)
+	require.NoError(t, err)
+	assert.Equal(t, "House", result.Name)
+
+	_, err = repo.GetProject(ctx, other, project)
+	assert.ErrorIs(t, err, service.ErrProjectNotFound)
+	assert.ErrorIs(t, repo.UpdateProject(ctx, other, project, &entity.Project{Name: "Hijacked"}), service.ErrProjectNotFound)
+	assert.ErrorIs(t, repo.DeleteProject(ctx, other, project, 0), service.ErrProjectNotFound)
+
+	inbox, err := repo.EnsureInboxProject(ctx, owner)
+	require.NoError(t, err)
+	assert.True(t, inbox.Inbox)
+	again, err := repo.EnsureInboxProject(ctx, owner)
+	require.NoError(t, err)
+	assert.Equal(t, inbox.ID, again.ID)
+
+	projects, err := repo.GetProjectList(ctx, owner)
+	require.NoError(t, err)
+	assert.Len(t, projects, 2)
+
+	_, err = repo.InsertTask(ctx, &entity.Task{OwnerID: owner, ProjectID: &project, Title: "Task", DueAt: time.Now()})
+	require.NoError(t, err)
+
+	filtered, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, ProjectID: project, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, taskIDs(filtered))
+}
+
+func TestSQLite_DeleteProject(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+
+	inbox, err := repo.EnsureInboxProject(ctx, owner)
+	require.NoError(t, err)
+
+	for _, name := range []string{"Moved", "Cascaded"} {
+		id, err := repo.InsertProject(ctx, &entity.Project{OwnerID: owner, Name: name})
+		require.NoError(t, err)
+		project := int(id)
+		_, err = repo.InsertTask(ctx, &entity.Task{OwnerID: owner, ProjectID: &project, Title: name, DueAt: time.Now()})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, repo.DeleteProject(ctx, owner, 2, inbox.ID))
+	moved, err := repo.GetTask(ctx, owner, 1)
+	require.NoError(t, err)
+	assert.Equal(t, inbox.ID, *moved.ProjectID)
+
+	// A subtask in another project, and a task already in the trash.
+	parent, cascaded := 2, 3
+	_, err = repo.InsertTask(ctx, &entity.Task{OwnerID: owner, ProjectID: &inbox.ID, ParentID: &parent, Title: "Subtask", DueAt: time.Now()})
+	require.NoError(t, err)
+	_, err = repo.InsertTask(ctx, &entity.Task{OwnerID: owner, ProjectID: &cascaded, Title: "Trashed", DueAt: time.Now()})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteTask(ctx, owner, 4, 0))
+
+	require.NoError(t, repo.DeleteProject(ctx, owner, 3, 0))
+	_, err = repo.GetTask(ctx, owner, 2)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	_, err = repo.GetProject(ctx, owner, 3)
+	assert.ErrorIs(t, err, service.ErrProjectNotFound)
+
+	trash, err := repo.GetTrash(ctx, owner)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{2, 3, 4}, taskIDs(trash))
+	history, err := repo.GetTaskHistory(ctx, owner, 2, 0, 10)
+	require.NoError(t, err)
+	assert.Equal(t, entity.EventDelete, history[0].Operation)
+
+	require.NoError(t, repo.RestoreTask(ctx, owner, 2))
+	restored, err := repo.GetTask(ctx, owner, 2)
+	require.NoError(t, err)
+	assert.Nil(t, restored.ProjectID)
+	subtask, err := repo.GetTask(ctx, owner, 3)
+	require.NoError(t, err)
+	assert.Equal(t, inbox.ID, *subtask.ProjectID)
+	require.NoError(t, repo.RestoreTask(ctx, owner, 4))
+}
+
+func TestSQLite_Tags(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+
+	for _, tags := range [][]string{{"home"}, {"home", "urgent"}, {"work"}} {
+		_, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Task", DueAt: time.Now(), Tags: tags})
+		require.NoError(t, err)
+	}
+
+	result, err := repo.GetTask(ctx, owner, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"home", "urgent"}, result.Tags)
+
+	anyOf, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Tags: []string{"urgent", "work"}, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, taskIDs(anyOf))
+	assert.Equal(t, []string{"work"}, anyOf[1].Tags)
+
+	allOf, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Tags: []string{"home", "urgent"}, TagMatch: entity.TagMatchAll, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2}, taskIDs(allOf))
+
+	require.NoError(t, repo.UpdateTask(ctx, owner, 3, &entity.Task{Title: "Task", DueAt: time.Now(), Tags: []string{"urgent"}}))
+
+	tags, err := repo.GetTagList(ctx, owner)
+	require.NoError(t, err)
+	counts := make(map[string]int)
+	for _, tag := range tags {
+		counts[tag.Name] = tag.Count
+	}
+	assert.Equal(t, map[string]int{"home": 2, "urgent": 2, "work": 0}, counts)
+
+	// tags come back sorted by name: home, urgent, work
+	home, urgent, work := tags[0].ID, tags[1].ID, tags[2].ID
+	assert.ErrorIs(t, repo.RenameTag(ctx, owner, work, "home"), service.ErrTagExists)
+	require.NoError(t, repo.RenameTag(ctx, owner, work, "office"))
+
+	require.NoError(t, repo.MergeTag(ctx, owner, urgent, home))
+	result, err = repo.GetTask(ctx, owner, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"home"}, result.Tags)
+	result, err = repo.GetTask(ctx, owner, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"home"}, result.Tags)
+	history, err := repo.GetTaskHistory(ctx, owner, 3, 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, result.Version, history[0].Version)
+	assert.Equal(t, "tags", history[0].Changes[0].Field)
+	assert.JSONEq(t, `["urgent"]`, string(history[0].Changes[0].Before))
+	assert.JSONEq(t, `["home"]`, string(history[0].Changes[0].After))
+
+	other := newSQLiteUser(t, repo, "jane")
+	assert.ErrorIs(t, repo.MergeTag(ctx, other, work, home), service.ErrTagNotFound)
+	assert.ErrorIs(t, repo.RenameTag(ctx, other, home, "mine"), service.ErrTagNotFound)
+}
+
+func TestSQLite_SortByPriority(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tasks := []*entity.Task{
+		{OwnerID: owner, Title: "Low", DueAt: day, Priority: entity.PriorityLow},
+		{OwnerID: owner, Title: "Urgent later", DueAt: day.AddDate(0, 0, 1), Priority: entity.PriorityUrgent},
+		{OwnerID: owner, Title: "Urgent", DueAt: day, Priority: entity.PriorityUrgent},
+		{OwnerID: owner, Title: "None", DueAt: day},
+	}
+	for _, task := range tasks {
+		_, err := repo.InsertTask(ctx, task)
+		require.NoError(t, err)
+	}
+
+	sort := []entity.SortField{{Field: "priority", Desc: true}, {Field: "due_at"}}
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Sort: sort, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 2, 1, 4}, taskIDs(result))
+	assert.Equal(t, entity.PriorityUrgent, result[0].Priority)
+}
+
+func TestSQLite_Keyset(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, task := range []*entity.Task{
+		{OwnerID: owner, Title: "Low groceries", DueAt: day, Priority: entity.PriorityLow},
+		{OwnerID: owner, Title: "Urgent later", DueAt: day.AddDate(0, 0, 1), Priority: entity.PriorityUrgent},
+		{OwnerID: owner, Title: "Urgent groceries", Description: "groceries", DueAt: day, Priority: entity.PriorityUrgent},
+		{OwnerID: owner, Title: "None", DueAt: day},
+	} {
+		_, err := repo.InsertTask(ctx, task)
+		require.NoError(t, err)
+	}
+
+	// The list reads 3, 2, 1, 4. The keyset of task 2 holds its due time in
+	// another zone, which must not matter.
+	sort := []entity.SortField{{Field: "priority", Desc: true}, {Field: "due_at"}}
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	after := &entity.TaskKeyset{ID: 2, DueAt: day.AddDate(0, 0, 1).In(newYork), Priority: entity.PriorityUrgent}
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Sort: sort, Keyset: after, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 4}, taskIDs(result))
+
+	before := &entity.TaskKeyset{ID: 1, DueAt: day, Priority: entity.PriorityLow, Backward: true}
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Sort: sort, Keyset: before, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 2}, taskIDs(result))
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Sort: sort, Keyset: before, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []int{2}, taskIDs(result))
+
+	// Ranked search results page on their rank.
+	ranked, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Query: "groceries", Limit: 10})
+	require.NoError(t, err)
+	require.Equal(t, []int{3, 1}, taskIDs(ranked))
+	assert.Greater(t, ranked[0].Rank, ranked[1].Rank)
+
+	after = &entity.TaskKeyset{ID: 3, Rank: ranked[0].Rank}
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Query: "groceries", Keyset: after, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1}, taskIDs(result))
+}
+
+func TestSQLite_DueRangeAcrossTimeZones(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// Both are late on January 1st in New York, but already January 2nd in UTC.
+	for _, dueAt := range []time.Time{
+		time.Date(2024, 1, 1, 23, 30, 0, 0, newYork),
+		time.Date(2024, 1, 2, 13, 0, 0, 0, tokyo),
+		time.Date(2024, 1, 2, 9, 0, 0, 0, newYork),
+	} {
+		_, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Task", DueAt: dueAt})
+		require.NoError(t, err)
+	}
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, newYork)
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, DueFrom: day, DueTo: day.AddDate(0, 0, 1), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, taskIDs(result))
+
+	stored, err := repo.GetTask(ctx, owner, 1)
+	require.NoError(t, err)
+	assert.True(t, stored.DueAt.Equal(time.Date(2024, 1, 1, 23, 30, 0, 0, newYork)))
+}
+
+func taskIDs(tasks []*entity.Task) []int {
+	var result []int
+	for _, task := range tasks {
+		result = append(result, task.ID)
+	}
+
+	return result
+}
+
+func TestSQLite_CompleteOccurrence(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+
+	task := &entity.Task{OwnerID: owner, Title: "Standup", DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), Tags: []string{"work"}, Recurrence: "FREQ=DAILY"}
+	_, err := repo.InsertTask(ctx, task)
+	require.NoError(t, err)
+
+	next := *task
+	next.DueAt = task.DueAt.AddDate(0, 0, 1)
+	task.Completed, task.Recurrence = true, ""
+
+	id, err := repo.CompleteOccurrence(ctx, owner, 1, task, &next)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), id)
+
+	result, err := repo.GetTask(ctx, owner, 1)
+	require.NoError(t, err)
+	assert.True(t, result.Completed)
+	assert.Empty(t, result.Recurrence)
+
+	result, err = repo.GetTask(ctx, owner, 2)
+	require.NoError(t, err)
+	assert.False(t, result.Completed)
+	assert.Equal(t, "FREQ=DAILY", result.Recurrence)
+	assert.Equal(t, []string{"work"}, result.Tags)
+	assert.True(t, next.DueAt.Equal(result.DueAt))
+
+	// A completion racing the one above read version 1 too.
+	stale := *task
+	stale.Version = 1
+	_, err = repo.CompleteOccurrence(ctx, owner, 1, &stale, &next)
+	assert.ErrorIs(t, err, service.ErrVersionMismatch)
+	_, err = repo.CompleteOccurrence(ctx, owner, 99, task, &next)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	_, err = repo.GetTask(ctx, owner, 3)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestSQLite_Subtasks(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+	due := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	insert := func(title string, parentID *int, completed bool) int {
+		id, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: title, DueAt: due, ParentID: parentID, Completed: completed})
+		require.NoError(t, err)
+		return int(id)
+	}
+	root := insert("Root", nil, false)
+	child := insert("Child", &root, true)
+	grandchild := insert("Grandchild", &child, false)
+	other := insert("Other", nil, false)
+
+	subtree, err := repo.GetSubtree(ctx, owner, root)
+	require.NoError(t, err)
+	assert.Equal(t, []int{root, child, grandchild}, taskIDs(subtree))
+	assert.Equal(t, child, *subtree[2].ParentID)
+
+	open, err := repo.CountOpenSubtasks(ctx, owner, root)
+	require.NoError(t, err)
+	assert.Equal(t, 1, open)
+
+	children, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, ParentID: root, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{child}, taskIDs(children))
+
+	require.NoError(t, repo.SetTaskParent(ctx, owner, other, &grandchild))
+	subtree, err = repo.GetSubtree(ctx, owner, child)
+	require.NoError(t, err)
+	assert.Equal(t, []int{child, grandchild, other}, taskIDs(subtree))
+
+	_, err = repo.GetSubtree(ctx, owner+1, root)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	require.NoError(t, repo.DeleteTask(ctx, owner, root, 0))
+	_, err = repo.GetTask(ctx, owner, other)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestSQLite_Dependencies(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+	due := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	design, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Design", DueAt: due})
+	require.NoError(t, err)
+	review, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Review", DueAt: due, Completed: true})
+	require.NoError(t, err)
+	build, err := repo.InsertTask(ctx, &entity.Task{OwnerID: owner, Title: "Build", DueAt: due, BlockedBy: []int{int(design), int(review)}})
+	require.NoError(t, err)
+
+	task, err := repo.GetTask(ctx, owner, int(build))
+	require.NoError(t, err)
+	assert.Equal(t, []int{int(design), int(review)}, task.BlockedBy)
+
+	blocked, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Blocked: boolPtr(true), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{int(build)}, taskIDs(blocked))
+
+	unblocked, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Blocked: boolPtr(false), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{int(design), int(review)}, taskIDs(unblocked))
+
+	dependencies, err := repo.GetDependencies(ctx, owner)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.Dependency{{TaskID: int(build), BlockerID: int(design)}, {TaskID: int(build), BlockerID: int(review)}}, dependencies)
+
+	require.NoError(t, repo.DeleteTask(ctx, owner, int(design), 0))
+	task, err = repo.GetTask(ctx, owner, int(build))
+	require.NoError(t, err)
+	assert.Equal(t, []int{int(review)}, task.BlockedBy)
+
+	task.BlockedBy = nil
+	require.NoError(t, repo.UpdateTask(ctx, owner, int(build), task))
+	dependencies, err = repo.GetDependencies(ctx, owner)
+	require.NoError(t, err)
+	assert.Empty(t, dependencies)
+}
+
+func TestSQLite_Search(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+	other := newSQLiteUser(t, repo, "jane")
+	due := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	insert := func(ownerID int, title, description string) int {
+		id, err := repo.InsertTask(ctx, &entity.Task{OwnerID: ownerID, Title: title, Description: description, DueAt: due})
+		require.NoError(t, err)
+		return int(id)
+	}
+	mention := insert(owner, "Call the bank", "ask about groceries budget")
+	groceries := insert(owner, "Groceries", "milk, bread and more groceries")
+	insert(owner, "Write report", "")
+	insert(other, "Groceries", "")
+
+	result, err := repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Query: "grocer", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{groceries, mention}, taskIDs(result))
+	assert.Contains(t, result[0].Snippet, "<b>Groceries</b>")
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Query: "grocer milk", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{groceries}, taskIDs(result))
+
+	// Task text is escaped, only the highlights are markup.
+	insert(owner, `<img src=x onerror="alert(1)"> payload`, "")
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Query: "payload", Limit: 10})
+	require.NoError(t, err)
+	assert.NotContains(t, result[0].Snippet, "<img")
+	assert.Contains(t, result[0].Snippet, "<b>payload</b>")
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Query: "grocer", Sort: []entity.SortField{{Field: "id"}}, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{mention, groceries}, taskIDs(result))
+
+	task, err := repo.GetTask(ctx, owner, mention)
+	require.NoError(t, err)
+	task.Description = "ask about the mortgage"
+	require.NoError(t, repo.UpdateTask(ctx, owner, mention, task))
+	require.NoError(t, repo.DeleteTask(ctx, owner, groceries, 0))
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Query: "grocer", Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, result)
+
+	result, err = repo.GetTaskList(ctx, entity.TaskFilter{OwnerID: owner, Query: "mort", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, []int{mention}, taskIDs(result))
+}
+
+func TestSQLite_Users(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	user := &entity.User{Username: "john", PasswordHash: "hash", CreatedAt: time.Now().UTC().Truncate(time.Second)}
+
+	id, err := repo.InsertUser(ctx, user)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+
+	_, err = repo.InsertUser(ctx, user)
+	assert.ErrorIs(t, err, service.ErrUserExists)
+
+	result, err := repo.GetUserByUsername(ctx, "john")
+	require.NoError(t, err)
+	assert.Equal(t, "hash", result.PasswordHash)
+	assert.True(t, user.CreatedAt.Equal(result.CreatedAt))
+
+	_, err = repo.GetUserByUsername(ctx, "jane")
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestSQLite_MigrationsRoundTrip(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+
+	m, err := repo.Migrator()
+	require.NoError(t, err)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, m.Down(ctx, len(statuses)))
+	require.NoError(t, m.Up(ctx))
+}
+
+func TestSQLite_MigrateDateToDueAt(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	owner := newSQLiteUser(t, repo, "john")
+
+	m, err := repo.Migrator()
+	require.NoError(t, err)
+	// Roll back to just before due_at replaced date.
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	steps := 0
+	for _, status := range statuses {
+		if status.Applied && status.Version >= 7 {
+			steps++
+		}
+	}
+	require.NoError(t, m.Down(ctx, steps))
+
+	_, err = repo.ExecContext(ctx, "INSERT INTO tasks(owner_id, title, description, date) VALUES ($1, $2, $3, $4)", owner, "Old Task", "", "2024-01-02")
+	require.NoError(t, err)
+	require.NoError(t, m.Up(ctx))
+
+	task, err := repo.GetTask(ctx, owner, 1)
+	require.NoError(t, err)
+	assert.True(t, task.AllDay)
+	assert.True(t, task.DueAt.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+// addTaskTags attaches tags to a task, creating the owner's tags on first use.
+func addTaskTags(ctx context.Context, tx dbTx, ownerID int, taskID int64, tags []string) error {
+	for _, tag := range tags {
+		var tagID int64
+		err := tx.QueryRowContext(ctx, "INSERT INTO tags(owner_id, name) VALUES ($1, $2) ON CONFLICT (owner_id, name) DO UPDATE SET name = excluded.name RETURNING id", ownerID, tag).Scan(&tagID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO task_tags(task_id, tag_id) VALUES ($1, $2)", taskID, tagID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadTags fills in the tags of every task with a single query.
+func loadTags(ctx context.Context, q querier, tasks []*entity.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*entity.Task, len(tasks))
+	args := make([]interface{}, len(tasks))
+	placeholders := make([]string, len(tasks))
+	for i, task := range tasks {
+		byID[task.ID] = task
+		args[i] = task.ID
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+
+	rows, err := q.QueryContext(ctx, "SELECT tt.task_id, t.name FROM task_tags tt JOIN tags t ON t.id = tt.tag_id WHERE tt.task_id IN ("+strings.Join(placeholders, ", ")+") ORDER BY t.name", args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID int
+		var name string
+		err = rows.Scan(&taskID, &name)
+		if err != nil {
+			return err
+		}
+
+		if task, ok := byID[taskID]; ok {
+			task.Tags = append(task.Tags, name)
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *Repository) GetTagList(ctx context.Context, ownerID int) ([]*entity.Tag, error) {
+	rows, err := r.QueryContext(ctx, "SELECT t.id, t.owner_id, t.name, COUNT(k.id) FROM tags t LEFT JOIN task_tags tt ON tt.tag_id = t.id LEFT JOIN tasks k ON k.id = tt.task_id AND k.deleted_at IS NULL WHERE t.owner_id = $1 GROUP BY t.id, t.owner_id, t.name ORDER BY t.name", ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*entity.Tag
+	for rows.Next() {
+		var tag entity.Tag
+		err = rows.Scan(&tag.ID, &tag.OwnerID, &tag.Name, &tag.Count)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+
+	return tags, rows.Err()
+}
+
+func (r *Repository) RenameTag(ctx context.Context, ownerID int, id int, name string) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var old string
+	err = tx.QueryRowContext(ctx, "SELECT name FROM tags WHERE id = $1 AND owner_id = $2", id, ownerID).Scan(&old)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrTagNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	tagged, err := taggedTasks(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE tags SET name=$1 WHERE id = $2 AND owner_id = $3", name, id, ownerID)
+	if r.dialect.isUniqueViolation(err) {
+		return service.ErrTagExists
+	}
+	if err != nil {
+		return err
+	}
+
+	err = recordRetag(ctx, tx, ownerID, tagged, old, name)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) MergeTag(ctx context.Context, ownerID int, id int, into int) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var from, to string
+	err = tx.QueryRowContext(ctx, "SELECT f.name, t.name FROM tags f JOIN tags t ON t.owner_id = f.owner_id WHERE f.owner_id = $1 AND f.id = $2 AND t.id = $3 AND f.id <> t.id", ownerID, id, into).Scan(&from, &to)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrTagNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	tagged, err := taggedTasks(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO task_tags(task_id, tag_id) SELECT task_id, CAST($1 AS INTEGER) FROM task_tags WHERE tag_id = $2 ON CONFLICT DO NOTHING", into, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM tags WHERE id = $1 AND owner_id = $2", id, ownerID)
+	if err != nil {
+		return err
+	}
+
+	err = recordRetag(ctx, tx, ownerID, tagged, from, to)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// taggedTasks returns the live tasks carrying tag id, with their tags. Tasks
+// in the trash are retagged too, but keep their history as it is.
+func taggedTasks(ctx context.Context, tx dbTx, id int) ([]*entity.Task, error) {
+	rows, err := tx.QueryContext(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id IN (SELECT task_id FROM task_tags WHERE tag_id = $1) AND deleted_at IS NULL ORDER BY id", id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*entity.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	err = loadTaskDetails(ctx, tx, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// recordRetag bumps the version of tasks, read before their tag from became
+// to, and records the change to their tags.
+func recordRetag(ctx context.Context, tx dbTx, ownerID int, tasks []*entity.Task, from string, to string) error {
+	for _, before := range tasks {
+		after := *before
+		after.Tags = nil
+		for _, name := range before.Tags {
+			if name == from {
+				name = to
+			}
+			if !slices.Contains(after.Tags, name) {
+				after.Tags = append(after.Tags, name)
+			}
+		}
+		slices.Sort(after.Tags)
+
+		changes, err := entity.TaskChanges(before, &after, []string{"tags"})
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE tasks SET version = version + 1 WHERE id = $1", before.ID)
+		if err != nil {
+			return err
+		}
+
+		err = recordEvent(ctx, tx, ownerID, int64(before.ID), entity.EventUpdate, changes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,77 +1,540 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 	"todo-list/internal/entity"
+	"todo-list/internal/service"
 )
 
-func (r *Repository) InsertTask(task *entity.Task) (int64, error) {
+const taskColumns = "id, owner_id, project_id, title, description, due_at, all_day, completed, priority, recurrence, parent_id, version"
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// querier runs queries on the database or within a transaction.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func scanTask(row scanner) (*entity.Task, error) {
+	var task entity.Task
+	err := row.Scan(taskFields(&task)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &task, nil
+}
+
+// taskFields lists the scan destinations of taskColumns.
+func taskFields(task *entity.Task) []any {
+	return []any{&task.ID, &task.OwnerID, &task.ProjectID, &task.Title, &task.Description, &task.DueAt, &task.AllDay, &task.Completed, &task.Priority, &task.Recurrence, &task.ParentID, &task.Version}
+}
+
+func (r *Repository) InsertTask(ctx context.Context, task *entity.Task) (int64, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	id, err := insertTask(ctx, tx, task)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, tx.Commit()
+}
+
+func insertTask(ctx context.Context, tx dbTx, task *entity.Task) (int64, error) {
 	var id int64
-	err := r.QueryRow("INSERT INTO tasks(title, description, date, completed) VALUES ($1, $2, $3, $4) RETURNING id", task.Title, task.Description, task.Date, task.Completed).Scan(&id)
+	err := tx.QueryRowContext(ctx, "INSERT INTO tasks(owner_id, project_id, title, description, due_at, all_day, completed, priority, recurrence, parent_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id", task.OwnerID, task.ProjectID, task.Title, task.Description, task.DueAt.UTC(), task.AllDay, task.Completed, task.Priority, task.Recurrence, task.ParentID).Scan(&id)
+	if err != nil {
+		return -1, err
+	}
+
+	err = addTaskTags(ctx, tx, task.OwnerID, id, task.Tags)
+	if err != nil {
+		return -1, err
+	}
+
+	err = addTaskBlockers(ctx, tx, id, task.BlockedBy)
+	if err != nil {
+		return -1, err
+	}
+
+	changes, err := entity.TaskChanges(nil, task, nil)
 	if err != nil {
 		return -1, err
 	}
 
-	return id, nil
+	return id, recordEvent(ctx, tx, task.OwnerID, id, entity.EventCreate, changes)
 }
 
-func (r *Repository) GetTask(id int) (*entity.Task, error) {
-	var task entity.Task
-	err := r.QueryRow("SELECT id, title, description, date, completed FROM tasks WHERE id = $1", id).Scan(&task.ID, &task.Title, &task.Description, &task.Date, &task.Completed)
+func (r *Repository) GetTask(ctx context.Context, ownerID int, id int) (*entity.Task, error) {
+	return getTask(ctx, r, ownerID, id, "")
+}
+
+// lockTask reads a task about to be written in tx, holding its row until tx
+// ends so that its history records what the write really replaced.
+func (r *Repository) lockTask(ctx context.Context, tx dbTx, ownerID int, id int) (*entity.Task, error) {
+	return getTask(ctx, tx, ownerID, id, r.dialect.forUpdate())
+}
+
+// getTask reads one of the owner's live tasks with its tags and blockers. lock
+// ends the query on the tasks table.
+func getTask(ctx context.Context, q querier, ownerID int, id int, lock string) (*entity.Task, error) {
+	task, err := scanTask(q.QueryRowContext(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id = $1 AND owner_id = $2 AND deleted_at IS NULL"+lock, id, ownerID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &task, nil
+	err = loadTaskDetails(ctx, q, []*entity.Task{task})
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
 }
 
-func (r *Repository) UpdateTask(id int, task *entity.Task) error {
-	err := r.QueryRow("UPDATE tasks SET title=$1, description=$2, date=$3, completed=$4 WHERE id = $5", task.Title, task.Description, task.Date, task.Completed, id).Scan()
+func (r *Repository) UpdateTask(ctx context.Context, ownerID int, id int, task *entity.Task) error {
+	tx, err := r.begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	return nil
+	err = r.updateTask(ctx, tx, ownerID, id, task)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) CompleteOccurrence(ctx context.Context, ownerID int, id int, task *entity.Task, next *entity.Task) (int64, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return -1, err
+	}
+	defer tx.Rollback()
+
+	err = r.updateTask(ctx, tx, ownerID, id, task)
+	if err != nil {
+		return -1, err
+	}
+
+	nextID, err := insertTask(ctx, tx, next)
+	if err != nil {
+		return -1, err
+	}
+
+	return nextID, tx.Commit()
+}
+
+func (r *Repository) updateTask(ctx context.Context, tx dbTx, ownerID int, id int, task *entity.Task) error {
+	before, err := r.lockTask(ctx, tx, ownerID, id)
+	if err != nil {
+		return err
+	}
+
+	row := tx.QueryRowContext(ctx, "UPDATE tasks SET project_id=$1, title=$2, description=$3, due_at=$4, all_day=$5, completed=$6, priority=$7, recurrence=$8, parent_id=$9, version = version + 1 WHERE id = $10 AND owner_id = $11 AND deleted_at IS NULL RETURNING version", task.ProjectID, task.Title, task.Description, task.DueAt.UTC(), task.AllDay, task.Completed, task.Priority, task.Recurrence, task.ParentID, id, ownerID)
+	err = scanVersion(row, task)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM task_tags WHERE task_id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	err = addTaskTags(ctx, tx, ownerID, int64(id), task.Tags)
+	if err != nil {
+		return err
+	}
+
+	err = replaceTaskBlockers(ctx, tx, int64(id), task.BlockedBy)
+	if err != nil {
+		return err
+	}
+
+	changes, err := entity.TaskChanges(before, task, nil)
+	if err != nil {
+		return err
+	}
+
+	return recordEvent(ctx, tx, ownerID, int64(id), entity.EventUpdate, changes)
+}
+
+func (r *Repository) PatchTask(ctx context.Context, ownerID int, id int, task *entity.Task, fields []string) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := r.lockTask(ctx, tx, ownerID, id)
+	if err != nil {
+		return err
+	}
+
+	// The version goes up even when only tags or blockers change, which
+	// also keeps the statement valid then.
+	sets := []string{"version = version + 1"}
+	var args []any
+	for _, field := range fields {
+		if field == "tags" || field == "blocked_by" {
+			continue
+		}
+
+		column, value, ok := taskColumn(task, field)
+		if !ok {
+			return fmt.Errorf("unknown task field %q", field)
+		}
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	args = append(args, id, ownerID)
+	row := tx.QueryRowContext(ctx, fmt.Sprintf("UPDATE tasks SET %s WHERE id = $%d AND owner_id = $%d AND deleted_at IS NULL RETURNING version", strings.Join(sets, ", "), len(args)-1, len(args)), args...)
+	err = scanVersion(row, task)
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(fields, "tags") {
+		_, err = tx.ExecContext(ctx, "DELETE FROM task_tags WHERE task_id = $1", id)
+		if err != nil {
+			return err
+		}
+
+		err = addTaskTags(ctx, tx, ownerID, int64(id), task.Tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	if slices.Contains(fields, "blocked_by") {
+		err = replaceTaskBlockers(ctx, tx, int64(id), task.BlockedBy)
+		if err != nil {
+			return err
+		}
+	}
+
+	changes, err := entity.TaskChanges(before, task, fields)
+	if err != nil {
+		return err
+	}
+
+	err = recordEvent(ctx, tx, ownerID, int64(id), entity.EventUpdate, changes)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// taskColumn returns the column behind a task field and its value in task.
+func taskColumn(task *entity.Task, field string) (string, any, bool) {
+	switch field {
+	case "project_id":
+		return "project_id", task.ProjectID, true
+	case "parent_id":
+		return "parent_id", task.ParentID, true
+	case "title":
+		return "title", task.Title, true
+	case "description":
+		return "description", task.Description, true
+	case "due_at":
+		return "due_at", task.DueAt.UTC(), true
+	case "all_day":
+		return "all_day", task.AllDay, true
+	case "completed":
+		return "completed", task.Completed, true
+	case "priority":
+		return "priority", task.Priority, true
+	case "recurrence":
+		return "recurrence", task.Recurrence, true
+	}
+
+	return "", nil, false
+}
+
+// DeleteTask moves a task and its subtasks to the trash. They share one
+// deleted_at, which is how RestoreTask tells them apart from subtasks
+// deleted on their own before.
+func (r *Repository) DeleteTask(ctx context.Context, ownerID int, id int, version int) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	rows, err := tx.QueryContext(ctx, subtreeQuery+"UPDATE tasks SET deleted_at = $3, version = version + 1 WHERE id IN (SELECT id FROM subtree) AND id <> $1 RETURNING id", id, ownerID, now)
+	if err != nil {
+		return err
+	}
+
+	err = recordEvents(ctx, tx, ownerID, rows, entity.EventDelete, nil)
+	if err != nil {
+		return err
+	}
+
+	var current int
+	err = tx.QueryRowContext(ctx, "UPDATE tasks SET deleted_at = $1, version = version + 1 WHERE id = $2 AND owner_id = $3 AND deleted_at IS NULL RETURNING version", now, id, ownerID).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if version != 0 && current != version+1 {
+		return service.ErrVersionMismatch
+	}
+
+	err = recordEvent(ctx, tx, ownerID, int64(id), entity.EventDelete, nil)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Repository) GetTrash(ctx context.Context, ownerID int) ([]*entity.Task, error) {
+	rows, err := r.QueryContext(ctx, "SELECT "+taskColumns+", deleted_at FROM tasks WHERE owner_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC, id", ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*entity.Task
+	for rows.Next() {
+		var task entity.Task
+		var deletedAt time.Time
+		err = rows.Scan(append(taskFields(&task), &deletedAt)...)
+		if err != nil {
+			return nil, err
+		}
+		task.DeletedAt = &deletedAt
+		tasks = append(tasks, &task)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = loadTaskDetails(ctx, r, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
 }
 
-func (r *Repository) DeleteTask(id int) error {
-	err := r.QueryRow("DELETE FROM tasks WHERE id = $1", id).Scan()
+// trashedSubtreeQuery is subtreeQuery for a task in the trash: the task and
+// the descendants deleted along with it, at $3.
+const trashedSubtreeQuery = "WITH RECURSIVE subtree(id) AS (" +
+	"SELECT id FROM tasks WHERE id = $1 AND owner_id = $2 " +
+	"UNION SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id WHERE t.owner_id = $2 AND t.deleted_at = $3) "
+
+func (r *Repository) RestoreTask(ctx context.Context, ownerID int, id int) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var deletedAt time.Time
+	var parentID *int
+	err = tx.QueryRowContext(ctx, "SELECT deleted_at, parent_id FROM tasks WHERE id = $1 AND owner_id = $2 AND deleted_at IS NOT NULL", id, ownerID).Scan(&deletedAt, &parentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if parentID != nil {
+		var trashed bool
+		err = tx.QueryRowContext(ctx, "SELECT deleted_at IS NOT NULL FROM tasks WHERE id = $1", *parentID).Scan(&trashed)
+		if err != nil {
+			return err
+		}
+		if trashed {
+			return service.ErrParentInTrash
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, trashedSubtreeQuery+"UPDATE tasks SET deleted_at = NULL, version = version + 1 WHERE id IN (SELECT id FROM subtree) RETURNING id", id, ownerID, deletedAt)
+	if err != nil {
+		return err
+	}
+
+	err = recordEvents(ctx, tx, ownerID, rows, entity.EventRestore, nil)
 	if err != nil {
 		return err
 	}
 
+	return tx.Commit()
+}
+
+// PurgeTasks counts the tasks first: the rows the foreign key cascades to are
+// not among those the DELETE reports.
+func (r *Repository) PurgeTasks(ctx context.Context, before time.Time) (int64, error) {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var purged int64
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM tasks WHERE deleted_at < $1", before.UTC()).Scan(&purged)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM tasks WHERE deleted_at < $1", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, tx.Commit()
+}
+
+// scanVersion reads the version a write moved a task to, the row returned by
+// its statement, into task.Version. When task.Version was set, the write
+// must have replaced that version; the caller's transaction is then rolled
+// back on ErrVersionMismatch.
+func scanVersion(row *sql.Row, task *entity.Task) error {
+	var version int
+	err := row.Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if task.Version != 0 && version != task.Version+1 {
+		return service.ErrVersionMismatch
+	}
+
+	task.Version = version
 	return nil
 }
 
-func (r *Repository) GetTaskList(offset int, completed string, pagesize int, date string) ([]*entity.Task, error) {
-	query := `SELECT id, title, description, date, completed FROM tasks`
-	var args []interface{}
-	whereClause := ""
+func (r *Repository) GetTaskList(ctx context.Context, filter entity.TaskFilter) ([]*entity.Task, error) {
+	columns := taskColumns
+	args := []interface{}{filter.OwnerID}
+	conditions := []string{"owner_id = $1", "deleted_at IS NULL"}
 
-	var count int
-	if completed != "" {
-		count++
-		whereClause = fmt.Sprintf(" WHERE completed = $%d", count)
-		args = append(args, completed)
-	} else {
-		whereClause = " WHERE (completed = 'true' OR completed = 'false')"
+	var rank string
+	if filter.Query != "" {
+		args = append(args, r.dialect.searchQuery(filter.Query))
+		var match, snippet string
+		match, rank, snippet = r.dialect.searchSQL(len(args))
+		conditions = append(conditions, match)
+		columns += ", " + snippet + ", " + rank
+	}
+
+	if filter.ProjectID != 0 {
+		args = append(args, filter.ProjectID)
+		conditions = append(conditions, fmt.Sprintf("project_id = $%d", len(args)))
+	}
+
+	if filter.ParentID != 0 {
+		args = append(args, filter.ParentID)
+		conditions = append(conditions, fmt.Sprintf("parent_id = $%d", len(args)))
 	}
 
-	if date != "" {
-		if whereClause != "" {
-			whereClause += " AND "
+	if filter.Completed != nil {
+		args = append(args, *filter.Completed)
+		conditions = append(conditions, fmt.Sprintf("completed = $%d", len(args)))
+	}
+
+	if filter.Blocked != nil {
+		condition := openBlockerCondition
+		if !*filter.Blocked {
+			condition = "NOT " + condition
 		}
-		whereClause += fmt.Sprintf("date = $%d", count+1)
-		count += 1
-		args = append(args, date)
+		conditions = append(conditions, condition)
 	}
 
-	query += whereClause + fmt.Sprintf(" ORDER BY id LIMIT $%d OFFSET $%d", count+1, count+2)
+	if filter.Overdue != nil || filter.DueWithin > 0 {
+		args = append(args, filter.Now.UTC(), filter.Now.Add(-24*time.Hour).UTC())
+		pastDue := pastDueCondition(len(args)-1, len(args))
+
+		if filter.Overdue != nil {
+			condition := "(NOT completed AND " + pastDue + ")"
+			if !*filter.Overdue {
+				condition = "NOT " + condition
+			}
+			conditions = append(conditions, condition)
+		}
+
+		if filter.DueWithin > 0 {
+			args = append(args, filter.Now.Add(filter.DueWithin).UTC())
+			conditions = append(conditions, "NOT "+pastDue, fmt.Sprintf("due_at < $%d", len(args)))
+		}
+	}
 
-	fmt.Println(args)
-	fmt.Println(query)
+	if !filter.DueFrom.IsZero() {
+		args = append(args, filter.DueFrom.UTC())
+		conditions = append(conditions, fmt.Sprintf("due_at >= $%d", len(args)))
+	}
 
-	rows, err := r.Query(query, append(args, pagesize, offset)...)
+	if !filter.DueTo.IsZero() {
+		args = append(args, filter.DueTo.UTC())
+		conditions = append(conditions, fmt.Sprintf("due_at < $%d", len(args)))
+	}
+
+	if len(filter.Tags) > 0 {
+		placeholders := make([]string, len(filter.Tags))
+		for i, tag := range filter.Tags {
+			args = append(args, tag)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+
+		subquery := "SELECT tt.task_id FROM task_tags tt JOIN tags t ON t.id = tt.tag_id WHERE t.owner_id = $1 AND t.name IN (" + strings.Join(placeholders, ", ") + ")"
+		if filter.TagMatch == entity.TagMatchAll {
+			args = append(args, len(filter.Tags))
+			subquery += fmt.Sprintf(" GROUP BY tt.task_id HAVING COUNT(*) = $%d", len(args))
+		}
+
+		conditions = append(conditions, "id IN ("+subquery+")")
+	}
+
+	keys, err := sortKeys(filter.Sort, rank)
+	if err != nil {
+		return nil, err
+	}
+
+	backward := filter.Keyset != nil && filter.Keyset.Backward
+	if filter.Keyset != nil {
+		var condition string
+		condition, args = keysetCondition(keys, filter.Keyset, args)
+		conditions = append(conditions, condition)
+	}
+
+	query := "SELECT " + columns + " FROM tasks WHERE " + strings.Join(conditions, " AND ")
+
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", orderByClause(keys, backward), len(args)-1, len(args))
+
+	rows, err := r.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -80,12 +543,240 @@ func (r *Repository) GetTaskList(offset int, completed string, pagesize int, dat
 	var tasks []*entity.Task
 	for rows.Next() {
 		var task entity.Task
-		err := rows.Scan(&task.ID, &task.Title, &task.Description, &task.Date, &task.Completed)
+		fields := taskFields(&task)
+		if filter.Query != "" {
+			fields = append(fields, &task.Snippet, &task.Rank)
+		}
+
+		err = rows.Scan(fields...)
 		if err != nil {
 			return nil, err
 		}
+		task.Snippet = highlightSnippet(task.Snippet)
 		tasks = append(tasks, &task)
 	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if backward {
+		slices.Reverse(tasks)
+	}
+
+	err = loadTaskDetails(ctx, r, tasks)
+	if err != nil {
+		return nil, err
+	}
 
 	return tasks, nil
 }
+
+// pastDueCondition matches tasks past due at the time bound as $now: timed
+// tasks due before it, and all-day tasks whose day ended by then, which is
+// when their due_at lies a day or more before, bound as $dayAgo.
+func pastDueCondition(now, dayAgo int) string {
+	return fmt.Sprintf("((all_day AND due_at <= $%d) OR (NOT all_day AND due_at < $%d))", dayAgo, now)
+}
+
+// subtreeQuery is a recursive CTE collecting the ids of a task, $1, and all
+// of its descendants. UNION rather than UNION ALL keeps it finite even if the
+// tree were ever to contain a cycle.
+const subtreeQuery = "WITH RECURSIVE subtree(id) AS (" +
+	"SELECT id FROM tasks WHERE id = $1 AND owner_id = $2 AND deleted_at IS NULL " +
+	"UNION SELECT t.id FROM tasks t JOIN subtree s ON t.parent_id = s.id WHERE t.owner_id = $2 AND t.deleted_at IS NULL) "
+
+func (r *Repository) GetSubtree(ctx context.Context, ownerID int, id int) ([]*entity.Task, error) {
+	rows, err := r.QueryContext(ctx, subtreeQuery+"SELECT "+taskColumns+" FROM tasks WHERE id IN (SELECT id FROM subtree) ORDER BY CASE WHEN id = $1 THEN 0 ELSE 1 END, id", id, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []*entity.Task
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(tasks) == 0 {
+		return nil, service.ErrNotFound
+	}
+
+	err = loadTaskDetails(ctx, r, tasks)
+	if err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (r *Repository) CountOpenSubtasks(ctx context.Context, ownerID int, id int) (int, error) {
+	var count int
+	err := r.QueryRowContext(ctx, subtreeQuery+"SELECT COUNT(*) FROM tasks WHERE id IN (SELECT id FROM subtree) AND id <> $1 AND NOT completed", id, ownerID).Scan(&count)
+	return count, err
+}
+
+func (r *Repository) SetTaskParent(ctx context.Context, ownerID int, id int, parentID *int) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := r.lockTask(ctx, tx, ownerID, id)
+	if err != nil {
+		return err
+	}
+
+	after := *before
+	after.ParentID = parentID
+	err = tx.QueryRowContext(ctx, "UPDATE tasks SET parent_id = $1, version = version + 1 WHERE id = $2 AND owner_id = $3 RETURNING version", parentID, id, ownerID).Scan(&after.Version)
+	if err != nil {
+		return err
+	}
+
+	changes, err := entity.TaskChanges(before, &after, []string{"parent_id"})
+	if err != nil {
+		return err
+	}
+
+	err = recordEvent(ctx, tx, ownerID, int64(id), entity.EventUpdate, changes)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// sortColumns maps the sort fields clients may use to the columns behind
+// them. Only names found here ever reach the ORDER BY clause.
+var sortColumns = map[string]string{
+	"id":        "id",
+	"title":     "title",
+	"due_at":    "due_at",
+	"priority":  "priority",
+	"completed": "completed",
+}
+
+// sortKey is one key of a task list ordering: a column, or the search rank.
+type sortKey struct {
+	field  string
+	column string
+	desc   bool
+}
+
+// sortKeys lists the keys ordering a task list: the search rank when ranking
+// results, then sort, always ending on id so pages stay stable when the other
+// keys tie.
+func sortKeys(sort []entity.SortField, rank string) ([]sortKey, error) {
+	var keys []sortKey
+	if rank != "" && len(sort) == 0 {
+		keys = append(keys, sortKey{field: "rank", column: rank, desc: true})
+	}
+
+	for _, field := range sort {
+		column, ok := sortColumns[field.Field]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field %q", field.Field)
+		}
+
+		keys = append(keys, sortKey{field: field.Field, column: column, desc: field.Desc})
+		if field.Field == "id" {
+			return keys, nil
+		}
+	}
+
+	return append(keys, sortKey{field: "id", column: "id"}), nil
+}
+
+// orderByClause builds an ORDER BY list from keys, reversed when reading a
+// list backwards.
+func orderByClause(keys []sortKey, backward bool) string {
+	columns := make([]string, len(keys))
+	for i, key := range keys {
+		columns[i] = key.column
+		if key.desc != backward {
+			columns[i] += " DESC"
+		}
+	}
+
+	return strings.Join(columns, ", ")
+}
+
+// keysetCondition selects the rows past keyset in the order of keys, binding
+// its values after args: (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..., with <
+// for keys read against their direction.
+func keysetCondition(keys []sortKey, keyset *entity.TaskKeyset, args []any) (string, []any) {
+	placeholders := make([]string, len(keys))
+	for i, key := range keys {
+		args = append(args, keysetValue(keyset, key.field))
+		placeholders[i] = fmt.Sprintf("$%d", len(args))
+	}
+
+	alternatives := make([]string, len(keys))
+	for i, key := range keys {
+		op := " > "
+		if key.desc != keyset.Backward {
+			op = " < "
+		}
+
+		terms := make([]string, 0, i+1)
+		for j := range keys[:i] {
+			terms = append(terms, keys[j].column+" = "+placeholders[j])
+		}
+		terms = append(terms, key.column+op+placeholders[i])
+		alternatives[i] = "(" + strings.Join(terms, " AND ") + ")"
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
+
+// keysetValue returns the value of a sort key at keyset.
+func keysetValue(keyset *entity.TaskKeyset, field string) any {
+	switch field {
+	case "rank":
+		return keyset.Rank
+	case "title":
+		return keyset.Title
+	case "due_at":
+		return keyset.DueAt.UTC()
+	case "priority":
+		return keyset.Priority
+	case "completed":
+		return keyset.Completed
+	}
+
+	return keyset.ID
+}
+
+// loadTaskDetails fills in the tags and blockers of tasks read from the
+// tasks table.
+func loadTaskDetails(ctx context.Context, q querier, tasks []*entity.Task) error {
+	err := loadTags(ctx, q, tasks)
+	if err != nil {
+		return err
+	}
+
+	return loadBlockers(ctx, q, tasks)
+}
+
+// checkAffected reports notFound when a statement matched nothing, so
+// callers can tell a missing row apart from a successful write.
+func checkAffected(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return notFound
+	}
+
+	return nil
+}
@@ -1,33 +1,74 @@
 package repository
 
 import (
+	"context"
+	"regexp"
 	"testing"
 	"time"
 	"todo-list/internal/entity"
+	"todo-list/internal/service"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 )
 
+// expectLockTask expects the read of a task about to be written, locking its
+// row.
+func expectLockTask(mock sqlmock.Sqlmock, task *entity.Task) {
+	rows := sqlmock.NewRows([]string{"id", "owner_id", "project_id", "title", "description", "due_at", "all_day", "completed", "priority", "recurrence", "parent_id", "version"})
+	if task != nil {
+		rows.AddRow(task.ID, task.OwnerID, task.ProjectID, task.Title, task.Description, task.DueAt, task.AllDay, task.Completed, task.Priority, task.Recurrence, task.ParentID, task.Version)
+	}
+
+	mock.ExpectQuery("SELECT " + regexp.QuoteMeta(taskColumns) + " FROM tasks WHERE id = \\$1 AND owner_id = \\$2 AND deleted_at IS NULL FOR UPDATE").
+		WillReturnRows(rows)
+	if task != nil {
+		mock.ExpectQuery("SELECT tt.task_id, t.name FROM task_tags").
+			WillReturnRows(sqlmock.NewRows([]string{"task_id", "name"}))
+		mock.ExpectQuery("SELECT d.task_id, d.blocker_id FROM task_dependencies").
+			WillReturnRows(sqlmock.NewRows([]string{"task_id", "blocker_id"}))
+	}
+}
+
+// expectEvent expects an entry in the history of task id, written by user 1,
+// after reading back the task at version for its snapshot.
+func expectEvent(mock sqlmock.Sqlmock, id int, operation string, version int, changes any) {
+	mock.ExpectQuery("SELECT " + regexp.QuoteMeta(taskColumns) + " FROM tasks WHERE id = \\$1$").
+		WithArgs(int64(id)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "project_id", "title", "description", "due_at", "all_day", "completed", "priority", "recurrence", "parent_id", "version"}).
+			AddRow(id, 1, nil, "Task", "", time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), false, false, 0, "", nil, version))
+	mock.ExpectQuery("SELECT tt.task_id, t.name FROM task_tags").
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "name"}))
+	mock.ExpectQuery("SELECT d.task_id, d.blocker_id FROM task_dependencies").
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "blocker_id"}))
+	mock.ExpectExec("INSERT INTO task_events\\(task_id, actor_id, operation, version, changes, snapshot, created_at\\)").
+		WithArgs(int64(id), 1, operation, version, changes, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+}
+
 func TestInsertTask(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
 	defer db.Close()
 
-	repo := &Repository{db}
+	repo := &Repository{DB: db}
 
 	task := &entity.Task{
+		OwnerID:     1,
 		Title:       "Test Task",
 		Description: "Test Description",
-		Date:        time.Now(),
+		DueAt:       time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
 		Completed:   false,
 	}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO tasks").
-		WithArgs(task.Title, task.Description, task.Date, task.Completed).
+		WithArgs(task.OwnerID, task.ProjectID, task.Title, task.Description, task.DueAt, task.AllDay, task.Completed, task.Priority, task.Recurrence, task.ParentID).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	expectEvent(mock, 1, entity.EventCreate, 1, `[{"field":"title","before":null,"after":"Test Task"},{"field":"description","before":null,"after":"Test Description"},{"field":"due_at","before":null,"after":"2024-01-01T09:00:00Z"}]`)
+	mock.ExpectCommit()
 
-	id, err := repo.InsertTask(task)
+	id, err := repo.InsertTask(context.Background(), task)
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), id)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -38,24 +79,33 @@ func TestGetTask(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	repo := &Repository{db}
+	repo := &Repository{DB: db}
 
 	task := &entity.Task{
 		ID:          1,
+		OwnerID:     1,
 		Title:       "Test Task",
 		Description: "Test Description",
-		Date:        time.Now(),
+		DueAt:       time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
 		Completed:   false,
+		Tags:        []string{"home", "urgent"},
+		BlockedBy:   []int{4},
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "title", "description", "date", "completed"}).
-		AddRow(task.ID, task.Title, task.Description, task.Date, task.Completed)
+	rows := sqlmock.NewRows([]string{"id", "owner_id", "project_id", "title", "description", "due_at", "all_day", "completed", "priority", "recurrence", "parent_id", "version"}).
+		AddRow(task.ID, task.OwnerID, nil, task.Title, task.Description, task.DueAt, task.AllDay, task.Completed, task.Priority, task.Recurrence, task.ParentID, task.Version)
 
-	mock.ExpectQuery("SELECT id, title, description, date, completed FROM tasks WHERE id = \\$1").
-		WithArgs(task.ID).
+	mock.ExpectQuery("SELECT id, owner_id, project_id, title, description, due_at, all_day, completed, priority, recurrence, parent_id, version FROM tasks WHERE id = \\$1 AND owner_id = \\$2 AND deleted_at IS NULL").
+		WithArgs(task.ID, task.OwnerID).
 		WillReturnRows(rows)
+	mock.ExpectQuery("SELECT tt.task_id, t.name FROM task_tags tt JOIN tags t ON t.id = tt.tag_id WHERE tt.task_id IN \\(\\$1\\) ORDER BY t.name").
+		WithArgs(task.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "name"}).AddRow(1, "home").AddRow(1, "urgent"))
+	mock.ExpectQuery("SELECT d.task_id, d.blocker_id FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id WHERE d.task_id IN \\(\\$1\\) AND b.deleted_at IS NULL ORDER BY d.blocker_id").
+		WithArgs(task.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "blocker_id"}).AddRow(1, 4))
 
-	result, err := repo.GetTask(task.ID)
+	result, err := repo.GetTask(context.Background(), task.OwnerID, task.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, task, result)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -66,21 +116,39 @@ func TestUpdateTask(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	repo := &Repository{db}
+	repo := &Repository{DB: db}
 
 	task := &entity.Task{
 		Title:       "Updated Task",
 		Description: "Updated Description",
-		Date:        time.Now(),
+		DueAt:       time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
 		Completed:   true,
+		Tags:        []string{"home"},
 	}
 
-	mock.ExpectQuery("UPDATE tasks SET title=\\$1, description=\\$2, date=\\$3, completed=\\$4 WHERE id = \\$5").
-		WithArgs(task.Title, task.Description, task.Date, task.Completed, 1).
-		WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectBegin()
+	expectLockTask(mock, &entity.Task{ID: 1, OwnerID: 1, Title: "Updated Task", Description: "Updated Description", DueAt: task.DueAt, Version: 3})
+	mock.ExpectQuery("UPDATE tasks SET project_id=\\$1, title=\\$2, description=\\$3, due_at=\\$4, all_day=\\$5, completed=\\$6, priority=\\$7, recurrence=\\$8, parent_id=\\$9, version = version \\+ 1 WHERE id = \\$10 AND owner_id = \\$11 AND deleted_at IS NULL RETURNING version").
+		WithArgs(task.ProjectID, task.Title, task.Description, task.DueAt.UTC(), task.AllDay, task.Completed, task.Priority, task.Recurrence, task.ParentID, 1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+	mock.ExpectExec("DELETE FROM task_tags WHERE task_id = \\$1").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("INSERT INTO tags").
+		WithArgs(1, "home").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectExec("INSERT INTO task_tags").
+		WithArgs(int64(1), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM task_dependencies WHERE task_id = \\$1 AND blocker_id IN \\(SELECT id FROM tasks WHERE deleted_at IS NULL\\)").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectEvent(mock, 1, entity.EventUpdate, 4, `[{"field":"completed","before":false,"after":true},{"field":"tags","before":null,"after":["home"]}]`)
+	mock.ExpectCommit()
 
-	err = repo.UpdateTask(1, task)
+	err = repo.UpdateTask(context.Background(), 1, 1, task)
 	assert.NoError(t, err)
+	assert.Equal(t, 4, task.Version)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -89,13 +157,20 @@ func TestDeleteTask(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	repo := &Repository{db}
+	repo := &Repository{DB: db}
 
-	mock.ExpectQuery("DELETE FROM tasks WHERE id = \\$1").
-		WithArgs(1).
-		WillReturnRows(sqlmock.NewRows([]string{}))
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH RECURSIVE subtree\\(id\\) AS \\(.*\\) UPDATE tasks SET deleted_at = \\$3, version = version \\+ 1 WHERE id IN \\(SELECT id FROM subtree\\) AND id <> \\$1 RETURNING id").
+		WithArgs(1, 1, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	expectEvent(mock, 2, entity.EventDelete, 4, "[]")
+	mock.ExpectQuery("UPDATE tasks SET deleted_at = \\$1, version = version \\+ 1 WHERE id = \\$2 AND owner_id = \\$3 AND deleted_at IS NULL RETURNING version").
+		WithArgs(sqlmock.AnyArg(), 1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	expectEvent(mock, 1, entity.EventDelete, 2, "[]")
+	mock.ExpectCommit()
 
-	err = repo.DeleteTask(1)
+	err = repo.DeleteTask(context.Background(), 1, 1, 0)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -105,35 +180,306 @@ func TestGetTaskList(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	repo := &Repository{db}
+	repo := &Repository{DB: db}
 
 	tasks := []*entity.Task{
 		{
 			ID:          1,
+			OwnerID:     1,
 			Title:       "Test Task 1",
 			Description: "Description 1",
-			Date:        time.Now(),
+			DueAt:       time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
 			Completed:   false,
 		},
 		{
 			ID:          2,
+			OwnerID:     1,
 			Title:       "Test Task 2",
 			Description: "Description 2",
-			Date:        time.Now(),
+			DueAt:       time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
 			Completed:   true,
 		},
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "title", "description", "date", "completed"}).
-		AddRow(tasks[0].ID, tasks[0].Title, tasks[0].Description, tasks[0].Date, tasks[0].Completed).
-		AddRow(tasks[1].ID, tasks[1].Title, tasks[1].Description, tasks[1].Date, tasks[1].Completed)
+	rows := sqlmock.NewRows([]string{"id", "owner_id", "project_id", "title", "description", "due_at", "all_day", "completed", "priority", "recurrence", "parent_id", "version"}).
+		AddRow(tasks[0].ID, tasks[0].OwnerID, nil, tasks[0].Title, tasks[0].Description, tasks[0].DueAt, tasks[0].AllDay, tasks[0].Completed, tasks[0].Priority, tasks[0].Recurrence, tasks[0].ParentID, tasks[0].Version).
+		AddRow(tasks[1].ID, tasks[1].OwnerID, nil, tasks[1].Title, tasks[1].Description, tasks[1].DueAt, tasks[1].AllDay, tasks[1].Completed, tasks[1].Priority, tasks[1].Recurrence, tasks[1].ParentID, tasks[1].Version)
 
-	mock.ExpectQuery("SELECT id, title, description, date, completed FROM tasks WHERE \\(completed = 'true' OR completed = 'false'\\) ORDER BY id LIMIT \\$1 OFFSET \\$2").
-		WithArgs(10, 0).
+	mock.ExpectQuery("SELECT id, owner_id, project_id, title, description, due_at, all_day, completed, priority, recurrence, parent_id, version FROM tasks WHERE owner_id = \\$1 AND deleted_at IS NULL ORDER BY id LIMIT \\$2 OFFSET \\$3").
+		WithArgs(1, 10, 0).
 		WillReturnRows(rows)
+	mock.ExpectQuery("SELECT tt.task_id, t.name FROM task_tags").
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "name"}))
+	mock.ExpectQuery("SELECT d.task_id, d.blocker_id FROM task_dependencies").
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "blocker_id"}))
 
-	result, err := repo.GetTaskList(0, "", 10, "")
+	result, err := repo.GetTaskList(context.Background(), entity.TaskFilter{OwnerID: 1, Limit: 10})
 	assert.NoError(t, err)
 	assert.Equal(t, tasks, result)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestPatchTask(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	task := &entity.Task{Title: "Patched", Completed: true, Tags: []string{"home"}}
+	project := 3
+
+	mock.ExpectBegin()
+	expectLockTask(mock, &entity.Task{ID: 1, OwnerID: 1, ProjectID: &project, Title: "Draft", Version: 1})
+	mock.ExpectQuery("^UPDATE tasks SET version = version \\+ 1, completed = \\$1, project_id = \\$2 WHERE id = \\$3 AND owner_id = \\$4 AND deleted_at IS NULL RETURNING version$").
+		WithArgs(true, nil, 1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectExec("DELETE FROM task_tags WHERE task_id = \\$1").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO tags").
+		WithArgs(1, "home").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectExec("INSERT INTO task_tags").
+		WithArgs(int64(1), int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectEvent(mock, 1, entity.EventUpdate, 2, `[{"field":"project_id","before":3,"after":null},{"field":"completed","before":false,"after":true},{"field":"tags","before":null,"after":["home"]}]`)
+	mock.ExpectCommit()
+
+	err = repo.PatchTask(context.Background(), 1, 1, task, []string{"completed", "tags", "project_id"})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPatchTask_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	mock.ExpectBegin()
+	expectLockTask(mock, nil)
+	mock.ExpectRollback()
+
+	err = repo.PatchTask(context.Background(), 2, 1, &entity.Task{}, []string{"blocked_by"})
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTask_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	task := &entity.Task{
+		Title: "Updated Task",
+		DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+	}
+
+	mock.ExpectBegin()
+	expectLockTask(mock, nil)
+	mock.ExpectRollback()
+
+	err = repo.UpdateTask(context.Background(), 2, 1, task)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTaskList_Filters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT id, owner_id, project_id, title, description, due_at, all_day, completed, priority, recurrence, parent_id, version FROM tasks WHERE owner_id = \\$1 AND deleted_at IS NULL AND completed = \\$2 AND due_at >= \\$3 AND due_at < \\$4 ORDER BY id LIMIT \\$5 OFFSET \\$6").
+		WithArgs(1, true, from, from.AddDate(0, 0, 1), 10, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "project_id", "title", "description", "due_at", "all_day", "completed", "priority", "recurrence", "parent_id", "version"}))
+
+	result, err := repo.GetTaskList(context.Background(), entity.TaskFilter{OwnerID: 1, Completed: boolPtr(true), DueFrom: from, DueTo: from.AddDate(0, 0, 1), Offset: 20, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTaskList_Overdue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	pastDue := "\\(\\(all_day AND due_at <= \\$3\\) OR \\(NOT all_day AND due_at < \\$2\\)\\)"
+	mock.ExpectQuery("SELECT .* FROM tasks WHERE owner_id = \\$1 AND deleted_at IS NULL AND NOT \\(NOT completed AND "+pastDue+"\\) AND NOT "+pastDue+" AND due_at < \\$4 ORDER BY id LIMIT \\$5 OFFSET \\$6").
+		WithArgs(1, now, now.Add(-24*time.Hour), now.Add(7*24*time.Hour), 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "project_id", "title", "description", "due_at", "all_day", "completed", "priority", "recurrence", "parent_id", "version"}))
+
+	_, err = repo.GetTaskList(context.Background(), entity.TaskFilter{OwnerID: 1, Overdue: boolPtr(false), DueWithin: 7 * 24 * time.Hour, Now: now, Limit: 10})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestGetTask_OtherOwner(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	mock.ExpectQuery("SELECT id, owner_id, project_id, title, description, due_at, all_day, completed, priority, recurrence, parent_id, version FROM tasks WHERE id = \\$1 AND owner_id = \\$2 AND deleted_at IS NULL").
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "project_id", "title", "description", "due_at", "all_day", "completed", "priority", "recurrence", "parent_id", "version"}))
+
+	result, err := repo.GetTask(context.Background(), 2, 1)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTaskList_AllTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	mock.ExpectQuery("SELECT id, owner_id, project_id, title, description, due_at, all_day, completed, priority, recurrence, parent_id, version FROM tasks WHERE owner_id = \\$1 AND deleted_at IS NULL AND id IN \\(SELECT tt.task_id FROM task_tags tt JOIN tags t ON t.id = tt.tag_id WHERE t.owner_id = \\$1 AND t.name IN \\(\\$2, \\$3\\) GROUP BY tt.task_id HAVING COUNT\\(\\*\\) = \\$4\\) ORDER BY id LIMIT \\$5 OFFSET \\$6").
+		WithArgs(1, "home", "urgent", 2, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "project_id", "title", "description", "due_at", "all_day", "completed", "priority", "recurrence", "parent_id", "version"}))
+
+	result, err := repo.GetTaskList(context.Background(), entity.TaskFilter{OwnerID: 1, Tags: []string{"home", "urgent"}, TagMatch: entity.TagMatchAll, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTaskList_Sort(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	mock.ExpectQuery("SELECT .* FROM tasks WHERE owner_id = \\$1 AND deleted_at IS NULL ORDER BY priority DESC, due_at, id LIMIT \\$2 OFFSET \\$3").
+		WithArgs(1, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "project_id", "title", "description", "due_at", "all_day", "completed", "priority", "recurrence", "parent_id", "version"}))
+
+	sort := []entity.SortField{{Field: "priority", Desc: true}, {Field: "due_at"}}
+	_, err = repo.GetTaskList(context.Background(), entity.TaskFilter{OwnerID: 1, Sort: sort, Limit: 10})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTaskList_UnknownSortField(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	sort := []entity.SortField{{Field: "title; DROP TABLE tasks"}}
+	_, err = repo.GetTaskList(context.Background(), entity.TaskFilter{OwnerID: 1, Sort: sort, Limit: 10})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTaskList_Blocked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	mock.ExpectQuery("SELECT .* FROM tasks WHERE owner_id = \\$1 AND deleted_at IS NULL AND NOT EXISTS \\(SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id WHERE d.task_id = tasks.id AND NOT b.completed AND b.deleted_at IS NULL\\) ORDER BY id LIMIT \\$2 OFFSET \\$3").
+		WithArgs(1, 10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "project_id", "title", "description", "due_at", "all_day", "completed", "priority", "recurrence", "parent_id", "version"}))
+
+	_, err = repo.GetTaskList(context.Background(), entity.TaskFilter{OwnerID: 1, Blocked: boolPtr(false), Limit: 10})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTaskList_Keyset(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	due := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "owner_id", "project_id", "title", "description", "due_at", "all_day", "completed", "priority", "recurrence", "parent_id", "version"}
+	mock.ExpectQuery("SELECT .* FROM tasks WHERE owner_id = \\$1 AND deleted_at IS NULL AND \\(\\(priority > \\$2\\) OR \\(priority = \\$2 AND due_at < \\$3\\) OR \\(priority = \\$2 AND due_at = \\$3 AND id < \\$4\\)\\) ORDER BY priority, due_at DESC, id DESC LIMIT \\$5 OFFSET \\$6").
+		WithArgs(1, entity.PriorityLow, due, 7, 2, 0).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(6, 1, nil, "Later", "", due, false, false, entity.PriorityLow, "", nil, 1).
+			AddRow(5, 1, nil, "Sooner", "", due, false, false, entity.PriorityHigh, "", nil, 1))
+	mock.ExpectQuery("SELECT tt.task_id, t.name FROM task_tags").
+		WithArgs(5, 6).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "name"}))
+	mock.ExpectQuery("SELECT d.task_id, d.blocker_id FROM task_dependencies").
+		WithArgs(5, 6).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "blocker_id"}))
+
+	sort := []entity.SortField{{Field: "priority", Desc: true}, {Field: "due_at"}}
+	keyset := &entity.TaskKeyset{ID: 7, DueAt: due, Priority: entity.PriorityLow, Backward: true}
+	result, err := repo.GetTaskList(context.Background(), entity.TaskFilter{OwnerID: 1, Sort: sort, Keyset: keyset, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, result[0].ID)
+	assert.Equal(t, 6, result[1].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTaskList_Search(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	columns := []string{"id", "owner_id", "project_id", "title", "description", "due_at", "all_day", "completed", "priority", "recurrence", "parent_id", "version", "snippet", "rank"}
+	mock.ExpectQuery("SELECT id, .*, parent_id, version, ts_headline\\('simple', title \\|\\| ' ' \\|\\| coalesce\\(description, ''\\), to_tsquery\\('simple', \\$2\\), .*\\) FROM tasks WHERE owner_id = \\$1 AND deleted_at IS NULL AND search @@ to_tsquery\\('simple', \\$2\\) ORDER BY ts_rank\\('\\{0, 0, 0.5, 1.0\\}', search, to_tsquery\\('simple', \\$2\\)\\) DESC, id LIMIT \\$3 OFFSET \\$4").
+		WithArgs(1, "buy:* & milk:*", 10, 0).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 1, nil, "Buy milk", "", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), false, false, 0, "", nil, 1, "\ue000Buy\ue001 <\ue000milk\ue001>", 0.5))
+	mock.ExpectQuery("SELECT tt.task_id, t.name FROM task_tags").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "name"}))
+	mock.ExpectQuery("SELECT d.task_id, d.blocker_id FROM task_dependencies").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"task_id", "blocker_id"}))
+
+	result, err := repo.GetTaskList(context.Background(), entity.TaskFilter{OwnerID: 1, Query: "buy milk", Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, "<b>Buy</b> &lt;<b>milk</b>&gt;", result[0].Snippet)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTask_VersionMismatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	task := &entity.Task{Title: "Updated Task", DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), Version: 2}
+
+	mock.ExpectBegin()
+	expectLockTask(mock, &entity.Task{ID: 1, OwnerID: 1, Title: "Task", DueAt: task.DueAt, Version: 4})
+	mock.ExpectQuery("UPDATE tasks SET .*version = version \\+ 1 WHERE id = \\$10 AND owner_id = \\$11 AND deleted_at IS NULL RETURNING version").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(5))
+	mock.ExpectRollback()
+
+	err = repo.UpdateTask(context.Background(), 1, 1, task)
+	assert.ErrorIs(t, err, service.ErrVersionMismatch)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
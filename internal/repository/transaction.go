@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"todo-list/internal/service"
+)
+
+// dbTx is what a write runs in: a transaction of its own, or a savepoint when
+// the Repository already runs in one.
+type dbTx interface {
+	querier
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	Commit() error
+	Rollback() error
+}
+
+// begin starts the transaction of a write. Within Transaction it takes a
+// savepoint instead, so that a failed write undoes only itself and the
+// transaction can go on.
+func (r *Repository) begin(ctx context.Context) (dbTx, error) {
+	if r.tx == nil {
+		tx, err := r.DB.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return tx, nil
+	}
+
+	_, err := r.tx.ExecContext(ctx, "SAVEPOINT task_write")
+	if err != nil {
+		return nil, err
+	}
+
+	return &savepoint{dbTx: r.tx, ctx: ctx}, nil
+}
+
+// savepoint ends like a transaction: Commit releases it and Rollback undoes
+// the writes made since it was taken. Both are no-ops once either ran.
+type savepoint struct {
+	dbTx
+	ctx  context.Context
+	done bool
+}
+
+func (s *savepoint) Commit() error {
+	if s.done {
+		return sql.ErrTxDone
+	}
+	s.done = true
+
+	_, err := s.ExecContext(s.ctx, "RELEASE SAVEPOINT task_write")
+	return err
+}
+
+func (s *savepoint) Rollback() error {
+	if s.done {
+		return sql.ErrTxDone
+	}
+	s.done = true
+
+	_, err := s.ExecContext(s.ctx, "ROLLBACK TO SAVEPOINT task_write")
+	if err != nil {
+		return err
+	}
+
+	_, err = s.ExecContext(s.ctx, "RELEASE SAVEPOINT task_write")
+	return err
+}
+
+// Transaction runs fn with a Repository whose reads and writes all go through
+// one transaction, committed when fn returns nil and rolled back otherwise.
+func (r *Repository) Transaction(ctx context.Context, fn func(repo service.Repository) error) error {
+	tx, err := r.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(&Repository{DB: r.DB, dialect: r.dialect, tx: tx})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// QueryContext runs a query on the database, or on the transaction r runs in
+// so that it sees what was written there. QueryRowContext and ExecContext do
+// the same.
+func (r *Repository) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if r.tx != nil {
+		return r.tx.QueryContext(ctx, query, args...)
+	}
+
+	return r.DB.QueryContext(ctx, query, args...)
+}
+
+func (r *Repository) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if r.tx != nil {
+		return r.tx.QueryRowContext(ctx, query, args...)
+	}
+
+	return r.DB.QueryRowContext(ctx, query, args...)
+}
+
+func (r *Repository) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if r.tx != nil {
+		return r.tx.ExecContext(ctx, query, args...)
+	}
+
+	return r.DB.ExecContext(ctx, query, args...)
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+)
+
+func (r *Repository) InsertUser(ctx context.Context, user *entity.User) (int64, error) {
+	var id int64
+	err := r.QueryRowContext(ctx, "INSERT INTO users(username, password_hash, created_at, time_zone) VALUES ($1, $2, $3, $4) RETURNING id", user.Username, user.PasswordHash, user.CreatedAt, user.TimeZone).Scan(&id)
+	if r.dialect.isUniqueViolation(err) {
+		return -1, service.ErrUserExists
+	}
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+const userColumns = "id, username, password_hash, created_at, time_zone"
+
+func scanUser(row scanner) (*entity.User, error) {
+	var user entity.User
+	err := row.Scan(&user.ID, &user.Username, &user.PasswordHash, &user.CreatedAt, &user.TimeZone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, service.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *Repository) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return scanUser(r.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE username = $1", username))
+}
+
+func (r *Repository) GetUser(ctx context.Context, id int) (*entity.User, error) {
+	return scanUser(r.QueryRowContext(ctx, "SELECT "+userColumns+" FROM users WHERE id = $1", id))
+}
+
+func (r *Repository) UpdateUserTimeZone(ctx context.Context, id int, timeZone string) error {
+	res, err := r.ExecContext(ctx, "UPDATE users SET time_zone=$1 WHERE id = $2", timeZone, id)
+	if err != nil {
+		return err
+	}
+
+	return checkAffected(res, service.ErrNotFound)
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-list/internal/entity"
+	"todo-list/internal/service"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	user := &entity.User{Username: "john", PasswordHash: "hash", CreatedAt: time.Now(), TimeZone: "UTC"}
+
+	mock.ExpectQuery("INSERT INTO users").
+		WithArgs(user.Username, user.PasswordHash, user.CreatedAt, user.TimeZone).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	id, err := repo.InsertUser(context.Background(), user)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertUser_Exists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	user := &entity.User{Username: "john", PasswordHash: "hash", CreatedAt: time.Now(), TimeZone: "UTC"}
+
+	mock.ExpectQuery("INSERT INTO users").
+		WithArgs(user.Username, user.PasswordHash, user.CreatedAt, user.TimeZone).
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	_, err = repo.InsertUser(context.Background(), user)
+	assert.ErrorIs(t, err, service.ErrUserExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserByUsername(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	user := &entity.User{ID: 1, Username: "john", PasswordHash: "hash", CreatedAt: time.Now(), TimeZone: "UTC"}
+
+	mock.ExpectQuery("SELECT id, username, password_hash, created_at, time_zone FROM users WHERE username = \\$1").
+		WithArgs("john").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password_hash", "created_at", "time_zone"}).
+			AddRow(user.ID, user.Username, user.PasswordHash, user.CreatedAt, user.TimeZone))
+
+	result, err := repo.GetUserByUsername(context.Background(), "john")
+	assert.NoError(t, err)
+	assert.Equal(t, user, result)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateUserTimeZone(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := &Repository{DB: db}
+
+	mock.ExpectExec("UPDATE users SET time_zone=\\$1 WHERE id = \\$2").
+		WithArgs("Europe/Berlin", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = repo.UpdateUserTimeZone(context.Background(), 1, "Europe/Berlin")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
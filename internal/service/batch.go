@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"todo-list/internal/entity"
+)
+
+// maxBatchSize is the most operations a batch may carry.
+const maxBatchSize = 1000
+
+// ErrBatchAborted marks the operations of an all-or-nothing batch that
+// succeeded but were rolled back because another one failed.
+var ErrBatchAborted = errors.New("rolled back because another operation of the batch failed")
+
+// errBatchFailed rolls back the transaction of an all-or-nothing batch.
+var errBatchFailed = errors.New("batch operation failed")
+
+// RunBatch applies a list of task writes in one transaction, each validated
+// as it would be on its own and seeing the writes before it. Every operation
+// is attempted so that all failures are reported at once. Unless bestEffort
+// is set, a single failure rolls the whole batch back; otherwise the writes
+// that succeeded are committed.
+func (s *Service) RunBatch(ctx context.Context, ops []entity.BatchOperation, bestEffort bool) (*entity.TaskBatch, error) {
+	_, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("%w: a batch needs at least one operation", ErrInvalidData)
+	}
+	if len(ops) > maxBatchSize {
+		return nil, fmt.Errorf("%w: a batch takes at most %d operations", ErrInvalidData, maxBatchSize)
+	}
+
+	batch := &entity.TaskBatch{Committed: true}
+	err = s.TaskRepository.Transaction(ctx, func(repo Repository) error {
+		tx := s.withRepository(repo)
+		batch.Results = make([]*entity.BatchResult, 0, len(ops))
+		failed := false
+		for _, op := range ops {
+			result := tx.runBatchOperation(ctx, op)
+			batch.Results = append(batch.Results, result)
+			failed = failed || result.Err != nil
+
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		if failed && !bestEffort {
+			return errBatchFailed
+		}
+
+		return nil
+	})
+	if errors.Is(err, errBatchFailed) {
+		batch.Committed = false
+		for _, result := range batch.Results {
+			if result.Err != nil {
+				continue
+			}
+
+			result.Err = ErrBatchAborted
+			result.Version = 0
+			if result.Op == entity.BatchCreate {
+				result.ID = 0
+			}
+		}
+
+		return batch, nil
+	}
+	if err != nil {
+		return nil, checkTimeout(ctx, err)
+	}
+
+	return batch, nil
+}
+
+// runBatchOperation applies one operation of a batch through CreateTask,
+// UpdateTask or DeleteTask.
+func (s *Service) runBatchOperation(ctx context.Context, op entity.BatchOperation) *entity.BatchResult {
+	result := &entity.BatchResult{Op: op.Op, ID: op.ID}
+	if op.Task == nil && (op.Op == entity.BatchCreate || op.Op == entity.BatchUpdate) {
+		result.Err = fmt.Errorf("%w: %s needs a task", ErrInvalidData, op.Op)
+		return result
+	}
+
+	switch op.Op {
+	case entity.BatchCreate:
+		task := *op.Task
+		id, err := s.CreateTask(ctx, &task)
+		if err != nil {
+			result.Err = err
+			break
+		}
+		result.ID, result.Version = int(id), 1
+	case entity.BatchUpdate:
+		task := *op.Task
+		task.Version = op.Version
+		err := s.UpdateTask(ctx, op.ID, &task, op.Force)
+		if err != nil {
+			result.Err = err
+			break
+		}
+		result.Version = task.Version
+	case entity.BatchDelete:
+		result.Err = s.DeleteTask(ctx, op.ID, op.Version)
+	default:
+		result.Err = fmt.Errorf("%w: unknown operation %q", ErrInvalidData, op.Op)
+	}
+
+	return result
+}
+
+// withRepository returns a copy of s working on repo, such as the one
+// TaskRepository.Transaction hands out.
+func (s *Service) withRepository(repo Repository) *Service {
+	tx := *s
+	tx.TaskRepository = repo
+	tx.UserRepository = repo
+	tx.ProjectRepository = repo
+	tx.TagRepository = repo
+
+	return &tx
+}
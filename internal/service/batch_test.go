@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func batchOperations() []entity.BatchOperation {
+	return []entity.BatchOperation{
+		{Op: entity.BatchCreate, Task: &entity.Task{Title: "New", DueAt: time.Now()}},
+		{Op: entity.BatchDelete, ID: 3},
+		{Op: "move", ID: 4},
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	mockRepo.On("Transaction", mock.Anything).Return()
+	mockRepo.On("InsertTask", mock.Anything, mock.Anything).Return(int64(7), nil)
+	mockRepo.On("DeleteTask", mock.Anything, 1, 3, 0).Return(ErrNotFound)
+
+	batch, err := service.RunBatch(testUserContext, batchOperations(), true)
+	require.NoError(t, err)
+	assert.True(t, batch.Committed)
+	require.Len(t, batch.Results, 3)
+	assert.NoError(t, batch.Results[0].Err)
+	assert.Equal(t, 7, batch.Results[0].ID)
+	assert.Equal(t, 1, batch.Results[0].Version)
+	assert.ErrorIs(t, batch.Results[1].Err, ErrNotFound)
+	assert.ErrorIs(t, batch.Results[2].Err, ErrInvalidData)
+
+	batch, err = service.RunBatch(testUserContext, batchOperations(), false)
+	require.NoError(t, err)
+	assert.False(t, batch.Committed)
+	assert.ErrorIs(t, batch.Results[0].Err, ErrBatchAborted)
+	assert.Zero(t, batch.Results[0].ID)
+	assert.ErrorIs(t, batch.Results[1].Err, ErrNotFound)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRunBatch_Invalid(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	_, err := service.RunBatch(testUserContext, nil, false)
+	assert.ErrorIs(t, err, ErrInvalidData)
+
+	_, err = service.RunBatch(testUserContext, make([]entity.BatchOperation, maxBatchSize+1), false)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	mockRepo.AssertNotCalled(t, "Transaction", mock.Anything)
+}
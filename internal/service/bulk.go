@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"todo-list/internal/entity"
+)
+
+// maxBulkSize is the most tasks a bulk update or delete may touch. A filter
+// matching more fails as a whole, and is to be narrowed.
+const maxBulkSize = 1000
+
+// errDryRun rolls back the transaction of a dry run once it went through.
+var errDryRun = errors.New("dry run")
+
+// UpdateTasks applies a JSON Merge Patch to every task matching filter, as
+// PatchTask would to each, in one transaction: if any task fails the patch,
+// none is changed and the error names that task. A dry run makes the same
+// writes and rolls them back, so it fails exactly when the real call would.
+func (s *Service) UpdateTasks(ctx context.Context, filter entity.TaskFilter, patch entity.TaskPatch, force bool, dryRun bool) (*entity.BulkResult, error) {
+	if len(patch) == 0 {
+		return nil, ErrInvalidData
+	}
+
+	return s.bulkWrite(ctx, filter, dryRun, nil, func(tx *Service, task *entity.Task) error {
+		_, err := tx.PatchTask(ctx, task.ID, patch, task.Version, force)
+		return err
+	})
+}
+
+// DeleteTasks moves every task matching filter to the trash in one
+// transaction, with their subtasks as DeleteTask does. Matching tasks below
+// another matching task go with it and are not reported on their own. A dry
+// run makes the same writes and rolls them back.
+func (s *Service) DeleteTasks(ctx context.Context, filter entity.TaskFilter, dryRun bool) (*entity.BulkResult, error) {
+	outermost := func(tx *Service, tasks []*entity.Task) ([]*entity.Task, error) {
+		return tx.outermost(ctx, tasks)
+	}
+
+	return s.bulkWrite(ctx, filter, dryRun, outermost, func(tx *Service, task *entity.Task) error {
+		return tx.DeleteTask(ctx, task.ID, AnyVersion)
+	})
+}
+
+// outermost leaves out the tasks with an ancestor among tasks, whose subtree
+// they are part of.
+func (s *Service) outermost(ctx context.Context, tasks []*entity.Task) ([]*entity.Task, error) {
+	matched := make(map[int]bool, len(tasks))
+	for _, task := range tasks {
+		matched[task.ID] = true
+	}
+
+	var kept []*entity.Task
+	for _, task := range tasks {
+		parentID := task.ParentID
+		for parentID != nil && !matched[*parentID] {
+			parent, err := s.TaskRepository.GetTask(ctx, task.OwnerID, *parentID)
+			if err != nil {
+				return nil, err
+			}
+			parentID = parent.ParentID
+		}
+
+		if parentID == nil {
+			kept = append(kept, task)
+		}
+	}
+
+	return kept, nil
+}
+
+// bulkWrite runs write on every task matching filter within a transaction,
+// or on those narrow keeps of them when it is not nil. Paging parameters of
+// the filter are ignored, and at least one filter is required so that a
+// forgotten one does not touch every task.
+func (s *Service) bulkWrite(ctx context.Context, filter entity.TaskFilter, dryRun bool, narrow func(tx *Service, tasks []*entity.Task) ([]*entity.Task, error), write func(tx *Service, task *entity.Task) error) (*entity.BulkResult, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if unfiltered(filter) {
+		return nil, fmt.Errorf("%w: a bulk write needs at least one filter", ErrInvalidData)
+	}
+
+	err = s.resolveFilter(ctx, user, &filter)
+	if err != nil {
+		return nil, err
+	}
+	filter.Cursor, filter.Keyset, filter.Offset = "", nil, 0
+	filter.Limit = maxBulkSize + 1
+
+	result := &entity.BulkResult{IDs: []int{}, DryRun: dryRun}
+	err = s.TaskRepository.Transaction(ctx, func(repo Repository) error {
+		tasks, err := repo.GetTaskList(ctx, filter)
+		if err != nil {
+			return err
+		}
+		if len(tasks) > maxBulkSize {
+			return fmt.Errorf("%w: the filter matches more than %d tasks", ErrInvalidData, maxBulkSize)
+		}
+
+		tx := s.withRepository(repo)
+		if narrow != nil {
+			tasks, err = narrow(tx, tasks)
+			if err != nil {
+				return err
+			}
+		}
+		for _, task := range tasks {
+			err = write(tx, task)
+			if err != nil {
+				return fmt.Errorf("task %d: %w", task.ID, err)
+			}
+			result.IDs = append(result.IDs, task.ID)
+		}
+		result.Count = len(result.IDs)
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, checkTimeout(ctx, err)
+	}
+
+	return result, nil
+}
+
+// unfiltered reports whether filter selects every task.
+func unfiltered(filter entity.TaskFilter) bool {
+	return filter.Query == "" && filter.ProjectID == 0 && filter.ParentID == 0 &&
+		filter.Completed == nil && filter.Blocked == nil && filter.Overdue == nil && filter.DueWithin == 0 &&
+		filter.Date == "" && filter.From == "" && filter.To == "" && len(filter.Tags) == 0
+}
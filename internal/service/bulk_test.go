@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateTasks(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	tasks := []*entity.Task{
+		{ID: 2, OwnerID: 1, Title: "Two", DueAt: due, Version: 3},
+		{ID: 5, OwnerID: 1, Title: "Five", DueAt: due, Version: 1},
+	}
+	mockRepo.On("Transaction", mock.Anything).Return()
+	mockRepo.On("GetTaskList", mock.Anything, mock.MatchedBy(func(filter entity.TaskFilter) bool {
+		return filter.OwnerID == 1 && filter.Tags[0] == "home" && filter.Limit == maxBulkSize+1
+	})).Return(tasks, nil)
+	for _, task := range tasks {
+		mockRepo.On("GetTask", mock.Anything, 1, task.ID).Return(task, nil)
+		mockRepo.On("PatchTask", mock.Anything, 1, task.ID, mock.Anything, []string{"priority"}).Return(nil)
+	}
+
+	filter := entity.TaskFilter{Tags: []string{"home"}, Limit: 10}
+	result, err := service.UpdateTasks(testUserContext, filter, mergePatch(t, `{"priority": "high"}`), false, true)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.BulkResult{Count: 2, IDs: []int{2, 5}, DryRun: true}, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteTasks(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	completed := true
+	parent, middle := 2, 5
+	// 3 is a subtask of 2, and 4 one of 5, itself a subtask of 2 that does
+	// not match: both go to the trash with 2.
+	tasks := []*entity.Task{{ID: 2, OwnerID: 1}, {ID: 3, OwnerID: 1, ParentID: &parent}, {ID: 4, OwnerID: 1, ParentID: &middle}, {ID: 6, OwnerID: 1}}
+	mockRepo.On("Transaction", mock.Anything).Return()
+	mockRepo.On("GetTaskList", mock.Anything, mock.Anything).Return(tasks, nil).Once()
+	mockRepo.On("GetTask", mock.Anything, 1, 5).Return(&entity.Task{ID: 5, OwnerID: 1, ParentID: &parent}, nil)
+	mockRepo.On("DeleteTask", mock.Anything, 1, 2, 0).Return(nil)
+	mockRepo.On("DeleteTask", mock.Anything, 1, 6, 0).Return(nil)
+
+	result, err := service.DeleteTasks(testUserContext, entity.TaskFilter{Completed: &completed}, false)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.BulkResult{Count: 2, IDs: []int{2, 6}}, result)
+	mockRepo.AssertNotCalled(t, "DeleteTask", mock.Anything, 1, 3, 0)
+	mockRepo.AssertNotCalled(t, "DeleteTask", mock.Anything, 1, 4, 0)
+
+	mockRepo.On("GetTaskList", mock.Anything, mock.Anything).Return(make([]*entity.Task, maxBulkSize+1), nil).Once()
+	_, err = service.DeleteTasks(testUserContext, entity.TaskFilter{Completed: &completed}, false)
+	assert.ErrorIs(t, err, ErrInvalidData)
+
+	_, err = service.DeleteTasks(testUserContext, entity.TaskFilter{Limit: 10}, true)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	mockRepo.AssertExpectations(t)
+}
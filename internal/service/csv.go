@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list/internal/entity"
+)
+
+// maxImportRows is the most rows a CSV import may carry.
+const maxImportRows = 10000
+
+// exportColumns are the columns of an exported file, in order. Lists such as
+// tags are written comma-separated within their cell.
+var exportColumns = []string{"id", "title", "description", "due_at", "all_day", "completed", "priority", "project_id", "parent_id", "tags", "blocked_by", "recurrence", "version"}
+
+// importFields are the task fields an imported column can fill.
+var importFields = []string{"title", "description", "due_at", "all_day", "completed", "priority", "project_id", "parent_id", "tags", "blocked_by", "recurrence"}
+
+// dayLayout is how the due day of an all-day task is written.
+const dayLayout = "2006-01-02"
+
+// formulaStart holds the characters that make a spreadsheet read a cell
+// starting with one as a formula.
+const formulaStart = "=+-@"
+
+// ExportTasks writes every task matching filter to w as CSV, a header first,
+// in list order and a page at a time. Paging fields of filter are ignored.
+// Nothing is written when the filter is invalid. All-day tasks are due on a
+// day of the caller's time zone, written as YYYY-MM-DD, so that importing the
+// file again keeps it.
+func (s *Service) ExportTasks(ctx context.Context, filter entity.TaskFilter, w io.Writer) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	loc, err := s.location(ctx, user)
+	if err != nil {
+		return checkTimeout(ctx, err)
+	}
+
+	filter.Cursor, filter.Offset = "", 0
+	filter.Limit = maxPageSize
+	out := csv.NewWriter(w)
+	for first := true; ; first = false {
+		page, err := s.GetTaskList(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		if first {
+			err = out.Write(exportColumns)
+			if err != nil {
+				return err
+			}
+		}
+		for _, task := range page.Tasks {
+			err = out.Write(taskRecord(task, loc))
+			if err != nil {
+				return err
+			}
+		}
+
+		out.Flush()
+		if err := out.Error(); err != nil {
+			return err
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+func taskRecord(task *entity.Task, loc *time.Location) []string {
+	due := task.DueAt.Format(time.RFC3339)
+	if task.AllDay {
+		due = task.DueAt.In(loc).Format(dayLayout)
+	}
+
+	blockers := make([]string, 0, len(task.BlockedBy))
+	for _, id := range task.BlockedBy {
+		blockers = append(blockers, strconv.Itoa(id))
+	}
+
+	return []string{
+		strconv.Itoa(task.ID),
+		escapeCell(task.Title),
+		escapeCell(task.Description),
+		due,
+		strconv.FormatBool(task.AllDay),
+		strconv.FormatBool(task.Completed),
+		task.Priority.String(),
+		optionalID(task.ProjectID),
+		optionalID(task.ParentID),
+		escapeCell(strings.Join(task.Tags, ",")),
+		strings.Join(blockers, ","),
+		task.Recurrence,
+		strconv.Itoa(task.Version),
+	}
+}
+
+// escapeCell prefixes text a spreadsheet would run as a formula with a quote,
+// which makes it plain text. Text that already starts with quotes before a
+// formula character gets one more, so that unescapeCell restores any text.
+func escapeCell(value string) string {
+	rest := strings.TrimLeft(value, "'")
+	if rest != "" && strings.ContainsRune(formulaStart, rune(rest[0])) {
+		return "'" + value
+	}
+
+	return value
+}
+
+// unescapeCell undoes escapeCell.
+func unescapeCell(value string) string {
+	rest := strings.TrimLeft(value, "'")
+	if len(rest) < len(value) && rest != "" && strings.ContainsRune(formulaStart, rune(rest[0])) {
+		return value[1:]
+	}
+
+	return value
+}
+
+func optionalID(id *int) string {
+	if id == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*id)
+}
+
+// ImportTasks creates a task from every row of a CSV file whose first line
+// names its columns. mapping maps column names to task fields; without one,
+// columns named after a task field fill it, so an exported file imports as
+// copies of its tasks. Every row is validated as CreateTask validates a task,
+// and the rows that fail are reported rather than failing the import; any
+// other error fails the whole import and creates nothing. A dry run creates
+// the tasks and rolls them back, so it rejects the same rows.
+func (s *Service) ImportTasks(ctx context.Context, file io.Reader, mapping map[string]string, dryRun bool) (*entity.ImportResult, error) {
+	_, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalidData)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+	// Spreadsheets may start the file with a byte order mark.
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+
+	columns, err := importColumns(header, mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	type row struct {
+		line   int
+		record []string
+	}
+	var rows []row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("%w: a file takes at most %d rows", ErrInvalidData, maxImportRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, row{line, record})
+	}
+
+	result := &entity.ImportResult{IDs: []int{}, Rejected: []entity.RejectedRow{}, DryRun: dryRun}
+	err = s.TaskRepository.Transaction(ctx, func(repo Repository) error {
+		tx := s.withRepository(repo)
+		for _, row := range rows {
+			id, err := tx.importRow(ctx, columns, len(header), row.record)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if errors.Is(err, ErrInvalidData) {
+				reason := strings.TrimPrefix(err.Error(), ErrInvalidData.Error()+": ")
+				result.Rejected = append(result.Rejected, entity.RejectedRow{Line: row.line, Reason: reason})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			result.Imported++
+			result.IDs = append(result.IDs, int(id))
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, checkTimeout(ctx, err)
+	}
+	if dryRun {
+		result.IDs = []int{}
+	}
+
+	return result, nil
+}
+
+// importColumns returns the task field each column of header fills, or ""
+// for the columns left out.
+func importColumns(header []string, mapping map[string]string) ([]string, error) {
+	columns := make([]string, len(header))
+	filled := make(map[string]bool)
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		field := strings.ToLower(name)
+		if mapping != nil {
+			field = mapping[name]
+		}
+		if !slices.Contains(importFields, field) {
+			if mapping != nil && field != "" {
+				return nil, fmt.Errorf("%w: column %q maps to unknown field %q", ErrInvalidData, name, field)
+			}
+			continue
+		}
+		if filled[field] {
+			return nil, fmt.Errorf("%w: more than one column fills %s", ErrInvalidData, field)
+		}
+
+		columns[i] = field
+		filled[field] = true
+	}
+
+	for name := range mapping {
+		if !slices.ContainsFunc(header, func(column string) bool { return strings.TrimSpace(column) == name }) {
+			return nil, fmt.Errorf("%w: the file has no column %q", ErrInvalidData, name)
+		}
+	}
+	for _, field := range []string{"title", "due_at"} {
+		if !filled[field] {
+			return nil, fmt.Errorf("%w: no column fills %s", ErrInvalidData, field)
+		}
+	}
+
+	return columns, nil
+}
+
+// importRow creates the task of one row through CreateTask. Empty cells
+// leave their field unset, and text cells escaped against formulas are read
+// back as they were before export. Errors in the row's data wrap ErrInvalidData.
+func (s *Service) importRow(ctx context.Context, columns []string, width int, record []string) (int64, error) {
+	if len(record) != width {
+		return -1, fmt.Errorf("%w: the row has %d columns, the header %d", ErrInvalidData, len(record), width)
+	}
+
+	var task entity.Task
+	day, allDaySet := false, false
+	for i, field := range columns {
+		value := strings.TrimSpace(record[i])
+		if field == "" || value == "" {
+			continue
+		}
+
+		var err error
+		switch field {
+		case "title":
+			task.Title = unescapeCell(value)
+		case "description":
+			task.Description = unescapeCell(value)
+		case "due_at":
+			task.DueAt, day, err = parseDue(value)
+		case "all_day":
+			task.AllDay, err = parseBool(value)
+			allDaySet = true
+		case "completed":
+			task.Completed, err = parseBool(value)
+		case "priority":
+			err = task.Priority.UnmarshalText([]byte(strings.ToLower(value)))
+		case "project_id":
+			task.ProjectID, err = parseID(value)
+		case "parent_id":
+			task.ParentID, err = parseID(value)
+		case "tags":
+			task.Tags = strings.Split(unescapeCell(value), ",")
+		case "blocked_by":
+			task.BlockedBy, err = parseIDs(value)
+		case "recurrence":
+			task.Recurrence = value
+		}
+		if err != nil {
+			return -1, fmt.Errorf("%w: %s: %v", ErrInvalidData, field, err)
+		}
+	}
+
+	if task.Title == "" {
+		return -1, fmt.Errorf("%w: title is required", ErrInvalidData)
+	}
+	if task.DueAt.IsZero() {
+		return -1, fmt.Errorf("%w: due_at is required", ErrInvalidData)
+	}
+	// A bare day makes an all-day task unless the row says otherwise.
+	if day && !allDaySet {
+		task.AllDay = true
+	}
+
+	return s.CreateTask(ctx, &task)
+}
+
+// parseDue reads a due time in RFC 3339, or a bare day as YYYY-MM-DD, which
+// day reports.
+func parseDue(value string) (due time.Time, day bool, err error) {
+	due, err = time.Parse(time.RFC3339, value)
+	if err == nil {
+		return due, false, nil
+	}
+
+	due, err = time.Parse(dayLayout, value)
+	if err == nil {
+		return due, true, nil
+	}
+
+	return time.Time{}, false, fmt.Errorf("invalid time %q: use RFC 3339 or YYYY-MM-DD", value)
+}
+
+func parseBool(value string) (bool, error) {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %q: use true or false", value)
+	}
+
+	return b, nil
+}
+
+func parseID(value string) (*int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("invalid id %q", value)
+	}
+
+	return &id, nil
+}
+
+// parseIDs reads a comma-separated list of task ids.
+func parseIDs(value string) ([]int, error) {
+	var ids []int
+	for _, part := range strings.Split(value, ",") {
+		id, err := parseID(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, *id)
+	}
+
+	return ids, nil
+}
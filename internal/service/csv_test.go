@@ -0,0 +1,162 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportTasks(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	project := 4
+	tasks := []*entity.Task{
+		{ID: 2, OwnerID: 1, Title: "Report", Description: "Q1, draft", DueAt: time.Date(2024, 3, 5, 9, 30, 0, 0, time.UTC), Priority: entity.PriorityHigh, ProjectID: &project, Tags: []string{"work", "urgent"}, BlockedBy: []int{1, 3}, Version: 2},
+		{ID: 5, OwnerID: 1, Title: "Holiday", DueAt: time.Date(2024, 3, 5, 0, 0, 0, 0, tokyo), AllDay: true, Completed: true, Version: 1},
+		{ID: 6, OwnerID: 1, Title: "=1+1", Description: "'-5", DueAt: time.Date(2024, 3, 5, 9, 30, 0, 0, time.UTC), Tags: []string{"-home", "work"}, Version: 1},
+	}
+	mockRepo.On("GetTaskList", mock.Anything, mock.MatchedBy(func(filter entity.TaskFilter) bool {
+		return filter.OwnerID == 1 && filter.Offset == 0 && filter.Tags[0] == "work"
+	})).Return(tasks, nil)
+
+	var out bytes.Buffer
+	ctx := WithLocation(testUserContext, tokyo)
+	err := service.ExportTasks(ctx, entity.TaskFilter{Tags: []string{"work"}, Limit: 10, Offset: 20}, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "id,title,description,due_at,all_day,completed,priority,project_id,parent_id,tags,blocked_by,recurrence,version\n"+
+		"2,Report,\"Q1, draft\",2024-03-05T09:30:00Z,false,false,high,4,,\"work,urgent\",\"1,3\",,2\n"+
+		"5,Holiday,,2024-03-05,true,true,none,,,,,,1\n"+
+		"6,'=1+1,''-5,2024-03-05T09:30:00Z,false,false,none,,,\"'-home,work\",,,1\n", out.String())
+
+	out.Reset()
+	err = service.ExportTasks(ctx, entity.TaskFilter{ProjectID: -1}, &out)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	assert.Empty(t, out.String())
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportTasks(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	mockRepo.On("Transaction", mock.Anything).Return()
+	mockRepo.On("InsertTask", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+		return task.Title == "Report" && task.Priority == entity.PriorityHigh && !task.AllDay &&
+			task.DueAt.Equal(time.Date(2024, 3, 5, 9, 30, 0, 0, time.UTC)) && slices.Equal(task.Tags, []string{"urgent", "work"})
+	})).Return(int64(7), nil)
+	mockRepo.On("InsertTask", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+		return task.Title == "Holiday" && task.AllDay
+	})).Return(int64(8), nil)
+
+	file := "\ufeffName,Deadline,Importance,Labels,Notes\n" +
+		"Report,2024-03-05T09:30:00Z,High,\"work,urgent\",ignored\n" +
+		",2024-03-06,,,\n" +
+		"Holiday,2024-03-08,,,\n" +
+		"Late,next week,,,\n" +
+		"Short,2024-03-09\n"
+	mapping := map[string]string{"Name": "title", "Deadline": "due_at", "Importance": "priority", "Labels": "tags"}
+	ctx := WithLocation(testUserContext, time.UTC)
+	result, err := service.ImportTasks(ctx, strings.NewReader(file), mapping, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Imported)
+	assert.Equal(t, []int{7, 8}, result.IDs)
+	assert.Equal(t, []entity.RejectedRow{
+		{Line: 3, Reason: "title is required"},
+		{Line: 5, Reason: `due_at: invalid time "next week": use RFC 3339 or YYYY-MM-DD`},
+		{Line: 6, Reason: "the row has 2 columns, the header 5"},
+	}, result.Rejected)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportTasks_Formulas(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	mockRepo.On("Transaction", mock.Anything).Return()
+	mockRepo.On("InsertTask", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+		return task.Title == "=1+1" && task.Description == "'-5" && slices.Equal(task.Tags, []string{"-home", "work"})
+	})).Return(int64(7), nil)
+
+	file := "title,description,due_at,tags\n'=1+1,''-5,2024-03-05T09:30:00Z,\"'-home,work\"\n"
+	result, err := service.ImportTasks(WithLocation(testUserContext, time.UTC), strings.NewReader(file), nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Imported)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEscapeCell(t *testing.T) {
+	for _, value := range []string{"", "Report", "'quoted'", "=1+1", "+1", "-5", "@home", "'=1+1", "''@x", "'"} {
+		assert.Equal(t, value, unescapeCell(escapeCell(value)), value)
+	}
+	assert.Equal(t, "'@home", escapeCell("@home"))
+	assert.Equal(t, "'quoted'", escapeCell("'quoted'"))
+	assert.Equal(t, "'quoted'", unescapeCell("'quoted'"))
+}
+
+func TestImportTasks_DryRun(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	mockRepo.On("Transaction", mock.Anything).Return()
+	mockRepo.On("InsertTask", mock.Anything, mock.Anything).Return(int64(7), nil)
+
+	file := "title,due_at,priority\nReport,2024-03-05T09:30:00Z,critical\nHoliday,2024-03-08,low\n"
+	result, err := service.ImportTasks(WithLocation(testUserContext, time.UTC), strings.NewReader(file), nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.ImportResult{
+		Imported: 1,
+		IDs:      []int{},
+		Rejected: []entity.RejectedRow{{Line: 2, Reason: `priority: invalid priority "critical"`}},
+		DryRun:   true,
+	}, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportTasks_Fails(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	failure := errors.New("connection reset")
+	mockRepo.On("Transaction", mock.Anything).Return()
+	mockRepo.On("InsertTask", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool { return task.Title == "Report" })).Return(int64(7), nil)
+	mockRepo.On("InsertTask", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool { return task.Title == "Holiday" })).Return(int64(-1), failure)
+
+	file := "title,due_at\nReport,2024-03-05T09:30:00Z\nHoliday,2024-03-08\n"
+	result, err := service.ImportTasks(WithLocation(testUserContext, time.UTC), strings.NewReader(file), nil, false)
+	assert.ErrorIs(t, err, failure)
+	assert.Nil(t, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestImportTasks_Columns(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	for _, tc := range []struct {
+		file    string
+		mapping map[string]string
+		message string
+	}{
+		{"", nil, "the file is empty"},
+		{"title,notes\n", nil, "no column fills due_at"},
+		{"Name,When\n", map[string]string{"Name": "title", "When": "due"}, `column "When" maps to unknown field "due"`},
+		{"Name,When\n", map[string]string{"Name": "title", "When": "due_at", "Tags": "tags"}, `the file has no column "Tags"`},
+		{"title,Title,due_at\n", nil, "more than one column fills title"},
+	} {
+		_, err := service.ImportTasks(testUserContext, strings.NewReader(tc.file), tc.mapping, false)
+		assert.ErrorIs(t, err, ErrInvalidData)
+		assert.ErrorContains(t, err, tc.message)
+	}
+	mockRepo.AssertNotCalled(t, "Transaction", mock.Anything)
+}
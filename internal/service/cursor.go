@@ -0,0 +1,179 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"todo-list/internal/entity"
+)
+
+// maxPageSize bounds the tasks of one list page.
+const maxPageSize = 1000
+
+// cursor is the signed content of a task list cursor: whose list it pages,
+// the order it was made for and the sort key values of a task in it. Only the
+// values of the order's keys are set.
+type cursor struct {
+	OwnerID   int             `json:"u"`
+	Order     string          `json:"o,omitempty"`
+	Backward  bool            `json:"b,omitempty"`
+	ID        int             `json:"i"`
+	Title     string          `json:"t,omitempty"`
+	DueAt     *time.Time      `json:"d,omitempty"`
+	Priority  entity.Priority `json:"p,omitempty"`
+	Completed bool            `json:"c,omitempty"`
+	Rank      float64         `json:"r,omitempty"`
+}
+
+var errInvalidCursor = fmt.Errorf("%w: invalid cursor", ErrInvalidData)
+
+// deriveCursorKey derives the key signing cursors from the JWT secret, so a
+// signature made for one can never pass for the other.
+func deriveCursorKey(secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("task list cursor"))
+	return mac.Sum(nil)
+}
+
+func (s *Service) signCursor(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.cursorKey)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// listOrder describes the order of a task list. A cursor only pages the order
+// it was made for, as its key values mean nothing in another.
+func listOrder(filter entity.TaskFilter) string {
+	if filter.Query != "" && len(filter.Sort) == 0 {
+		return "rank " + filter.Query
+	}
+
+	fields := make([]string, len(filter.Sort))
+	for i, field := range filter.Sort {
+		fields[i] = field.Field
+		if field.Desc {
+			fields[i] = "-" + fields[i]
+		}
+	}
+
+	return strings.Join(fields, ",")
+}
+
+// encodeCursor makes the cursor of the tasks after task in the list filter
+// pages, or before it when backward is set.
+func (s *Service) encodeCursor(filter entity.TaskFilter, task *entity.Task, backward bool) (string, error) {
+	c := cursor{
+		OwnerID:  filter.OwnerID,
+		Order:    listOrder(filter),
+		Backward: backward,
+		ID:       task.ID,
+	}
+	if filter.Query != "" && len(filter.Sort) == 0 {
+		c.Rank = task.Rank
+	}
+	for _, field := range filter.Sort {
+		switch field.Field {
+		case "title":
+			c.Title = task.Title
+		case "due_at":
+			dueAt := task.DueAt.UTC()
+			c.DueAt = &dueAt
+		case "priority":
+			c.Priority = task.Priority
+		case "completed":
+			c.Completed = task.Completed
+		}
+	}
+
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(s.signCursor(payload)), nil
+}
+
+// decodeCursor verifies the cursor of filter and returns the position it
+// holds. It must have been made for the same owner and order.
+func (s *Service) decodeCursor(filter entity.TaskFilter) (*entity.TaskKeyset, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(filter.Cursor, ".")
+	if !ok {
+		return nil, errInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, s.signCursor(payload)) {
+		return nil, errInvalidCursor
+	}
+
+	var c cursor
+	err = json.Unmarshal(payload, &c)
+	if err != nil || c.OwnerID != filter.OwnerID || c.ID <= 0 {
+		return nil, errInvalidCursor
+	}
+	if c.Order != listOrder(filter) {
+		return nil, fmt.Errorf("%w: cursor belongs to a different sort order", ErrInvalidData)
+	}
+
+	keyset := &entity.TaskKeyset{
+		ID:        c.ID,
+		Title:     c.Title,
+		Priority:  c.Priority,
+		Completed: c.Completed,
+		Rank:      c.Rank,
+		Backward:  c.Backward,
+	}
+	if c.DueAt != nil {
+		keyset.DueAt = *c.DueAt
+	}
+
+	return keyset, nil
+}
+
+// taskPage makes a page of tasks read for filter with one extra task past
+// limit, whose presence tells that another page follows, and links the
+// neighbouring pages.
+func (s *Service) taskPage(filter entity.TaskFilter, tasks []*entity.Task, limit int) (*entity.TaskPage, error) {
+	backward := filter.Keyset != nil && filter.Keyset.Backward
+	more := len(tasks) > limit
+	if more && backward {
+		tasks = tasks[len(tasks)-limit:]
+	} else if more {
+		tasks = tasks[:limit]
+	}
+
+	page := &entity.TaskPage{Tasks: tasks}
+	if len(tasks) == 0 {
+		page.Tasks = []*entity.Task{}
+		return page, nil
+	}
+
+	hasNext, hasPrev := more, filter.Keyset != nil || filter.Offset > 0
+	if backward {
+		hasNext, hasPrev = true, more
+	}
+
+	var err error
+	if hasNext {
+		page.NextCursor, err = s.encodeCursor(filter, tasks[len(tasks)-1], false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if hasPrev {
+		page.PrevCursor, err = s.encodeCursor(filter, tasks[0], true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
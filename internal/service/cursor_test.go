@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTaskList_Cursors(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{JWTSecret: "secret"})
+
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	tasks := []*entity.Task{
+		{ID: 1, Title: "a", DueAt: due, Priority: entity.PriorityHigh},
+		{ID: 2, Title: "b", DueAt: due, Priority: entity.PriorityLow},
+		{ID: 3, Title: "c", DueAt: due, Priority: entity.PriorityLow},
+	}
+	sort := []entity.SortField{{Field: "priority", Desc: true}, {Field: "due_at"}}
+
+	// The first page asks for one extra task to learn whether more follow.
+	mockRepo.On("GetTaskList", mock.Anything, entity.TaskFilter{OwnerID: 1, Sort: sort, Limit: 3}).Return(tasks, nil).Once()
+	page, err := service.GetTaskList(testUserContext, entity.TaskFilter{Sort: sort, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, tasks[:2], page.Tasks)
+	assert.Empty(t, page.PrevCursor)
+	require.NotEmpty(t, page.NextCursor)
+
+	next := &entity.TaskKeyset{ID: 2, DueAt: due, Priority: entity.PriorityLow}
+	mockRepo.On("GetTaskList", mock.Anything, entity.TaskFilter{OwnerID: 1, Sort: sort, Cursor: page.NextCursor, Keyset: next, Limit: 3}).Return(tasks[2:], nil).Once()
+	page, err = service.GetTaskList(testUserContext, entity.TaskFilter{Sort: sort, Cursor: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, tasks[2:], page.Tasks)
+	assert.Empty(t, page.NextCursor)
+	require.NotEmpty(t, page.PrevCursor)
+
+	// Reading backwards, the extra task comes first.
+	prev := &entity.TaskKeyset{ID: 3, DueAt: due, Priority: entity.PriorityLow, Backward: true}
+	mockRepo.On("GetTaskList", mock.Anything, entity.TaskFilter{OwnerID: 1, Sort: sort, Cursor: page.PrevCursor, Keyset: prev, Limit: 2}).Return(tasks[:2], nil).Once()
+	page, err = service.GetTaskList(testUserContext, entity.TaskFilter{Sort: sort, Cursor: page.PrevCursor, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, tasks[1:2], page.Tasks)
+	assert.NotEmpty(t, page.NextCursor)
+	assert.NotEmpty(t, page.PrevCursor)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTaskList_RankedCursor(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{JWTSecret: "secret"})
+
+	tasks := []*entity.Task{{ID: 4, Rank: 0.75}, {ID: 2, Rank: 0.5}}
+	mockRepo.On("GetTaskList", mock.Anything, entity.TaskFilter{OwnerID: 1, Query: "milk", Limit: 2}).Return(tasks, nil).Once()
+	page, err := service.GetTaskList(testUserContext, entity.TaskFilter{Query: "milk", Limit: 1})
+	require.NoError(t, err)
+
+	keyset := &entity.TaskKeyset{ID: 4, Rank: 0.75}
+	mockRepo.On("GetTaskList", mock.Anything, entity.TaskFilter{OwnerID: 1, Query: "milk", Cursor: page.NextCursor, Keyset: keyset, Limit: 2}).Return(tasks[1:], nil).Once()
+	_, err = service.GetTaskList(testUserContext, entity.TaskFilter{Query: "Milk", Cursor: page.NextCursor, Limit: 1})
+	assert.NoError(t, err)
+
+	// Ranks of one search mean nothing to another.
+	_, err = service.GetTaskList(testUserContext, entity.TaskFilter{Query: "bread", Cursor: page.NextCursor, Limit: 1})
+	assert.ErrorIs(t, err, ErrInvalidData)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTaskList_InvalidCursor(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{JWTSecret: "secret"})
+
+	mockRepo.On("GetTaskList", mock.Anything, mock.Anything).Return([]*entity.Task{{ID: 1}, {ID: 2}}, nil).Once()
+	page, err := service.GetTaskList(testUserContext, entity.TaskFilter{Limit: 1})
+	require.NoError(t, err)
+	cursor := page.NextCursor
+
+	payload, signature, _ := strings.Cut(cursor, ".")
+	other := NewService(mockRepo, nil, nil, nil, &configs.Config{JWTSecret: "other"})
+	otherUser := WithUser(context.Background(), &entity.User{ID: 2})
+
+	for name, call := range map[string]func() error{
+		"garbage": func() error {
+			_, err := service.GetTaskList(testUserContext, entity.TaskFilter{Cursor: "garbage", Limit: 1})
+			return err
+		},
+		"tampered": func() error {
+			_, err := service.GetTaskList(testUserContext, entity.TaskFilter{Cursor: payload + "x." + signature, Limit: 1})
+			return err
+		},
+		"other secret": func() error {
+			_, err := other.GetTaskList(testUserContext, entity.TaskFilter{Cursor: cursor, Limit: 1})
+			return err
+		},
+		"other user": func() error {
+			_, err := service.GetTaskList(otherUser, entity.TaskFilter{Cursor: cursor, Limit: 1})
+			return err
+		},
+		"other sort": func() error {
+			_, err := service.GetTaskList(testUserContext, entity.TaskFilter{Cursor: cursor, Sort: []entity.SortField{{Field: "title"}}, Limit: 1})
+			return err
+		},
+		"with page": func() error {
+			_, err := service.GetTaskList(testUserContext, entity.TaskFilter{Cursor: cursor, Offset: 1, Limit: 1})
+			return err
+		},
+		"limit": func() error {
+			_, err := service.GetTaskList(testUserContext, entity.TaskFilter{Limit: maxPageSize + 1})
+			return err
+		},
+	} {
+		assert.ErrorIs(t, call(), ErrInvalidData, name)
+	}
+	mockRepo.AssertExpectations(t)
+}
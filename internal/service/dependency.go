@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"todo-list/internal/entity"
+)
+
+var ErrBlocked = errors.New("task is blocked")
+
+// GetDependencyGraph returns the caller's dependencies, with the tasks taking
+// part in them in topological order: blockers first.
+func (s *Service) GetDependencyGraph(ctx context.Context) (*entity.DependencyGraph, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dependencies, err := s.TaskRepository.GetDependencies(ctx, user.ID)
+	if err != nil {
+		return nil, checkTimeout(ctx, err)
+	}
+
+	if dependencies == nil {
+		dependencies = []entity.Dependency{}
+	}
+
+	return &entity.DependencyGraph{Order: topologicalOrder(dependencies), Dependencies: dependencies}, nil
+}
+
+// topologicalOrder sorts the tasks of dependencies so that each blocker comes
+// before the tasks it blocks, breaking ties by id to keep the order stable.
+func topologicalOrder(dependencies []entity.Dependency) []int {
+	pending := make(map[int]int)
+	blocks := make(map[int][]int)
+	for _, dependency := range dependencies {
+		pending[dependency.TaskID]++
+		if _, ok := pending[dependency.BlockerID]; !ok {
+			pending[dependency.BlockerID] = 0
+		}
+		blocks[dependency.BlockerID] = append(blocks[dependency.BlockerID], dependency.TaskID)
+	}
+
+	var ready []int
+	for id, count := range pending {
+		if count == 0 {
+			ready = append(ready, id)
+		}
+	}
+
+	order := make([]int, 0, len(pending))
+	for len(ready) > 0 {
+		slices.Sort(ready)
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+
+		for _, blocked := range blocks[id] {
+			pending[blocked]--
+			if pending[blocked] == 0 {
+				ready = append(ready, blocked)
+			}
+		}
+	}
+
+	return order
+}
+
+// normalizeBlockers sorts and dedupes the blockers of task id, which may not
+// block itself.
+func normalizeBlockers(id int, blockers []int) ([]int, error) {
+	if len(blockers) == 0 {
+		return nil, nil
+	}
+
+	result := slices.Clone(blockers)
+	slices.Sort(result)
+	result = slices.Compact(result)
+	for _, blockerID := range result {
+		if blockerID <= 0 {
+			return nil, fmt.Errorf("%w: invalid blocking task %d", ErrInvalidData, blockerID)
+		}
+		if blockerID == id {
+			return nil, fmt.Errorf("%w: a task cannot block itself", ErrInvalidData)
+		}
+	}
+
+	return result, nil
+}
+
+// checkBlockers makes sure the blockers of task id are tasks of its owner
+// and that depending on them closes no cycle. id is 0 for a task not created
+// yet, which nothing can depend on.
+func (s *Service) checkBlockers(ctx context.Context, ownerID int, id int, blockers []int) error {
+	for _, blockerID := range blockers {
+		_, err := s.TaskRepository.GetTask(ctx, ownerID, blockerID)
+		if errors.Is(err, ErrNotFound) {
+			return fmt.Errorf("%w: unknown blocking task %d", ErrInvalidData, blockerID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if id == 0 || len(blockers) == 0 {
+		return nil
+	}
+
+	dependencies, err := s.TaskRepository.GetDependencies(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+
+	blockedBy := make(map[int][]int)
+	for _, dependency := range dependencies {
+		if dependency.TaskID != id {
+			blockedBy[dependency.TaskID] = append(blockedBy[dependency.TaskID], dependency.BlockerID)
+		}
+	}
+
+	// A cycle closes when id already blocks, directly or not, one of its
+	// new blockers.
+	seen := make(map[int]bool)
+	stack := slices.Clone(blockers)
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == id {
+			return fmt.Errorf("%w: dependencies would form a cycle", ErrInvalidData)
+		}
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		stack = append(stack, blockedBy[current]...)
+	}
+
+	return nil
+}
+
+// countOpenBlockers counts the blockers not yet completed.
+func (s *Service) countOpenBlockers(ctx context.Context, ownerID int, blockers []int) (int, error) {
+	open := 0
+	for _, blockerID := range blockers {
+		blocker, err := s.TaskRepository.GetTask(ctx, ownerID, blockerID)
+		if err != nil {
+			return 0, err
+		}
+		if !blocker.Completed {
+			open++
+		}
+	}
+
+	return open, nil
+}
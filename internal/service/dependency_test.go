@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateTask_Blockers(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	mockRepo.On("GetTask", mock.Anything, 1, 2).Return(&entity.Task{ID: 2}, nil)
+	mockRepo.On("GetTask", mock.Anything, 1, 3).Return(&entity.Task{ID: 3}, nil)
+	mockRepo.On("GetTask", mock.Anything, 1, 9).Return((*entity.Task)(nil), ErrNotFound)
+	mockRepo.On("InsertTask", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+		return assert.ObjectsAreEqual([]int{2, 3}, task.BlockedBy)
+	})).Return(int64(4), nil)
+
+	_, err := service.CreateTask(testUserContext, &entity.Task{Title: "Build", DueAt: time.Now(), BlockedBy: []int{3, 2, 3}})
+	assert.NoError(t, err)
+
+	_, err = service.CreateTask(testUserContext, &entity.Task{Title: "Build", DueAt: time.Now(), BlockedBy: []int{9}})
+	assert.ErrorIs(t, err, ErrInvalidData)
+
+	_, err = service.CreateTask(testUserContext, &entity.Task{Title: "Build", DueAt: time.Now(), BlockedBy: []int{0}})
+	assert.ErrorIs(t, err, ErrInvalidData)
+	mockRepo.AssertNumberOfCalls(t, "InsertTask", 1)
+}
+
+func TestUpdateTask_RejectsDependencyCycles(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	// 3 is blocked by 2, which is blocked by 1.
+	mockRepo.On("GetTask", mock.Anything, 1, mock.Anything).Return(&entity.Task{}, nil)
+	mockRepo.On("GetDependencies", mock.Anything, 1).Return([]entity.Dependency{{TaskID: 2, BlockerID: 1}, {TaskID: 3, BlockerID: 2}}, nil)
+
+	err := service.UpdateTask(testUserContext, 1, &entity.Task{Title: "Design", DueAt: time.Now(), BlockedBy: []int{3}}, false)
+	assert.ErrorIs(t, err, ErrInvalidData)
+
+	err = service.UpdateTask(testUserContext, 1, &entity.Task{Title: "Design", DueAt: time.Now(), BlockedBy: []int{1}}, false)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	mockRepo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateTask_ReplacesOwnBlockersWhenCheckingCycles(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	task := &entity.Task{Title: "Build", DueAt: time.Now(), BlockedBy: []int{2}}
+	mockRepo.On("GetTask", mock.Anything, 1, 2).Return(&entity.Task{ID: 2}, nil)
+	mockRepo.On("GetDependencies", mock.Anything, 1).Return([]entity.Dependency{{TaskID: 3, BlockerID: 2}, {TaskID: 3, BlockerID: 1}}, nil)
+	mockRepo.On("UpdateTask", mock.Anything, 1, 3, task).Return(nil)
+
+	assert.NoError(t, service.UpdateTask(testUserContext, 3, task, false))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateTask_OpenBlockers(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(&entity.Task{ID: 1}, nil)
+	mockRepo.On("GetTask", mock.Anything, 1, 2).Return(&entity.Task{ID: 2}, nil)
+	mockRepo.On("GetDependencies", mock.Anything, 1).Return([]entity.Dependency{}, nil)
+
+	err := service.UpdateTask(testUserContext, 1, &entity.Task{Title: "Build", DueAt: time.Now(), Completed: true, BlockedBy: []int{2}}, true)
+	assert.ErrorIs(t, err, ErrBlocked)
+	mockRepo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetDependencyGraph(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	dependencies := []entity.Dependency{{TaskID: 2, BlockerID: 5}, {TaskID: 4, BlockerID: 2}, {TaskID: 4, BlockerID: 3}}
+	mockRepo.On("GetDependencies", mock.Anything, 1).Return(dependencies, nil)
+
+	graph, err := service.GetDependencyGraph(testUserContext)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 5, 2, 4}, graph.Order)
+	assert.Equal(t, dependencies, graph.Dependencies)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"todo-list/internal/entity"
+)
+
+// defaultHistorySize is the number of events on a history page unless the
+// caller asks for another.
+const defaultHistorySize = 20
+
+// GetTaskHistory returns a page of a task's history, newest first. cursor is
+// the next_cursor of the page before, empty for the first. Tasks in the trash
+// keep their history until purged.
+func (s *Service) GetTaskHistory(ctx context.Context, id int, cursor string, limit int) (*entity.TaskHistory, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if id <= 0 || limit < 0 {
+		return nil, ErrInvalidData
+	}
+	if limit == 0 {
+		limit = defaultHistorySize
+	}
+	if limit > maxPageSize {
+		return nil, fmt.Errorf("%w: limit must be at most %d", ErrInvalidData, maxPageSize)
+	}
+
+	before := 0
+	if cursor != "" {
+		before, err = strconv.Atoi(cursor)
+		if err != nil || before <= 0 {
+			return nil, errInvalidCursor
+		}
+	}
+
+	events, err := s.TaskRepository.GetTaskHistory(ctx, user.ID, id, before, limit+1)
+	if err != nil {
+		return nil, checkTimeout(ctx, err)
+	}
+
+	history := &entity.TaskHistory{Events: events}
+	if len(events) > limit {
+		history.Events = events[:limit]
+		history.NextCursor = strconv.Itoa(events[limit-1].ID)
+	}
+	if history.Events == nil {
+		history.Events = []*entity.TaskEvent{}
+	}
+
+	return history, nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTaskHistory(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	events := []*entity.TaskEvent{{ID: 9}, {ID: 7}, {ID: 4}}
+	mockRepo.On("GetTaskHistory", mock.Anything, 1, 5, 0, 3).Return(events, nil)
+	mockRepo.On("GetTaskHistory", mock.Anything, 1, 5, 7, 3).Return(events[2:], nil)
+
+	history, err := service.GetTaskHistory(testUserContext, 5, "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, events[:2], history.Events)
+	assert.Equal(t, "7", history.NextCursor)
+
+	history, err = service.GetTaskHistory(testUserContext, 5, history.NextCursor, 2)
+	require.NoError(t, err)
+	assert.Equal(t, events[2:], history.Events)
+	assert.Empty(t, history.NextCursor)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTaskHistory_Invalid(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	for _, cursor := range []string{"abc", "0", "-3"} {
+		_, err := service.GetTaskHistory(testUserContext, 5, cursor, 10)
+		assert.ErrorIs(t, err, ErrInvalidData, cursor)
+	}
+
+	_, err := service.GetTaskHistory(testUserContext, 5, "", maxPageSize+1)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	mockRepo.AssertNotCalled(t, "GetTaskHistory", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
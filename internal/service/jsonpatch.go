@@ -0,0 +1,303 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"todo-list/internal/entity"
+)
+
+// ErrTestFailed is returned when a test operation of a JSON Patch does not
+// hold, in which case nothing of the patch is applied.
+var ErrTestFailed = errors.New("patch test failed")
+
+// JSONPatchTask applies a JSON Patch (RFC 6902) to a task. The operations run
+// in order against the task's JSON form, and the result is validated and saved
+// like a merge patch, in one write: either every operation takes effect or
+// none does. Add, remove and replace may only change writable fields, while
+// test may read any member. version is the version to patch, as for
+// UpdateTask. It returns the task as saved.
+func (s *Service) JSONPatchTask(ctx context.Context, id int, ops []entity.PatchOperation, version int, force bool) (*entity.Task, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err = s.expectedVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	if id <= 0 {
+		return nil, ErrInvalidData
+	}
+
+	current, err := s.TaskRepository.GetTask(ctx, user.ID, id)
+	if err != nil {
+		return nil, checkTimeout(ctx, err)
+	}
+
+	err = checkVersion(current, version)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := applyJSONPatch(current, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	return task, s.saveMerged(ctx, user, current, task, force)
+}
+
+// applyJSONPatch runs ops against a copy of current and decodes the result.
+// Empty tags and blockers are lists in the document rather than null, so that
+// an operation may add to them.
+func applyJSONPatch(current *entity.Task, ops []entity.PatchOperation) (*entity.Task, error) {
+	raw, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]any
+	err = decodeJSON(raw, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range []string{"tags", "blocked_by"} {
+		if doc[name] == nil {
+			doc[name] = []any{}
+		}
+	}
+
+	for i, op := range ops {
+		err = applyOperation(doc, op)
+		if err != nil {
+			return nil, fmt.Errorf("%w in operation %d", err, i)
+		}
+	}
+
+	raw, err = json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+
+	var task entity.Task
+	err = json.Unmarshal(raw, &task)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+
+	return &task, nil
+}
+
+func applyOperation(doc map[string]any, op entity.PatchOperation) error {
+	tokens, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+
+	var value any
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return fmt.Errorf("%w: %s needs a value", ErrInvalidData, op.Op)
+		}
+
+		err = decodeJSON(op.Value, &value)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidData, err)
+		}
+	case "remove":
+	default:
+		return fmt.Errorf("%w: unsupported op %q", ErrInvalidData, op.Op)
+	}
+
+	if op.Op == "test" {
+		target, ok := lookupPointer(doc, tokens)
+		if !ok || !equalJSON(target, value) {
+			return ErrTestFailed
+		}
+
+		return nil
+	}
+
+	if len(tokens) == 0 || !slices.Contains(entity.WritableFields, tokens[0]) {
+		return fmt.Errorf("%w: path cannot be patched", ErrInvalidData)
+	}
+
+	_, err = mutatePointer(doc, tokens, op.Op, value)
+	return err
+}
+
+// parsePointer splits a JSON Pointer into its unescaped reference tokens. The
+// empty pointer, the whole document, has none.
+func parsePointer(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	if path[0] != '/' {
+		return nil, fmt.Errorf("%w: path must start with /", ErrInvalidData)
+	}
+
+	tokens := strings.Split(path[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func lookupPointer(node any, tokens []string) (any, bool) {
+	for _, token := range tokens {
+		switch n := node.(type) {
+		case map[string]any:
+			child, ok := n[token]
+			if !ok {
+				return nil, false
+			}
+			node = child
+		case []any:
+			i, err := arrayIndex(token, len(n), false)
+			if err != nil {
+				return nil, false
+			}
+			node = n[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return node, true
+}
+
+// mutatePointer applies op at the location tokens name below node and returns
+// node as changed, since inserting into or removing from a list may move it.
+func mutatePointer(node any, tokens []string, op string, value any) (any, error) {
+	token := tokens[0]
+	last := len(tokens) == 1
+
+	switch n := node.(type) {
+	case map[string]any:
+		child, ok := n[token]
+		if last {
+			switch {
+			case op == "remove" && ok:
+				delete(n, token)
+			case op == "add" || ok:
+				n[token] = value
+			default:
+				return nil, fmt.Errorf("%w: path not found", ErrInvalidData)
+			}
+
+			return n, nil
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("%w: path not found", ErrInvalidData)
+		}
+
+		child, err := mutatePointer(child, tokens[1:], op, value)
+		if err != nil {
+			return nil, err
+		}
+
+		n[token] = child
+		return n, nil
+	case []any:
+		i, err := arrayIndex(token, len(n), last && op == "add")
+		if err != nil {
+			return nil, err
+		}
+
+		if !last {
+			child, err := mutatePointer(n[i], tokens[1:], op, value)
+			if err != nil {
+				return nil, err
+			}
+
+			n[i] = child
+			return n, nil
+		}
+
+		switch op {
+		case "add":
+			return slices.Insert(n, i, value), nil
+		case "remove":
+			return slices.Delete(n, i, i+1), nil
+		default:
+			n[i] = value
+			return n, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: path not found", ErrInvalidData)
+}
+
+// arrayIndex reads a reference token as an index into a list of length n.
+// When inserting, the index may be n, which "-" also stands for.
+func arrayIndex(token string, n int, insert bool) (int, error) {
+	if insert && token == "-" {
+		return n, nil
+	}
+
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || strconv.Itoa(i) != token {
+		return 0, fmt.Errorf("%w: invalid list index %q", ErrInvalidData, token)
+	}
+
+	if i > n || i == n && !insert {
+		return 0, fmt.Errorf("%w: path not found", ErrInvalidData)
+	}
+
+	return i, nil
+}
+
+// equalJSON compares two decoded JSON values, numbers by their value.
+func equalJSON(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+
+		x, errA := a.Float64()
+		y, errB := b.Float64()
+		return errA == nil && errB == nil && x == y
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for name, value := range a {
+			other, ok := b[name]
+			if !ok || !equalJSON(value, other) {
+				return false
+			}
+		}
+
+		return true
+	case []any:
+		b, ok := b.([]any)
+		return ok && slices.EqualFunc(a, b, equalJSON)
+	case string, bool, nil:
+		return a == b
+	}
+
+	return false
+}
+
+func decodeJSON(raw []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
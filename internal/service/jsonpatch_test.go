@@ -0,0 +1,139 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func jsonPatch(t *testing.T, doc string) []entity.PatchOperation {
+	var ops []entity.PatchOperation
+	require.NoError(t, json.Unmarshal([]byte(doc), &ops))
+	return ops
+}
+
+func TestJSONPatchTask(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	current := &entity.Task{ID: 1, OwnerID: 1, Title: "Old", DueAt: due, Tags: []string{"home", "work"}}
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(current, nil)
+
+	want := &entity.Task{ID: 1, OwnerID: 1, Title: "New", DueAt: due, Tags: []string{"errand", "home"}}
+	mockRepo.On("PatchTask", mock.Anything, 1, 1, want, []string{"title", "tags"}).Return(nil)
+
+	task, err := service.JSONPatchTask(testUserContext, 1, jsonPatch(t, `[
+		{"op": "test", "path": "/title", "value": "Old"},
+		{"op": "test", "path": "/id", "value": 1.0},
+		{"op": "replace", "path": "/title", "value": "New"},
+		{"op": "remove", "path": "/tags/1"},
+		{"op": "add", "path": "/tags/-", "value": "errand"},
+		{"op": "test", "path": "/tags", "value": ["home", "errand"]}
+	]`), 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, want, task)
+	assert.Equal(t, []string{"home", "work"}, current.Tags)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestJSONPatchTask_RaceWithoutVersion(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	current := &entity.Task{ID: 1, OwnerID: 1, Title: "Task", DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), Tags: []string{"home"}, Version: 3}
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(current, nil)
+	// Another write got in after the read: the tags the patch was worked out
+	// on are gone, so the write must not go through.
+	mockRepo.On("PatchTask", mock.Anything, 1, 1, mock.MatchedBy(func(task *entity.Task) bool {
+		return task.Version == 3
+	}), []string{"tags"}).Return(ErrVersionMismatch)
+
+	_, err := service.JSONPatchTask(testUserContext, 1, jsonPatch(t, `[
+		{"op": "test", "path": "/tags", "value": ["home"]},
+		{"op": "add", "path": "/tags/-", "value": "work"}
+	]`), 0, false)
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestJSONPatchTask_EmptyCollections(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	current := &entity.Task{ID: 1, OwnerID: 1, Title: "Task", DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(current, nil)
+	mockRepo.On("PatchTask", mock.Anything, 1, 1, mock.Anything, []string{"tags"}).Return(nil)
+
+	task, err := service.JSONPatchTask(testUserContext, 1, jsonPatch(t, `[
+		{"op": "test", "path": "/tags", "value": []},
+		{"op": "add", "path": "/tags/0", "value": "first"}
+	]`), 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first"}, task.Tags)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestJSONPatchTask_TestFailed(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	current := &entity.Task{ID: 1, OwnerID: 1, Title: "Task", DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), Tags: []string{"home"}}
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(current, nil)
+
+	for _, doc := range []string{
+		`[{"op": "replace", "path": "/title", "value": "New"}, {"op": "test", "path": "/title", "value": "Task"}]`,
+		`[{"op": "test", "path": "/tags/1", "value": "home"}]`,
+		`[{"op": "test", "path": "/project_id", "value": 3}]`,
+		`[{"op": "test", "path": "/completed", "value": "false"}]`,
+	} {
+		_, err := service.JSONPatchTask(testUserContext, 1, jsonPatch(t, doc), 0, false)
+		assert.ErrorIs(t, err, ErrTestFailed, doc)
+	}
+	mockRepo.AssertNotCalled(t, "PatchTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestJSONPatchTask_Invalid(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	current := &entity.Task{ID: 1, OwnerID: 1, Title: "Task", DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), Tags: []string{"home"}}
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(current, nil)
+
+	for _, doc := range []string{
+		`[{"op": "move", "from": "/title", "path": "/description"}]`,
+		`[{"op": "replace", "path": "title", "value": "New"}]`,
+		`[{"op": "replace", "path": "/id", "value": 2}]`,
+		`[{"op": "replace", "path": "", "value": {}}]`,
+		`[{"op": "add", "path": "/title"}]`,
+		`[{"op": "remove", "path": "/title"}]`,
+		`[{"op": "replace", "path": "/tags/1", "value": "work"}]`,
+		`[{"op": "remove", "path": "/tags/01"}]`,
+		`[{"op": "add", "path": "/tags/-/x", "value": "work"}]`,
+		`[{"op": "add", "path": "/tags/-", "value": 5}]`,
+		`[{"op": "replace", "path": "/due_at", "value": "tomorrow"}]`,
+	} {
+		_, err := service.JSONPatchTask(testUserContext, 1, jsonPatch(t, doc), 0, false)
+		assert.ErrorIs(t, err, ErrInvalidData, doc)
+	}
+	mockRepo.AssertNotCalled(t, "PatchTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestParsePointer(t *testing.T) {
+	tokens, err := parsePointer("/a~1b/m~0n/0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a/b", "m~n", "0"}, tokens)
+
+	tokens, err = parsePointer("")
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	_, err = parsePointer("title")
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
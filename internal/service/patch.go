@@ -0,0 +1,135 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"todo-list/internal/entity"
+)
+
+// PatchTask applies a JSON Merge Patch to a task and saves the fields that
+// change. The merged task is validated like UpdateTask validates a whole one,
+// so completing it through a patch is guarded the same way. version is the
+// version to patch, as for UpdateTask. It returns the task as saved.
+func (s *Service) PatchTask(ctx context.Context, id int, patch entity.TaskPatch, version int, force bool) (*entity.Task, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err = s.expectedVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	if id <= 0 || len(patch) == 0 {
+		return nil, ErrInvalidData
+	}
+
+	current, err := s.TaskRepository.GetTask(ctx, user.ID, id)
+	if err != nil {
+		return nil, checkTimeout(ctx, err)
+	}
+
+	err = checkVersion(current, version)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := mergeTask(current, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	return task, s.saveMerged(ctx, user, current, task, force)
+}
+
+// saveMerged validates task, the result of changing current, and saves the
+// fields that differ, provided the stored task is still at current's version:
+// a patch worked out on a task another write has changed since fails with
+// ErrVersionMismatch rather than undo that write. An all-day task is only pinned to its day again when its due
+// time or all-day flag changed, lest a patch sent from another time zone move
+// it.
+func (s *Service) saveMerged(ctx context.Context, user *entity.User, current *entity.Task, task *entity.Task, force bool) error {
+	if task.Title == "" || task.DueAt.IsZero() || !validPriority(task.Priority) {
+		return ErrInvalidData
+	}
+
+	if !task.DueAt.Equal(current.DueAt) || task.AllDay != current.AllDay {
+		err := s.normalizeDueAt(ctx, user, task)
+		if err != nil {
+			return checkTimeout(ctx, err)
+		}
+	}
+
+	rule, err := s.checkTask(ctx, user, current.ID, task)
+	if err != nil {
+		return checkTimeout(ctx, err)
+	}
+
+	task.ID = current.ID
+	task.OwnerID = user.ID
+	task.Version = current.Version
+	fields := entity.ChangedFields(current, task)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if task.Completed && !current.Completed {
+		return checkTimeout(ctx, s.completeTask(ctx, user, current.ID, task, rule, force, fields))
+	}
+
+	return checkTimeout(ctx, s.TaskRepository.PatchTask(ctx, user.ID, current.ID, task, fields))
+}
+
+// mergeTask applies patch to a copy of current, following RFC 7396 over the
+// task's JSON form.
+func mergeTask(current *entity.Task, patch entity.TaskPatch) (*entity.Task, error) {
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+
+	var members map[string]json.RawMessage
+	err = json.Unmarshal(doc, &members)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range patch {
+		if !slices.Contains(entity.WritableFields, name) {
+			return nil, fmt.Errorf("%w: %s cannot be patched", ErrInvalidData, name)
+		}
+
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			delete(members, name)
+		} else {
+			members[name] = value
+		}
+	}
+
+	doc, err = json.Marshal(members)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+
+	var task entity.Task
+	err = json.Unmarshal(doc, &task)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidData, err)
+	}
+
+	return &task, nil
+}
+
+// saveTask writes a task: all of it when fields is nil, otherwise only the
+// named fields.
+func (s *Service) saveTask(ctx context.Context, ownerID int, id int, task *entity.Task, fields []string) error {
+	if fields == nil {
+		return s.TaskRepository.UpdateTask(ctx, ownerID, id, task)
+	}
+
+	return s.TaskRepository.PatchTask(ctx, ownerID, id, task, fields)
+}
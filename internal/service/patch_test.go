@@ -0,0 +1,95 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func mergePatch(t *testing.T, doc string) entity.TaskPatch {
+	var patch entity.TaskPatch
+	require.NoError(t, json.Unmarshal([]byte(doc), &patch))
+	return patch
+}
+
+func TestPatchTask(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	current := &entity.Task{ID: 1, OwnerID: 1, ProjectID: intPtr(3), Title: "Old", Description: "Keep me", DueAt: due, Tags: []string{"home"}}
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(current, nil)
+
+	want := &entity.Task{ID: 1, OwnerID: 1, Title: "New", Description: "Keep me", DueAt: due, Priority: entity.PriorityHigh, Tags: []string{"home"}}
+	mockRepo.On("PatchTask", mock.Anything, 1, 1, want, []string{"project_id", "title", "priority"}).Return(nil)
+
+	task, err := service.PatchTask(testUserContext, 1, mergePatch(t, `{"title": "New", "priority": "high", "project_id": null}`), 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, want, task)
+	assert.Equal(t, "Old", current.Title)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPatchTask_NoChange(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	current := &entity.Task{ID: 1, OwnerID: 1, Title: "Same", DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(current, nil)
+
+	_, err := service.PatchTask(testUserContext, 1, mergePatch(t, `{"title": "Same"}`), 0, false)
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "PatchTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPatchTask_Complete(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	current := &entity.Task{ID: 1, OwnerID: 1, Title: "Parent", DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(current, nil)
+	mockRepo.On("CountOpenSubtasks", mock.Anything, 1, 1).Return(2, nil).Once()
+
+	_, err := service.PatchTask(testUserContext, 1, mergePatch(t, `{"completed": true}`), 0, false)
+	assert.ErrorIs(t, err, ErrOpenSubtasks)
+
+	mockRepo.On("PatchTask", mock.Anything, 1, 1, mock.Anything, []string{"completed"}).Return(nil)
+	task, err := service.PatchTask(testUserContext, 1, mergePatch(t, `{"completed": true}`), 0, true)
+	require.NoError(t, err)
+	assert.True(t, task.Completed)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPatchTask_Invalid(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	current := &entity.Task{ID: 1, OwnerID: 1, Title: "Task", DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(current, nil)
+	mockRepo.On("GetTask", mock.Anything, 1, 2).Return((*entity.Task)(nil), ErrNotFound)
+
+	for _, doc := range []string{
+		`{"title": null}`,
+		`{"due_at": null}`,
+		`{"id": 5}`,
+		`{"owner_id": 2}`,
+		`{"color": "red"}`,
+		`{"priority": "extreme"}`,
+		`{"completed": "yes"}`,
+		`{"recurrence": "FREQ=HOURLY"}`,
+		`{}`,
+	} {
+		_, err := service.PatchTask(testUserContext, 1, mergePatch(t, doc), 0, false)
+		assert.ErrorIs(t, err, ErrInvalidData, doc)
+	}
+
+	_, err := service.PatchTask(testUserContext, 2, mergePatch(t, `{"title": "New"}`), 0, false)
+	assert.ErrorIs(t, err, ErrNotFound)
+	mockRepo.AssertNotCalled(t, "PatchTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"todo-list/internal/entity"
+)
+
+var ErrProjectNotFound = errors.New("project not found")
+
+const maxNameLength = 255
+
+func (s *Service) CreateProject(ctx context.Context, project *entity.Project) (int64, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return -1, err
+	}
+
+	project.Name = strings.TrimSpace(project.Name)
+	if project.Name == "" || len(project.Name) > maxNameLength {
+		return -1, ErrInvalidData
+	}
+
+	project.OwnerID = user.ID
+	project.Inbox = false
+	id, err := s.ProjectRepository.InsertProject(ctx, project)
+	return id, checkTimeout(ctx, err)
+}
+
+func (s *Service) GetProject(ctx context.Context, id int) (*entity.Project, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if id <= 0 {
+		return nil, ErrInvalidData
+	}
+
+	project, err := s.ProjectRepository.GetProject(ctx, user.ID, id)
+	return project, checkTimeout(ctx, err)
+}
+
+func (s *Service) GetProjectList(ctx context.Context) ([]*entity.Project, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := s.ProjectRepository.GetProjectList(ctx, user.ID)
+	return projects, checkTimeout(ctx, err)
+}
+
+func (s *Service) UpdateProject(ctx context.Context, id int, project *entity.Project) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	project.Name = strings.TrimSpace(project.Name)
+	if project.Name == "" || len(project.Name) > maxNameLength || id <= 0 {
+		return ErrInvalidData
+	}
+
+	project.OwnerID = user.ID
+	return checkTimeout(ctx, s.ProjectRepository.UpdateProject(ctx, user.ID, id, project))
+}
+
+// DeleteProject deletes a project, moving its tasks and their subtasks to the
+// trash when cascade is set, and otherwise to the caller's inbox first. Tasks
+// trashed with the project come back without one.
+func (s *Service) DeleteProject(ctx context.Context, id int, cascade bool) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if id <= 0 {
+		return ErrInvalidData
+	}
+
+	project, err := s.ProjectRepository.GetProject(ctx, user.ID, id)
+	if err != nil {
+		return checkTimeout(ctx, err)
+	}
+
+	if project.Inbox {
+		return fmt.Errorf("%w: the inbox project cannot be deleted", ErrInvalidData)
+	}
+
+	var moveTo int
+	if !cascade {
+		inbox, err := s.ProjectRepository.EnsureInboxProject(ctx, user.ID)
+		if err != nil {
+			return checkTimeout(ctx, err)
+		}
+		moveTo = inbox.ID
+	}
+
+	return checkTimeout(ctx, s.ProjectRepository.DeleteProject(ctx, user.ID, id, moveTo))
+}
+
+// checkProject makes sure a task only points at a project of its owner.
+func (s *Service) checkProject(ctx context.Context, ownerID int, projectID *int) error {
+	if projectID == nil {
+		return nil
+	}
+
+	_, err := s.ProjectRepository.GetProject(ctx, ownerID, *projectID)
+	if errors.Is(err, ErrProjectNotFound) {
+		return fmt.Errorf("%w: unknown project %d", ErrInvalidData, *projectID)
+	}
+
+	return err
+}
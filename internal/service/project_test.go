@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProjectRepository struct {
+	mock.Mock
+}
+
+func (m *MockProjectRepository) InsertProject(ctx context.Context, project *entity.Project) (int64, error) {
+	args := m.Called(ctx, project)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockProjectRepository) GetProject(ctx context.Context, ownerID int, id int) (*entity.Project, error) {
+	args := m.Called(ctx, ownerID, id)
+	return args.Get(0).(*entity.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) GetProjectList(ctx context.Context, ownerID int) ([]*entity.Project, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).([]*entity.Project), args.Error(1)
+}
+
+func (m *MockProjectRepository) UpdateProject(ctx context.Context, ownerID int, id int, project *entity.Project) error {
+	args := m.Called(ctx, ownerID, id, project)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) DeleteProject(ctx context.Context, ownerID int, id int, moveTasksTo int) error {
+	args := m.Called(ctx, ownerID, id, moveTasksTo)
+	return args.Error(0)
+}
+
+func (m *MockProjectRepository) EnsureInboxProject(ctx context.Context, ownerID int) (*entity.Project, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).(*entity.Project), args.Error(1)
+}
+
+func TestCreateProject(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	service := NewService(nil, nil, mockRepo, nil, &configs.Config{})
+
+	mockRepo.On("InsertProject", mock.Anything, &entity.Project{OwnerID: 1, Name: "Home"}).Return(int64(1), nil)
+
+	id, err := service.CreateProject(testUserContext, &entity.Project{Name: " Home ", Inbox: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateProject_InvalidName(t *testing.T) {
+	service := NewService(nil, nil, new(MockProjectRepository), nil, &configs.Config{})
+
+	_, err := service.CreateProject(testUserContext, &entity.Project{Name: "  "})
+	assert.Equal(t, ErrInvalidData, err)
+}
+
+func TestDeleteProject_MovesTasksToInbox(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	service := NewService(nil, nil, mockRepo, nil, &configs.Config{})
+
+	mockRepo.On("GetProject", mock.Anything, 1, 2).Return(&entity.Project{ID: 2, OwnerID: 1, Name: "Home"}, nil)
+	mockRepo.On("EnsureInboxProject", mock.Anything, 1).Return(&entity.Project{ID: 5, OwnerID: 1, Name: "Inbox", Inbox: true}, nil)
+	mockRepo.On("DeleteProject", mock.Anything, 1, 2, 5).Return(nil)
+
+	assert.NoError(t, service.DeleteProject(testUserContext, 2, false))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteProject_Cascade(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	service := NewService(nil, nil, mockRepo, nil, &configs.Config{})
+
+	mockRepo.On("GetProject", mock.Anything, 1, 2).Return(&entity.Project{ID: 2, OwnerID: 1, Name: "Home"}, nil)
+	mockRepo.On("DeleteProject", mock.Anything, 1, 2, 0).Return(nil)
+
+	assert.NoError(t, service.DeleteProject(testUserContext, 2, true))
+	mockRepo.AssertNotCalled(t, "EnsureInboxProject", mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteProject_Inbox(t *testing.T) {
+	mockRepo := new(MockProjectRepository)
+	service := NewService(nil, nil, mockRepo, nil, &configs.Config{})
+
+	mockRepo.On("GetProject", mock.Anything, 1, 5).Return(&entity.Project{ID: 5, OwnerID: 1, Name: "Inbox", Inbox: true}, nil)
+
+	err := service.DeleteProject(testUserContext, 5, true)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTask_UnknownProject(t *testing.T) {
+	mockTasks := new(MockTaskRepository)
+	mockProjects := new(MockProjectRepository)
+	service := NewService(mockTasks, nil, mockProjects, nil, &configs.Config{})
+	project := 7
+
+	mockProjects.On("GetProject", mock.Anything, 1, 7).Return((*entity.Project)(nil), ErrProjectNotFound)
+
+	_, err := service.CreateTask(testUserContext, &entity.Task{Title: "Test Task", DueAt: time.Now(), ProjectID: &project})
+	assert.ErrorIs(t, err, ErrInvalidData)
+	mockTasks.AssertNotCalled(t, "InsertTask", mock.Anything, mock.Anything)
+	mockProjects.AssertExpectations(t)
+}
@@ -0,0 +1,394 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list/internal/entity"
+)
+
+// Recurrence frequencies, as spelled in RFC 5545 FREQ values.
+const (
+	freqDaily   = "DAILY"
+	freqWeekly  = "WEEKLY"
+	freqMonthly = "MONTHLY"
+	freqYearly  = "YEARLY"
+)
+
+// untilLayouts are the UNTIL forms accepted: a date, or a UTC date-time.
+const (
+	untilDateLayout     = "20060102"
+	untilDateTimeLayout = "20060102T150405Z"
+)
+
+// maxRecurrencePeriods bounds how many periods a series is walked through,
+// so a rule that rarely or never yields a date cannot spin forever.
+const maxRecurrencePeriods = 100000
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// recurrence is a parsed RRULE limited to FREQ, INTERVAL, BYDAY, COUNT and
+// UNTIL. BYDAY is a plain weekday list and only applies to daily and weekly
+// rules.
+type recurrence struct {
+	freq     string
+	interval int
+	byDay    []time.Weekday
+	count    int
+	until    time.Time
+	// untilDate is set when UNTIL was a date, which includes that whole day.
+	untilDate bool
+}
+
+// parseRecurrence reads a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE".
+// An "RRULE:" prefix is allowed.
+func parseRecurrence(value string) (*recurrence, error) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "RRULE:")
+
+	rule := &recurrence{interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, recurrenceError("malformed part %q", part)
+		}
+		if seen[key] {
+			return nil, recurrenceError("%s given twice", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			switch val {
+			case freqDaily, freqWeekly, freqMonthly, freqYearly:
+				rule.freq = val
+			default:
+				return nil, recurrenceError("unsupported FREQ %q", val)
+			}
+		case "INTERVAL":
+			rule.interval, err = strconv.Atoi(val)
+			if err != nil || rule.interval < 1 {
+				return nil, recurrenceError("INTERVAL must be a positive number")
+			}
+		case "COUNT":
+			rule.count, err = strconv.Atoi(val)
+			if err != nil || rule.count < 1 {
+				return nil, recurrenceError("COUNT must be a positive number")
+			}
+		case "UNTIL":
+			rule.until, err = time.Parse(untilDateTimeLayout, val)
+			if err != nil {
+				rule.until, err = time.Parse(untilDateLayout, val)
+				rule.untilDate = true
+			}
+			if err != nil {
+				return nil, recurrenceError("UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ")
+			}
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				day, ok := weekdayCodes[code]
+				if !ok {
+					return nil, recurrenceError("unsupported BYDAY value %q", code)
+				}
+				if !slices.Contains(rule.byDay, day) {
+					rule.byDay = append(rule.byDay, day)
+				}
+			}
+			slices.SortFunc(rule.byDay, func(a, b time.Weekday) int { return weekdayOffset(a) - weekdayOffset(b) })
+		default:
+			return nil, recurrenceError("unsupported part %s", key)
+		}
+	}
+
+	if rule.freq == "" {
+		return nil, recurrenceError("FREQ is required")
+	}
+	if rule.count > 0 && !rule.until.IsZero() {
+		return nil, recurrenceError("COUNT and UNTIL cannot be combined")
+	}
+	if len(rule.byDay) > 0 && rule.freq != freqDaily && rule.freq != freqWeekly {
+		return nil, recurrenceError("BYDAY is only supported with DAILY or WEEKLY")
+	}
+
+	return rule, nil
+}
+
+func recurrenceError(format string, args ...any) error {
+	return fmt.Errorf("%w: recurrence: %s", ErrInvalidData, fmt.Sprintf(format, args...))
+}
+
+// String renders the rule in a canonical form, which is what gets stored.
+func (r *recurrence) String() string {
+	parts := []string{"FREQ=" + r.freq}
+	if r.interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.interval))
+	}
+	if len(r.byDay) > 0 {
+		codes := make([]string, len(r.byDay))
+		for i, day := range r.byDay {
+			codes[i] = strings.ToUpper(day.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.count))
+	}
+	if !r.until.IsZero() {
+		layout := untilDateTimeLayout
+		if r.untilDate {
+			layout = untilDateLayout
+		}
+		parts = append(parts, "UNTIL="+r.until.Format(layout))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// occurrences calls yield with each occurrence of a series starting at start,
+// in order, until yield returns false or the series ends. start is always the
+// first occurrence and counts towards COUNT, as DTSTART does in RFC 5545.
+// Dates are computed on the wall clock of start's location, so a series keeps
+// its local time across DST changes.
+func (r *recurrence) occurrences(start time.Time, yield func(time.Time) bool) {
+	n := 0
+	emit := func(t time.Time) bool {
+		if r.ended(t) {
+			return false
+		}
+		n++
+		return yield(t) && (r.count == 0 || n < r.count)
+	}
+
+	if !emit(start) {
+		return
+	}
+
+	for period := 0; period < maxRecurrencePeriods; period++ {
+		for _, t := range r.period(start, period) {
+			if !t.After(start) {
+				continue
+			}
+			if !emit(t) {
+				return
+			}
+		}
+	}
+}
+
+// next returns the occurrence following start, or false when start is the
+// last one of its series.
+func (r *recurrence) next(start time.Time) (time.Time, bool) {
+	var next time.Time
+	found := false
+	r.occurrences(start, func(t time.Time) bool {
+		if t.Equal(start) {
+			return true
+		}
+		next, found = t, true
+		return false
+	})
+
+	return next, found
+}
+
+// ended reports whether t falls after UNTIL.
+func (r *recurrence) ended(t time.Time) bool {
+	if r.until.IsZero() {
+		return false
+	}
+
+	if r.untilDate {
+		year, month, day := t.Date()
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC).After(r.until)
+	}
+
+	return t.After(r.until)
+}
+
+// period returns the candidate dates of the n-th period of a series, in order.
+func (r *recurrence) period(start time.Time, n int) []time.Time {
+	step := n * r.interval
+	year, month, day := start.Date()
+	hour, minute, sec := start.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, sec, start.Nanosecond(), start.Location())
+	}
+
+	switch r.freq {
+	case freqDaily:
+		t := start.AddDate(0, 0, step)
+		if len(r.byDay) > 0 && !slices.Contains(r.byDay, t.Weekday()) {
+			return nil
+		}
+		return []time.Time{t}
+	case freqWeekly:
+		if len(r.byDay) == 0 {
+			return []time.Time{start.AddDate(0, 0, 7*step)}
+		}
+		monday := at(year, month, day-weekdayOffset(start.Weekday())+7*step)
+		dates := make([]time.Time, len(r.byDay))
+		for i, weekday := range r.byDay {
+			y, m, d := monday.Date()
+			dates[i] = at(y, m, d+weekdayOffset(weekday))
+		}
+		return dates
+	case freqMonthly:
+		t := at(year, month+time.Month(step), day)
+		if t.Day() != day {
+			// Months without this day are skipped, not clamped.
+			return nil
+		}
+		return []time.Time{t}
+	case freqYearly:
+		t := at(year+step, month, day)
+		if t.Day() != day {
+			return nil
+		}
+		return []time.Time{t}
+	}
+
+	return nil
+}
+
+// weekdayOffset counts days from Monday, the RFC 5545 default week start.
+func weekdayOffset(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+// rest returns the rule of the series that continues after its first
+// occurrence, with one fewer left to COUNT.
+func (r *recurrence) rest() *recurrence {
+	rest := *r
+	if rest.count > 0 {
+		rest.count--
+	}
+
+	return &rest
+}
+
+// Limits of GetOccurrences.
+const (
+	defaultOccurrenceDays = 30
+	maxOccurrences        = 1000
+)
+
+// GetOccurrences expands a task's series into its due times within the days
+// from and to, both inclusive and read in the caller's time zone. from
+// defaults to today and to to 30 days after from.
+func (s *Service) GetOccurrences(ctx context.Context, id int, from, to string) ([]time.Time, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if id <= 0 {
+		return nil, ErrInvalidData
+	}
+
+	loc, err := s.location(ctx, user)
+	if err != nil {
+		return nil, checkTimeout(ctx, err)
+	}
+
+	if from == "" {
+		from = "today"
+	}
+	start, err := s.parseDay("from", from, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	end := start.AddDate(0, 0, defaultOccurrenceDays)
+	if to != "" {
+		day, err := s.parseDay("to", to, loc)
+		if err != nil {
+			return nil, err
+		}
+		end = day.AddDate(0, 0, 1)
+	}
+	if !start.Before(end) {
+		return nil, fmt.Errorf("%w: from must not be after to", ErrInvalidData)
+	}
+
+	task, err := s.TaskRepository.GetTask(ctx, user.ID, id)
+	if err != nil {
+		return nil, checkTimeout(ctx, err)
+	}
+
+	// A task that does not repeat is a series of one.
+	rule := &recurrence{freq: freqDaily, interval: 1, count: 1}
+	if task.Recurrence != "" {
+		rule, err = parseRecurrence(task.Recurrence)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	occurrences := []time.Time{}
+	rule.occurrences(task.DueAt.In(loc), func(t time.Time) bool {
+		if !t.Before(end) {
+			return false
+		}
+		if !t.Before(start) {
+			occurrences = append(occurrences, t)
+		}
+		return len(occurrences) < maxOccurrences
+	})
+
+	return occurrences, nil
+}
+
+// normalizeRecurrence validates the rule of task and stores it in canonical
+// form. It returns nil for a task that does not repeat.
+func normalizeRecurrence(task *entity.Task) (*recurrence, error) {
+	if task.Recurrence == "" {
+		return nil, nil
+	}
+
+	rule, err := parseRecurrence(task.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+	task.Recurrence = rule.String()
+
+	return rule, nil
+}
+
+// completeOccurrence saves an open recurring task as completed and, unless it
+// ends its series, creates the next occurrence. The series moves on to the new
+// task: the completed one keeps no rule, so reopening and completing it again
+// does not repeat it twice.
+func (s *Service) completeOccurrence(ctx context.Context, user *entity.User, id int, task *entity.Task, rule *recurrence) error {
+	loc, err := s.location(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	dueAt, ok := rule.next(task.DueAt.In(loc))
+	if !ok {
+		return s.TaskRepository.UpdateTask(ctx, user.ID, id, task)
+	}
+
+	next := *task
+	next.DueAt = dueAt
+	next.Completed = false
+	next.Tags = slices.Clone(task.Tags)
+	next.BlockedBy = slices.Clone(task.BlockedBy)
+	next.Recurrence = rule.rest().String()
+	task.Recurrence = ""
+
+	_, err = s.TaskRepository.CompleteOccurrence(ctx, user.ID, id, task, &next)
+	return err
+}
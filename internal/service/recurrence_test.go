@@ -0,0 +1,206 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func expand(t *testing.T, value string, start time.Time, limit int) []time.Time {
+	t.Helper()
+
+	rule, err := parseRecurrence(value)
+	require.NoError(t, err)
+
+	var result []time.Time
+	rule.occurrences(start, func(t time.Time) bool {
+		result = append(result, t)
+		return len(result) < limit
+	})
+
+	return result
+}
+
+func dates(times []time.Time) []string {
+	result := make([]string, len(times))
+	for i, t := range times {
+		result[i] = t.Format(time.DateOnly)
+	}
+
+	return result
+}
+
+func TestParseRecurrence(t *testing.T) {
+	rule, err := parseRecurrence("rrule:byday=we,mo,we;freq=weekly;interval=2;count=4")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=4", rule.String())
+
+	rule, err = parseRecurrence("FREQ=DAILY;INTERVAL=1;UNTIL=20240131")
+	require.NoError(t, err)
+	assert.Equal(t, "FREQ=DAILY;UNTIL=20240131", rule.String())
+
+	for _, value := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=-1",
+		"FREQ=DAILY;COUNT=2;UNTIL=20240101",
+		"FREQ=DAILY;UNTIL=2024-01-01",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=MO",
+		"FREQ=DAILY;BYMONTH=1",
+		"FREQ",
+	} {
+		_, err := parseRecurrence(value)
+		assert.ErrorIs(t, err, ErrInvalidData, value)
+	}
+}
+
+func TestRecurrenceOccurrences(t *testing.T) {
+	// Monday, January 1st 2024.
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, []string{"2024-01-01", "2024-01-03", "2024-01-05"},
+		dates(expand(t, "FREQ=DAILY;INTERVAL=2;COUNT=3", start, 10)))
+	assert.Equal(t, []string{"2024-01-01", "2024-01-02", "2024-01-08", "2024-01-09"},
+		dates(expand(t, "FREQ=DAILY;BYDAY=MO,TU;UNTIL=20240109", start, 10)))
+	assert.Equal(t, []string{"2024-01-01", "2024-01-03", "2024-01-15", "2024-01-17"},
+		dates(expand(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", start, 4)))
+	assert.Equal(t, []string{"2024-01-01", "2024-01-08"},
+		dates(expand(t, "FREQ=WEEKLY;UNTIL=20240108T090000Z", start, 10)))
+	assert.Equal(t, []string{"2024-01-01", "2025-01-01"},
+		dates(expand(t, "FREQ=YEARLY", start, 2)))
+}
+
+func TestRecurrenceOccurrences_SkipsMissingDays(t *testing.T) {
+	endOfMonth := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2024-01-31", "2024-03-31", "2024-05-31"},
+		dates(expand(t, "FREQ=MONTHLY", endOfMonth, 3)))
+
+	leapDay := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, []string{"2024-02-29", "2028-02-29"},
+		dates(expand(t, "FREQ=YEARLY", leapDay, 2)))
+}
+
+func TestRecurrenceOccurrences_KeepsLocalTimeAcrossDST(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	start := time.Date(2024, 3, 30, 9, 0, 0, 0, berlin)
+
+	result := expand(t, "FREQ=DAILY", start, 2)
+	assert.Equal(t, 9, result[1].Hour())
+	assert.Equal(t, 23*time.Hour, result[1].Sub(result[0]))
+}
+
+func TestUpdateTask_CompletingRecurringTaskCreatesNext(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+	ctx := WithLocation(testUserContext, time.UTC)
+
+	dueAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	task := &entity.Task{Title: "Standup", DueAt: dueAt, Completed: true, Tags: []string{"work"}, Recurrence: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3"}
+
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(&entity.Task{ID: 1, Title: "Standup", DueAt: dueAt}, nil)
+	mockRepo.On("CountOpenSubtasks", mock.Anything, 1, 1).Return(0, nil)
+	mockRepo.On("CompleteOccurrence", mock.Anything, 1, 1, mock.MatchedBy(func(task *entity.Task) bool {
+		return task.Completed && task.Recurrence == ""
+	}), mock.MatchedBy(func(next *entity.Task) bool {
+		return !next.Completed && next.Title == "Standup" && next.DueAt.Equal(time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)) &&
+			next.Recurrence == "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=2" && assert.ObjectsAreEqual([]string{"work"}, next.Tags)
+	})).Return(int64(2), nil)
+
+	err := service.UpdateTask(ctx, 1, task, false)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateTask_CompletingLastOccurrence(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+	ctx := WithLocation(testUserContext, time.UTC)
+
+	task := &entity.Task{Title: "Standup", DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), Completed: true, Recurrence: "FREQ=DAILY;COUNT=1"}
+
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(&entity.Task{ID: 1}, nil)
+	mockRepo.On("CountOpenSubtasks", mock.Anything, 1, 1).Return(0, nil)
+	mockRepo.On("UpdateTask", mock.Anything, 1, 1, task).Return(nil)
+
+	err := service.UpdateTask(ctx, 1, task, false)
+	assert.NoError(t, err)
+	mockRepo.AssertNotCalled(t, "CompleteOccurrence", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateTask_CompletingRecurringTaskExpectsVersionRead(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+	ctx := WithLocation(testUserContext, time.UTC)
+
+	dueAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	task := &entity.Task{Title: "Standup", DueAt: dueAt, Completed: true, Recurrence: "FREQ=DAILY"}
+
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(&entity.Task{ID: 1, Title: "Standup", DueAt: dueAt, Version: 4}, nil)
+	mockRepo.On("CountOpenSubtasks", mock.Anything, 1, 1).Return(0, nil)
+	// Another completion got in between the read and the write.
+	mockRepo.On("CompleteOccurrence", mock.Anything, 1, 1, mock.MatchedBy(func(task *entity.Task) bool {
+		return task.Version == 4
+	}), mock.Anything).Return(int64(-1), ErrVersionMismatch)
+
+	err := service.UpdateTask(ctx, 1, task, false)
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateTask_AlreadyCompletedDoesNotRepeat(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	task := &entity.Task{Title: "Standup", DueAt: time.Now(), Completed: true, Recurrence: "FREQ=DAILY"}
+
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(&entity.Task{ID: 1, Completed: true}, nil)
+	mockRepo.On("UpdateTask", mock.Anything, 1, 1, task).Return(nil)
+
+	err := service.UpdateTask(testUserContext, 1, task, false)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTask_InvalidRecurrence(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	_, err := service.CreateTask(testUserContext, &entity.Task{Title: "Test Task", DueAt: time.Now(), Recurrence: "FREQ=SECONDLY"})
+	assert.ErrorIs(t, err, ErrInvalidData)
+	mockRepo.AssertNotCalled(t, "InsertTask", mock.Anything, mock.Anything)
+}
+
+func TestGetOccurrences(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+	service.now = func() time.Time { return time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC) }
+	ctx := WithLocation(testUserContext, time.UTC)
+
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(&entity.Task{ID: 1, DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), Recurrence: "FREQ=WEEKLY"}, nil)
+	mockRepo.On("GetTask", mock.Anything, 1, 2).Return(&entity.Task{ID: 2, DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}, nil)
+
+	result, err := service.GetOccurrences(ctx, 1, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2024-01-15", "2024-01-22", "2024-01-29", "2024-02-05"}, dates(result))
+
+	result, err = service.GetOccurrences(ctx, 1, "2024-01-01", "2024-01-08")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2024-01-01", "2024-01-08"}, dates(result))
+
+	result, err = service.GetOccurrences(ctx, 2, "", "")
+	assert.NoError(t, err)
+	assert.Empty(t, result)
+
+	_, err = service.GetOccurrences(ctx, 1, "2024-02-01", "2024-01-01")
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// maxSearchTerms bounds the words of a search query.
+const maxSearchTerms = 16
+
+// normalizeQuery reduces a search query to its distinct lowercase words,
+// separated by single spaces. Punctuation only separates words, so no query
+// syntax of a storage engine can leak through.
+func normalizeQuery(query string) (string, error) {
+	if strings.TrimSpace(query) == "" {
+		return "", nil
+	}
+	if len(query) > maxNameLength {
+		return "", fmt.Errorf("%w: q is too long", ErrInvalidData)
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var terms []string
+	for _, word := range words {
+		if !slices.Contains(terms, word) {
+			terms = append(terms, word)
+		}
+	}
+
+	if len(terms) == 0 {
+		return "", fmt.Errorf("%w: q has no words to search for", ErrInvalidData)
+	}
+	if len(terms) > maxSearchTerms {
+		return "", fmt.Errorf("%w: q has more than %d words", ErrInvalidData, maxSearchTerms)
+	}
+
+	return strings.Join(terms, " "), nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	query, err := normalizeQuery("  Groceries, MILK & groceries:* ")
+	assert.NoError(t, err)
+	assert.Equal(t, "groceries milk", query)
+
+	query, err = normalizeQuery("Überweisung 2024")
+	assert.NoError(t, err)
+	assert.Equal(t, "überweisung 2024", query)
+
+	query, err = normalizeQuery("   ")
+	assert.NoError(t, err)
+	assert.Empty(t, query)
+
+	_, err = normalizeQuery("&& !!")
+	assert.ErrorIs(t, err, ErrInvalidData)
+
+	words := make([]string, maxSearchTerms+1)
+	for i := range words {
+		words[i] = fmt.Sprintf("w%d", i)
+	}
+	_, err = normalizeQuery(strings.Join(words, " "))
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
+
+func TestGetTaskList_Query(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	mockRepo.On("GetTaskList", mock.Anything, entity.TaskFilter{OwnerID: 1, Query: "buy milk", Limit: 11}).Return([]*entity.Task{}, nil)
+
+	_, err := service.GetTaskList(testUserContext, entity.TaskFilter{Query: "Buy 'milk'", Limit: 10})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -1,19 +1,130 @@
 package service
 
-import "todo-list/internal/entity"
+import (
+	"context"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+)
 
 type Service struct {
 	TaskRepository
+	UserRepository
+	ProjectRepository
+	TagRepository
+	jwtSecret []byte
+	cursorKey []byte
+	tokenTTL  time.Duration
+	now       func() time.Time
+	// requireVersion makes writes to a task name the version they replace.
+	requireVersion bool
+	// trashRetention is how long deleted tasks stay in the trash.
+	trashRetention time.Duration
 }
 
-func NewService(repo TaskRepository) *Service {
-	return &Service{repo}
+func NewService(tasks TaskRepository, users UserRepository, projects ProjectRepository, tags TagRepository, cfg *configs.Config) *Service {
+	return &Service{
+		TaskRepository:    tasks,
+		UserRepository:    users,
+		ProjectRepository: projects,
+		TagRepository:     tags,
+		jwtSecret:         []byte(cfg.JWTSecret),
+		cursorKey:         deriveCursorKey(cfg.JWTSecret),
+		tokenTTL:          cfg.TokenTTL,
+		now:               time.Now,
+		requireVersion:    cfg.RequireIfMatch,
+		trashRetention:    cfg.TrashRetention,
+	}
 }
 
+// Repository is implemented by every storage driver.
+type Repository interface {
+	TaskRepository
+	UserRepository
+	ProjectRepository
+	TagRepository
+}
+
+// TaskRepository stores tasks. Every write to a task also records a
+// TaskEvent in the same transaction, naming the user in ctx as the actor, or
+// the owner when there is none, and keeping a snapshot of the task as written.
 type TaskRepository interface {
-	InsertTask(task *entity.Task) (int64, error)
-	GetTask(id int) (*entity.Task, error)
-	UpdateTask(id int, task *entity.Task) error
-	DeleteTask(id int) error
-	GetTaskList(offset int, completed string, pagesize int, date string) ([]*entity.Task, error)
+	InsertTask(ctx context.Context, task *entity.Task) (int64, error)
+	GetTask(ctx context.Context, ownerID int, id int) (*entity.Task, error)
+	// UpdateTask saves task and sets task.Version to the task's new version.
+	// When task.Version is not 0 the stored task must be at that version,
+	// otherwise nothing is written and ErrVersionMismatch is returned.
+	UpdateTask(ctx context.Context, ownerID int, id int, task *entity.Task) error
+	// PatchTask writes only the named fields of task, by their JSON name, in
+	// a single statement for the tasks table. It checks and sets
+	// task.Version like UpdateTask.
+	PatchTask(ctx context.Context, ownerID int, id int, task *entity.Task, fields []string) error
+	// CompleteOccurrence saves task and inserts next, the following occurrence
+	// of its series, in one transaction. It returns the id of next, and checks
+	// and sets task.Version like UpdateTask.
+	CompleteOccurrence(ctx context.Context, ownerID int, id int, task *entity.Task, next *entity.Task) (int64, error)
+	// DeleteTask moves a task and its subtasks to the trash, provided it is
+	// at version unless version is 0. Every other method but the trash ones
+	// treats tasks in the trash as gone.
+	DeleteTask(ctx context.Context, ownerID int, id int, version int) error
+	// GetTrash returns the owner's tasks in the trash, most recently deleted
+	// first, with DeletedAt set.
+	GetTrash(ctx context.Context, ownerID int) ([]*entity.Task, error)
+	// RestoreTask takes a task out of the trash together with the subtasks
+	// deleted along with it.
+	RestoreTask(ctx context.Context, ownerID int, id int) error
+	// GetTaskHistory returns up to limit events of one of the owner's tasks,
+	// newest first, starting below event before unless that is 0. Tasks in
+	// the trash have their history too.
+	GetTaskHistory(ctx context.Context, ownerID int, id int, before int, limit int) ([]*entity.TaskEvent, error)
+	// GetTaskVersion returns one of the owner's tasks as it was at version,
+	// or ErrVersionNotFound when its history keeps no such snapshot.
+	GetTaskVersion(ctx context.Context, ownerID int, id int, version int) (*entity.Task, error)
+	// GetTaskAsOf returns one of the owner's tasks as its last write up to at
+	// left it, or ErrVersionNotFound when it did not exist then or was in the
+	// trash.
+	GetTaskAsOf(ctx context.Context, ownerID int, id int, at time.Time) (*entity.Task, error)
+	// Transaction runs fn with a Repository whose reads and writes all go
+	// through one transaction, committed when fn returns nil and rolled back
+	// otherwise. A write failing within it undoes only itself.
+	Transaction(ctx context.Context, fn func(repo Repository) error) error
+	// PurgeTasks permanently removes every task deleted before before and
+	// returns how many there were.
+	PurgeTasks(ctx context.Context, before time.Time) (int64, error)
+	// GetTaskList returns the owner's tasks matching filter, in list order
+	// even when reading backwards from filter.Keyset.
+	GetTaskList(ctx context.Context, filter entity.TaskFilter) ([]*entity.Task, error)
+	// GetSubtree returns a task followed by all of its descendants.
+	GetSubtree(ctx context.Context, ownerID int, id int) ([]*entity.Task, error)
+	// CountOpenSubtasks counts the descendants of a task not yet completed.
+	CountOpenSubtasks(ctx context.Context, ownerID int, id int) (int, error)
+	SetTaskParent(ctx context.Context, ownerID int, id int, parentID *int) error
+	// GetDependencies returns every dependency among the owner's tasks.
+	GetDependencies(ctx context.Context, ownerID int) ([]entity.Dependency, error)
+}
+
+type UserRepository interface {
+	InsertUser(ctx context.Context, user *entity.User) (int64, error)
+	GetUserByUsername(ctx context.Context, username string) (*entity.User, error)
+	GetUser(ctx context.Context, id int) (*entity.User, error)
+	UpdateUserTimeZone(ctx context.Context, id int, timeZone string) error
+}
+
+type ProjectRepository interface {
+	InsertProject(ctx context.Context, project *entity.Project) (int64, error)
+	GetProject(ctx context.Context, ownerID int, id int) (*entity.Project, error)
+	GetProjectList(ctx context.Context, ownerID int) ([]*entity.Project, error)
+	UpdateProject(ctx context.Context, ownerID int, id int, project *entity.Project) error
+	// DeleteProject removes a project. Its tasks are moved to moveTasksTo, or
+	// deleted with it when moveTasksTo is 0.
+	DeleteProject(ctx context.Context, ownerID int, id int, moveTasksTo int) error
+	// EnsureInboxProject returns the owner's inbox, creating it on first use.
+	EnsureInboxProject(ctx context.Context, ownerID int) (*entity.Project, error)
+}
+
+type TagRepository interface {
+	GetTagList(ctx context.Context, ownerID int) ([]*entity.Tag, error)
+	RenameTag(ctx context.Context, ownerID int, id int, name string) error
+	// MergeTag retags every task carrying id with into, then deletes id.
+	MergeTag(ctx context.Context, ownerID int, id int, into int) error
 }
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo-list/internal/entity"
+)
+
+var ErrVersionNotFound = errors.New("task version not found")
+
+// GetTaskVersion returns a task exactly as it was at version, from the
+// snapshots kept in its history. Tasks in the trash keep them too.
+func (s *Service) GetTaskVersion(ctx context.Context, id int, version int) (*entity.Task, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if id <= 0 || version <= 0 {
+		return nil, ErrInvalidData
+	}
+
+	task, err := s.TaskRepository.GetTaskVersion(ctx, user.ID, id, version)
+	return task, checkTimeout(ctx, err)
+}
+
+// GetTaskAsOf returns a task as it was at a point in time. It fails with
+// ErrVersionNotFound when the task had not been created yet, was in the trash
+// then, or was last written before snapshots were kept.
+func (s *Service) GetTaskAsOf(ctx context.Context, id int, at time.Time) (*entity.Task, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if id <= 0 || at.IsZero() {
+		return nil, ErrInvalidData
+	}
+
+	task, err := s.TaskRepository.GetTaskAsOf(ctx, user.ID, id, at)
+	return task, checkTimeout(ctx, err)
+}
+
+// RevertTask writes an earlier version of a task back as its next one. The
+// reverted task is saved through UpdateTask, so it is validated as any
+// replacement is: a project, parent or blocker gone since fails the revert,
+// and completing it is guarded the same way. expected is the version to
+// replace, as task.Version is for UpdateTask. It returns the task as saved.
+func (s *Service) RevertTask(ctx context.Context, id int, version int, expected int, force bool) (*entity.Task, error) {
+	snapshot, err := s.GetTaskVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	task := &entity.Task{
+		ProjectID:   snapshot.ProjectID,
+		ParentID:    snapshot.ParentID,
+		Title:       snapshot.Title,
+		Description: snapshot.Description,
+		DueAt:       snapshot.DueAt,
+		AllDay:      snapshot.AllDay,
+		Completed:   snapshot.Completed,
+		Priority:    snapshot.Priority,
+		Tags:        snapshot.Tags,
+		BlockedBy:   snapshot.BlockedBy,
+		Recurrence:  snapshot.Recurrence,
+		Version:     expected,
+	}
+
+	err = s.UpdateTask(ctx, id, task, force)
+	if err != nil {
+		return nil, err
+	}
+
+	task.ID = id
+	return task, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTaskAsOf(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	at := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	mockRepo.On("GetTaskAsOf", mock.Anything, 1, 5, at).Return((*entity.Task)(nil), ErrVersionNotFound)
+
+	_, err := service.GetTaskAsOf(testUserContext, 5, at)
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+
+	_, err = service.GetTaskAsOf(testUserContext, 5, time.Time{})
+	assert.ErrorIs(t, err, ErrInvalidData)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRevertTask(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	snapshot := &entity.Task{ID: 5, OwnerID: 1, Title: "Old", DueAt: due, Tags: []string{"home"}, Version: 2}
+	mockRepo.On("GetTaskVersion", mock.Anything, 1, 5, 2).Return(snapshot, nil)
+	mockRepo.On("UpdateTask", mock.Anything, 1, 5, mock.MatchedBy(func(task *entity.Task) bool {
+		return task.Title == "Old" && task.DueAt.Equal(due) && task.Version == 4
+	})).Run(func(args mock.Arguments) {
+		args.Get(3).(*entity.Task).Version = 5
+	}).Return(nil)
+
+	task, err := service.RevertTask(testUserContext, 5, 2, 4, false)
+	require.NoError(t, err)
+	assert.Equal(t, 5, task.ID)
+	assert.Equal(t, "Old", task.Title)
+	assert.Equal(t, []string{"home"}, task.Tags)
+	assert.Equal(t, 5, task.Version)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRevertTask_Missing(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	mockRepo.On("GetTaskVersion", mock.Anything, 1, 5, 9).Return((*entity.Task)(nil), ErrVersionNotFound)
+
+	_, err := service.RevertTask(testUserContext, 5, 9, 0, false)
+	assert.ErrorIs(t, err, ErrVersionNotFound)
+
+	_, err = service.RevertTask(testUserContext, 5, 0, 0, false)
+	assert.ErrorIs(t, err, ErrInvalidData)
+	mockRepo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
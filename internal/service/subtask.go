@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"todo-list/internal/entity"
+)
+
+var ErrOpenSubtasks = errors.New("task has open subtasks")
+
+// GetChildren lists the direct subtasks of a task, filtered and paged like
+// GetTaskList.
+func (s *Service) GetChildren(ctx context.Context, id int, filter entity.TaskFilter) (*entity.TaskPage, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if id <= 0 {
+		return nil, ErrInvalidData
+	}
+
+	_, err = s.TaskRepository.GetTask(ctx, user.ID, id)
+	if err != nil {
+		return nil, checkTimeout(ctx, err)
+	}
+
+	filter.ParentID = id
+	return s.GetTaskList(ctx, filter)
+}
+
+// GetSubtree returns a task followed by all of its descendants, which carry
+// parent_id to rebuild the tree from.
+func (s *Service) GetSubtree(ctx context.Context, id int) ([]*entity.Task, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if id <= 0 {
+		return nil, ErrInvalidData
+	}
+
+	tasks, err := s.TaskRepository.GetSubtree(ctx, user.ID, id)
+	return tasks, checkTimeout(ctx, err)
+}
+
+// MoveTask reparents a task under parentID, or makes it a top-level task when
+// parentID is nil.
+func (s *Service) MoveTask(ctx context.Context, id int, parentID *int) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if id <= 0 {
+		return ErrInvalidData
+	}
+
+	err = s.checkParent(ctx, user.ID, id, parentID)
+	if err != nil {
+		return checkTimeout(ctx, err)
+	}
+
+	return checkTimeout(ctx, s.TaskRepository.SetTaskParent(ctx, user.ID, id, parentID))
+}
+
+// checkParent makes sure task id may hang under parentID: the parent must be
+// another task of the same owner and must not sit in id's own subtree. id is
+// 0 for a task not created yet.
+func (s *Service) checkParent(ctx context.Context, ownerID int, id int, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+	if *parentID == id {
+		return fmt.Errorf("%w: a task cannot be its own parent", ErrInvalidData)
+	}
+
+	_, err := s.TaskRepository.GetTask(ctx, ownerID, *parentID)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("%w: unknown parent task %d", ErrInvalidData, *parentID)
+	}
+	if err != nil || id == 0 {
+		return err
+	}
+
+	subtree, err := s.TaskRepository.GetSubtree(ctx, ownerID, id)
+	if err != nil {
+		return err
+	}
+	for _, task := range subtree {
+		if task.ID == *parentID {
+			return fmt.Errorf("%w: task %d is a subtask of %d", ErrInvalidData, *parentID, id)
+		}
+	}
+
+	return nil
+}
+
+// completeTask saves a task that is to be completed, all of it or only the
+// named fields as saveTask does. Closing a task that was open is refused while
+// one of its blockers is open, or while it has open subtasks unless force is
+// set, and moves a recurring task's series on to its next occurrence. The
+// write expects the version read here, so that of two racing completions one
+// fails with ErrVersionMismatch rather than both moving the series on.
+func (s *Service) completeTask(ctx context.Context, user *entity.User, id int, task *entity.Task, rule *recurrence, force bool, fields []string) error {
+	current, err := s.TaskRepository.GetTask(ctx, user.ID, id)
+	if err != nil {
+		return err
+	}
+	if task.Version == 0 {
+		task.Version = current.Version
+	}
+	if current.Completed {
+		return s.saveTask(ctx, user.ID, id, task, fields)
+	}
+
+	blockers, err := s.countOpenBlockers(ctx, user.ID, task.BlockedBy)
+	if err != nil {
+		return err
+	}
+	if blockers > 0 {
+		return fmt.Errorf("%w: %d blocking tasks still open", ErrBlocked, blockers)
+	}
+
+	if !force {
+		open, err := s.TaskRepository.CountOpenSubtasks(ctx, user.ID, id)
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return fmt.Errorf("%w: %d still open, pass force=true to complete anyway", ErrOpenSubtasks, open)
+		}
+	}
+
+	if rule != nil {
+		return s.completeOccurrence(ctx, user, id, task, rule)
+	}
+
+	return s.saveTask(ctx, user.ID, id, task, fields)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
+
+func TestMoveTask(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	mockRepo.On("GetTask", mock.Anything, 1, 2).Return(&entity.Task{ID: 2}, nil)
+	mockRepo.On("GetSubtree", mock.Anything, 1, 1).Return([]*entity.Task{{ID: 1}, {ID: 3}}, nil)
+	mockRepo.On("SetTaskParent", mock.Anything, 1, 1, intPtr(2)).Return(nil)
+	mockRepo.On("SetTaskParent", mock.Anything, 1, 1, (*int)(nil)).Return(nil)
+
+	assert.NoError(t, service.MoveTask(testUserContext, 1, intPtr(2)))
+	assert.NoError(t, service.MoveTask(testUserContext, 1, nil))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMoveTask_RejectsCycles(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	mockRepo.On("GetTask", mock.Anything, 1, 3).Return(&entity.Task{ID: 3, ParentID: intPtr(1)}, nil)
+	mockRepo.On("GetTask", mock.Anything, 1, 9).Return((*entity.Task)(nil), ErrNotFound)
+	mockRepo.On("GetSubtree", mock.Anything, 1, 1).Return([]*entity.Task{{ID: 1}, {ID: 3, ParentID: intPtr(1)}}, nil)
+
+	assert.ErrorIs(t, service.MoveTask(testUserContext, 1, intPtr(1)), ErrInvalidData)
+	assert.ErrorIs(t, service.MoveTask(testUserContext, 1, intPtr(3)), ErrInvalidData)
+	assert.ErrorIs(t, service.MoveTask(testUserContext, 1, intPtr(9)), ErrInvalidData)
+	mockRepo.AssertNotCalled(t, "SetTaskParent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateTask_OpenSubtasks(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	task := &entity.Task{Title: "Parent", DueAt: time.Now(), Completed: true}
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(&entity.Task{ID: 1}, nil)
+	mockRepo.On("CountOpenSubtasks", mock.Anything, 1, 1).Return(2, nil)
+	mockRepo.On("UpdateTask", mock.Anything, 1, 1, task).Return(nil).Once()
+
+	err := service.UpdateTask(testUserContext, 1, task, false)
+	assert.ErrorIs(t, err, ErrOpenSubtasks)
+	mockRepo.AssertNotCalled(t, "UpdateTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	err = service.UpdateTask(testUserContext, 1, task, true)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetChildren(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	children := []*entity.Task{{ID: 2, ParentID: intPtr(1)}}
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(&entity.Task{ID: 1}, nil)
+	mockRepo.On("GetTask", mock.Anything, 1, 5).Return((*entity.Task)(nil), ErrNotFound)
+	mockRepo.On("GetTaskList", mock.Anything, entity.TaskFilter{OwnerID: 1, ParentID: 1, Limit: 11}).Return(children, nil)
+
+	result, err := service.GetChildren(testUserContext, 1, entity.TaskFilter{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, children, result.Tasks)
+
+	_, err = service.GetChildren(testUserContext, 5, entity.TaskFilter{Limit: 10})
+	assert.ErrorIs(t, err, ErrNotFound)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"todo-list/internal/entity"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag already exists")
+)
+
+func (s *Service) GetTagList(ctx context.Context) ([]*entity.Tag, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.TagRepository.GetTagList(ctx, user.ID)
+	return tags, checkTimeout(ctx, err)
+}
+
+// RenameTag renames a tag on every task carrying it. Renaming to the name
+// of another tag is refused with ErrTagExists; MergeTag covers that case.
+func (s *Service) RenameTag(ctx context.Context, id int, name string) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if id <= 0 {
+		return ErrInvalidData
+	}
+	name = strings.TrimSpace(name)
+	err = checkTagName(name)
+	if err != nil {
+		return err
+	}
+
+	return checkTimeout(ctx, s.TagRepository.RenameTag(ctx, user.ID, id, name))
+}
+
+// MergeTag moves every task tagged id over to the tag into and deletes id.
+func (s *Service) MergeTag(ctx context.Context, id int, into int) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if id <= 0 || into <= 0 {
+		return ErrInvalidData
+	}
+	if id == into {
+		return fmt.Errorf("%w: a tag cannot be merged into itself", ErrInvalidData)
+	}
+
+	return checkTimeout(ctx, s.TagRepository.MergeTag(ctx, user.ID, id, into))
+}
+
+// normalizeTags trims tag names, drops duplicates and sorts them, so every
+// repository stores and compares the same set.
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		err := checkTagName(tag)
+		if err != nil {
+			return nil, err
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// checkTagName makes sure a trimmed tag name is usable. Commas are refused, as
+// a CSV cell lists tags comma-separated.
+func checkTagName(name string) error {
+	if name == "" || len(name) > maxNameLength {
+		return fmt.Errorf("%w: invalid tag %q", ErrInvalidData, name)
+	}
+	if strings.Contains(name, ",") {
+		return fmt.Errorf("%w: tag %q contains a comma", ErrInvalidData, name)
+	}
+
+	return nil
+}
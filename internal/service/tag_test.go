@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTagRepository struct {
+	mock.Mock
+}
+
+func (m *MockTagRepository) GetTagList(ctx context.Context, ownerID int) ([]*entity.Tag, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).([]*entity.Tag), args.Error(1)
+}
+
+func (m *MockTagRepository) RenameTag(ctx context.Context, ownerID int, id int, name string) error {
+	args := m.Called(ctx, ownerID, id, name)
+	return args.Error(0)
+}
+
+func (m *MockTagRepository) MergeTag(ctx context.Context, ownerID int, id int, into int) error {
+	args := m.Called(ctx, ownerID, id, into)
+	return args.Error(0)
+}
+
+func TestRenameTag(t *testing.T) {
+	mockRepo := new(MockTagRepository)
+	service := NewService(nil, nil, nil, mockRepo, &configs.Config{})
+
+	mockRepo.On("RenameTag", mock.Anything, 1, 2, "errands").Return(nil)
+
+	assert.NoError(t, service.RenameTag(testUserContext, 2, " errands "))
+	assert.ErrorIs(t, service.RenameTag(testUserContext, 2, " "), ErrInvalidData)
+	assert.ErrorIs(t, service.RenameTag(testUserContext, 2, "home,work"), ErrInvalidData)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestMergeTag_IntoItself(t *testing.T) {
+	service := NewService(nil, nil, nil, new(MockTagRepository), &configs.Config{})
+
+	assert.ErrorIs(t, service.MergeTag(testUserContext, 2, 2), ErrInvalidData)
+}
+
+func TestCreateTask_NormalizesTags(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	mockRepo.On("InsertTask", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+		return assert.ObjectsAreEqual([]string{"home", "urgent"}, task.Tags)
+	})).Return(int64(1), nil)
+
+	_, err := service.CreateTask(testUserContext, &entity.Task{Title: "Test Task", DueAt: time.Now(), Tags: []string{"urgent ", "home", "urgent"}})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	_, err = service.CreateTask(testUserContext, &entity.Task{Title: "Test Task", DueAt: time.Now(), Tags: []string{""}})
+	assert.ErrorIs(t, err, ErrInvalidData)
+	_, err = service.CreateTask(testUserContext, &entity.Task{Title: "Test Task", DueAt: time.Now(), Tags: []string{"home,work"}})
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
+
+func TestGetTaskList_TagFilters(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	mockRepo.On("GetTaskList", mock.Anything, entity.TaskFilter{OwnerID: 1, Tags: []string{"home", "work"}, TagMatch: entity.TagMatchAll, Limit: 11}).Return([]*entity.Task{}, nil)
+
+	_, err := service.GetTaskList(testUserContext, entity.TaskFilter{Tags: []string{"work", "home"}, TagMatch: entity.TagMatchAll, Limit: 10})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	_, err = service.GetTaskList(testUserContext, entity.TaskFilter{Tags: []string{"home"}, TagMatch: "some", Limit: 10})
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
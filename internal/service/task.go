@@ -1,48 +1,369 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"time"
 	"todo-list/internal/entity"
 )
 
-var ErrInvalidData = errors.New("invalid data")
+var (
+	ErrInvalidData = errors.New("invalid data")
+	ErrNotFound    = errors.New("task not found")
+	ErrTimeout     = errors.New("request timed out")
+)
+
+func (s *Service) CreateTask(ctx context.Context, task *entity.Task) (int64, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return -1, err
+	}
 
-func (s *Service) CreateTask(task *entity.Task) (int64, error) {
-	if task.Title == "" || task.Date.IsZero() {
+	if task.Title == "" || task.DueAt.IsZero() || !validPriority(task.Priority) {
 		return -1, ErrInvalidData
 	}
 
-	return s.TaskRepository.InsertTask(task)
+	err = s.normalizeDueAt(ctx, user, task)
+	if err != nil {
+		return -1, checkTimeout(ctx, err)
+	}
+
+	_, err = s.checkTask(ctx, user, 0, task)
+	if err != nil {
+		return -1, checkTimeout(ctx, err)
+	}
+
+	task.OwnerID = user.ID
+	id, err := s.TaskRepository.InsertTask(ctx, task)
+	return id, checkTimeout(ctx, err)
 }
 
-func (s *Service) GetTask(id int) (*entity.Task, error) {
+func (s *Service) GetTask(ctx context.Context, id int) (*entity.Task, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	if id <= 0 {
 		return nil, ErrInvalidData
 	}
 
-	return s.TaskRepository.GetTask(id)
+	task, err := s.TaskRepository.GetTask(ctx, user.ID, id)
+	return task, checkTimeout(ctx, err)
 }
 
-func (s *Service) UpdateTask(id int, task *entity.Task) error {
-	if task.Title == "" || task.Date.IsZero() || id <= 0 {
+// UpdateTask replaces a task. Completing a task with open subtasks fails with
+// ErrOpenSubtasks unless force is set; completing one with open blockers
+// always fails with ErrBlocked. task.Version is the version to replace, 0
+// for whichever is stored, and is set to the new version once saved.
+func (s *Service) UpdateTask(ctx context.Context, id int, task *entity.Task, force bool) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	task.Version, err = s.expectedVersion(task.Version)
+	if err != nil {
+		return err
+	}
+
+	if task.Title == "" || task.DueAt.IsZero() || !validPriority(task.Priority) || id <= 0 {
 		return ErrInvalidData
 	}
 
-	return s.TaskRepository.UpdateTask(id, task)
+	err = s.normalizeDueAt(ctx, user, task)
+	if err != nil {
+		return checkTimeout(ctx, err)
+	}
+
+	rule, err := s.checkTask(ctx, user, id, task)
+	if err != nil {
+		return checkTimeout(ctx, err)
+	}
+
+	task.OwnerID = user.ID
+	if task.Completed {
+		return checkTimeout(ctx, s.completeTask(ctx, user, id, task, rule, force, nil))
+	}
+
+	return checkTimeout(ctx, s.TaskRepository.UpdateTask(ctx, user.ID, id, task))
+}
+
+// checkTask validates a task about to be saved as id, or created when id is
+// 0, and normalizes its recurrence, tags and blockers. It returns the task's
+// recurrence rule, nil for a one-off task.
+func (s *Service) checkTask(ctx context.Context, user *entity.User, id int, task *entity.Task) (*recurrence, error) {
+	rule, err := normalizeRecurrence(task)
+	if err != nil {
+		return nil, err
+	}
+
+	task.Tags, err = normalizeTags(task.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkProject(ctx, user.ID, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkParent(ctx, user.ID, id, task.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	task.BlockedBy, err = normalizeBlockers(id, task.BlockedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkBlockers(ctx, user.ID, id, task.BlockedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return rule, nil
 }
 
-func (s *Service) DeleteTask(id int) error {
+func (s *Service) DeleteTask(ctx context.Context, id int, version int) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	version, err = s.expectedVersion(version)
+	if err != nil {
+		return err
+	}
+
 	if id <= 0 {
 		return ErrInvalidData
 	}
 
-	return s.TaskRepository.DeleteTask(id)
+	return checkTimeout(ctx, s.TaskRepository.DeleteTask(ctx, user.ID, id, version))
 }
 
-func (s *Service) GetTaskList(offset int, completed string, pagesize int, date string) ([]*entity.Task, error) {
-	if offset < 0 || pagesize <= 0 {
+// GetTaskList returns a page of the caller's tasks. A cursor of an earlier
+// page continues the list either way; Offset still serves page numbers.
+func (s *Service) GetTaskList(ctx context.Context, filter entity.TaskFilter) (*entity.TaskPage, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.Offset < 0 || filter.Limit <= 0 {
 		return nil, ErrInvalidData
 	}
+	if filter.Limit > maxPageSize {
+		return nil, fmt.Errorf("%w: limit must be at most %d", ErrInvalidData, maxPageSize)
+	}
+
+	err = s.resolveFilter(ctx, user, &filter)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Keyset = nil
+	if filter.Cursor != "" {
+		if filter.Offset > 0 {
+			return nil, fmt.Errorf("%w: cursor cannot be combined with page", ErrInvalidData)
+		}
+
+		filter.Keyset, err = s.decodeCursor(filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+	tasks, err := s.TaskRepository.GetTaskList(ctx, filter)
+	if err != nil {
+		return nil, checkTimeout(ctx, err)
+	}
+
+	return s.taskPage(filter, tasks, limit)
+}
+
+// resolveFilter checks the filters of a task list, leaving paging aside, and
+// resolves them into what repositories filter on for user.
+func (s *Service) resolveFilter(ctx context.Context, user *entity.User, filter *entity.TaskFilter) error {
+	if filter.ProjectID < 0 {
+		return ErrInvalidData
+	}
+	if filter.Overdue != nil && *filter.Overdue {
+		if filter.Completed != nil && *filter.Completed {
+			return fmt.Errorf("%w: overdue tasks are never completed", ErrInvalidData)
+		}
+		if filter.DueWithin > 0 {
+			return fmt.Errorf("%w: overdue cannot be combined with due_within", ErrInvalidData)
+		}
+	}
+	if filter.DueWithin < 0 || filter.DueWithin > maxDueWithin {
+		return fmt.Errorf("%w: due_within must be positive and at most %d days", ErrInvalidData, maxDueWithin/(24*time.Hour))
+	}
+	if filter.Overdue != nil || filter.DueWithin > 0 {
+		filter.Now = s.now()
+	}
+
+	err := s.resolveDueRange(ctx, user, filter)
+	if err != nil {
+		return checkTimeout(ctx, err)
+	}
+
+	filter.Tags, err = normalizeTags(filter.Tags)
+	if err != nil {
+		return err
+	}
+
+	filter.Query, err = normalizeQuery(filter.Query)
+	if err != nil {
+		return err
+	}
+
+	switch filter.TagMatch {
+	case "", entity.TagMatchAny, entity.TagMatchAll:
+	default:
+		return fmt.Errorf("%w: tag_match must be %q or %q", ErrInvalidData, entity.TagMatchAny, entity.TagMatchAll)
+	}
+
+	filter.Sort = slices.Clone(filter.Sort)
+	for i, field := range filter.Sort {
+		if alias, ok := sortAliases[field.Field]; ok {
+			filter.Sort[i].Field = alias
+			field.Field = alias
+		}
+		if !sortableFields[field.Field] {
+			return fmt.Errorf("%w: cannot sort by %q", ErrInvalidData, field.Field)
+		}
+	}
+
+	filter.OwnerID = user.ID
+	return nil
+}
+
+// maxDueWithin bounds the span of GetTaskList's DueWithin filter.
+const maxDueWithin = 366 * 24 * time.Hour
+
+// sortableFields are the task fields GetTaskList can order by.
+var sortableFields = map[string]bool{
+	"id":        true,
+	"title":     true,
+	"due_at":    true,
+	"priority":  true,
+	"completed": true,
+}
+
+// sortAliases are former names of sortable fields, still accepted for
+// clients written against them.
+var sortAliases = map[string]string{
+	"date": "due_at",
+}
+
+func validPriority(priority entity.Priority) bool {
+	return priority >= entity.PriorityNone && priority <= entity.PriorityUrgent
+}
+
+// normalizeDueAt pins an all-day task to midnight of its day in the caller's
+// time zone, so "today" filters find it wherever the client sent it from.
+func (s *Service) normalizeDueAt(ctx context.Context, user *entity.User, task *entity.Task) error {
+	if !task.AllDay {
+		return nil
+	}
+
+	loc, err := s.location(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	year, month, day := task.DueAt.Date()
+	task.DueAt = time.Date(year, month, day, 0, 0, 0, 0, loc)
+
+	return nil
+}
+
+// resolveDueRange turns the day filters of filter into its due_at range,
+// reading the days in the caller's time zone.
+func (s *Service) resolveDueRange(ctx context.Context, user *entity.User, filter *entity.TaskFilter) error {
+	if filter.Date == "" && filter.From == "" && filter.To == "" {
+		return nil
+	}
+	if filter.Date != "" && (filter.From != "" || filter.To != "") {
+		return fmt.Errorf("%w: date cannot be combined with from or to", ErrInvalidData)
+	}
+
+	loc, err := s.location(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	if filter.Date != "" {
+		day, err := s.parseDay("date", filter.Date, loc)
+		if err != nil {
+			return err
+		}
+		filter.DueFrom, filter.DueTo = day, day.AddDate(0, 0, 1)
+	}
+
+	if filter.From != "" {
+		filter.DueFrom, err = s.parseDay("from", filter.From, loc)
+		if err != nil {
+			return err
+		}
+	}
+
+	if filter.To != "" {
+		day, err := s.parseDay("to", filter.To, loc)
+		if err != nil {
+			return err
+		}
+		filter.DueTo = day.AddDate(0, 0, 1)
+	}
+
+	if !filter.DueFrom.IsZero() && !filter.DueTo.IsZero() && !filter.DueFrom.Before(filter.DueTo) {
+		return fmt.Errorf("%w: from must not be after to", ErrInvalidData)
+	}
+
+	filter.Date, filter.From, filter.To = "", "", ""
+	return nil
+}
+
+// parseDay returns midnight in loc of a day given as "today", YYYY-MM-DD or
+// an RFC 3339 timestamp, whose own calendar day is used. name is the
+// parameter the day came in, for the error.
+func (s *Service) parseDay(name, value string, loc *time.Location) (time.Time, error) {
+	var t time.Time
+	var err error
+	switch value {
+	case "today":
+		t = s.now().In(loc)
+	default:
+		t, err = time.Parse(time.DateOnly, value)
+		if err != nil {
+			t, err = time.Parse(time.RFC3339, value)
+		}
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: invalid %s %q, use YYYY-MM-DD or today", ErrInvalidData, name, value)
+		}
+	}
+
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, loc), nil
+}
+
+// checkTimeout replaces a repository error with ErrTimeout when the request
+// deadline has passed, since drivers report a cancelled query in their own way.
+func checkTimeout(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return ErrTimeout
+	}
 
-	return s.TaskRepository.GetTaskList(offset, completed, pagesize, date)
+	return err
 }
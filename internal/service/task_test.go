@@ -1,10 +1,13 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"testing"
 	"time"
+	"todo-list/configs"
 	"todo-list/internal/entity"
 )
 
@@ -12,43 +15,116 @@ type MockTaskRepository struct {
 	mock.Mock
 }
 
-func (m *MockTaskRepository) InsertTask(task *entity.Task) (int64, error) {
-	args := m.Called(task)
+func (m *MockTaskRepository) InsertTask(ctx context.Context, task *entity.Task) (int64, error) {
+	args := m.Called(ctx, task)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockTaskRepository) GetTask(id int) (*entity.Task, error) {
-	args := m.Called(id)
+func (m *MockTaskRepository) GetTask(ctx context.Context, ownerID int, id int) (*entity.Task, error) {
+	args := m.Called(ctx, ownerID, id)
 	return args.Get(0).(*entity.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) UpdateTask(id int, task *entity.Task) error {
-	args := m.Called(id, task)
+func (m *MockTaskRepository) UpdateTask(ctx context.Context, ownerID int, id int, task *entity.Task) error {
+	args := m.Called(ctx, ownerID, id, task)
 	return args.Error(0)
 }
 
-func (m *MockTaskRepository) DeleteTask(id int) error {
-	args := m.Called(id)
+func (m *MockTaskRepository) PatchTask(ctx context.Context, ownerID int, id int, task *entity.Task, fields []string) error {
+	args := m.Called(ctx, ownerID, id, task, fields)
 	return args.Error(0)
 }
 
-func (m *MockTaskRepository) GetTaskList(offset int, completed string, pagesize int, date string) ([]*entity.Task, error) {
-	args := m.Called(offset, completed, pagesize, date)
+func (m *MockTaskRepository) CompleteOccurrence(ctx context.Context, ownerID int, id int, task *entity.Task, next *entity.Task) (int64, error) {
+	args := m.Called(ctx, ownerID, id, task, next)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetSubtree(ctx context.Context, ownerID int, id int) ([]*entity.Task, error) {
+	args := m.Called(ctx, ownerID, id)
 	return args.Get(0).([]*entity.Task), args.Error(1)
 }
 
+func (m *MockTaskRepository) CountOpenSubtasks(ctx context.Context, ownerID int, id int) (int, error) {
+	args := m.Called(ctx, ownerID, id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTaskRepository) SetTaskParent(ctx context.Context, ownerID int, id int, parentID *int) error {
+	args := m.Called(ctx, ownerID, id, parentID)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetDependencies(ctx context.Context, ownerID int) ([]entity.Dependency, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).([]entity.Dependency), args.Error(1)
+}
+
+func (m *MockTaskRepository) DeleteTask(ctx context.Context, ownerID int, id int, version int) error {
+	args := m.Called(ctx, ownerID, id, version)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetTrash(ctx context.Context, ownerID int) ([]*entity.Task, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).([]*entity.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) RestoreTask(ctx context.Context, ownerID int, id int) error {
+	args := m.Called(ctx, ownerID, id)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetTaskHistory(ctx context.Context, ownerID int, id int, before int, limit int) ([]*entity.TaskEvent, error) {
+	args := m.Called(ctx, ownerID, id, before, limit)
+	return args.Get(0).([]*entity.TaskEvent), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetTaskVersion(ctx context.Context, ownerID int, id int, version int) (*entity.Task, error) {
+	args := m.Called(ctx, ownerID, id, version)
+	return args.Get(0).(*entity.Task), args.Error(1)
+}
+
+// Transaction runs fn on m itself, with no other repositories.
+func (m *MockTaskRepository) Transaction(ctx context.Context, fn func(Repository) error) error {
+	m.Called(ctx)
+	return fn(struct {
+		TaskRepository
+		UserRepository
+		ProjectRepository
+		TagRepository
+	}{TaskRepository: m})
+}
+
+func (m *MockTaskRepository) GetTaskAsOf(ctx context.Context, ownerID int, id int, at time.Time) (*entity.Task, error) {
+	args := m.Called(ctx, ownerID, id, at)
+	return args.Get(0).(*entity.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) PurgeTasks(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetTaskList(ctx context.Context, filter entity.TaskFilter) ([]*entity.Task, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*entity.Task), args.Error(1)
+}
+
+var testUserContext = WithUser(context.Background(), &entity.User{ID: 1, Username: "john"})
+
 func TestCreateTask(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
 
 	task := &entity.Task{
 		Title: "Test Task",
-		Date:  time.Now(),
+		DueAt: time.Now(),
 	}
 
-	mockRepo.On("InsertTask", task).Return(int64(1), nil)
+	mockRepo.On("InsertTask", mock.Anything, task).Return(int64(1), nil)
 
-	id, err := service.CreateTask(task)
+	id, err := service.CreateTask(testUserContext, task)
 	assert.NoError(t, err)
 	assert.Equal(t, int64(1), id)
 	mockRepo.AssertExpectations(t)
@@ -56,14 +132,14 @@ func TestCreateTask(t *testing.T) {
 
 func TestCreateTask_InvalidData(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
 
 	task := &entity.Task{
 		Title: "",
-		Date:  time.Time{},
+		DueAt: time.Time{},
 	}
 
-	id, err := service.CreateTask(task)
+	id, err := service.CreateTask(testUserContext, task)
 	assert.Error(t, err)
 	assert.Equal(t, int64(-1), id)
 	assert.Equal(t, ErrInvalidData, err)
@@ -71,17 +147,17 @@ func TestCreateTask_InvalidData(t *testing.T) {
 
 func TestGetTask(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
 
 	task := &entity.Task{
 		ID:    1,
 		Title: "Test Task",
-		Date:  time.Now(),
+		DueAt: time.Now(),
 	}
 
-	mockRepo.On("GetTask", 1).Return(task, nil)
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(task, nil)
 
-	result, err := service.GetTask(1)
+	result, err := service.GetTask(testUserContext, 1)
 	assert.NoError(t, err)
 	assert.Equal(t, task, result)
 	mockRepo.AssertExpectations(t)
@@ -89,9 +165,9 @@ func TestGetTask(t *testing.T) {
 
 func TestGetTask_InvalidID(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
 
-	result, err := service.GetTask(-1)
+	result, err := service.GetTask(testUserContext, -1)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Equal(t, ErrInvalidData, err)
@@ -99,75 +175,269 @@ func TestGetTask_InvalidID(t *testing.T) {
 
 func TestUpdateTask(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
 
 	task := &entity.Task{
 		Title: "Updated Task",
-		Date:  time.Now(),
+		DueAt: time.Now(),
 	}
 
-	mockRepo.On("UpdateTask", 1, task).Return(nil)
+	mockRepo.On("UpdateTask", mock.Anything, 1, 1, task).Return(nil)
 
-	err := service.UpdateTask(1, task)
+	err := service.UpdateTask(testUserContext, 1, task, false)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestUpdateTask_InvalidData(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
 
 	task := &entity.Task{
 		Title: "",
-		Date:  time.Time{},
+		DueAt: time.Time{},
 	}
 
-	err := service.UpdateTask(-1, task)
+	err := service.UpdateTask(testUserContext, -1, task, false)
 	assert.Error(t, err)
 	assert.Equal(t, ErrInvalidData, err)
 }
 
 func TestDeleteTask(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
 
-	mockRepo.On("DeleteTask", 1).Return(nil)
+	mockRepo.On("DeleteTask", mock.Anything, 1, 1, 0).Return(nil)
 
-	err := service.DeleteTask(1)
+	err := service.DeleteTask(testUserContext, 1, 0)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestDeleteTask_InvalidID(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
 
-	err := service.DeleteTask(-1)
+	err := service.DeleteTask(testUserContext, -1, 0)
 	assert.Error(t, err)
 	assert.Equal(t, ErrInvalidData, err)
 }
 
 func TestGetTaskList(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewService(mockRepo)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
 
 	tasks := []*entity.Task{
 		{
 			ID:    1,
 			Title: "Test Task 1",
-			Date:  time.Now(),
+			DueAt: time.Now(),
 		},
 		{
 			ID:    2,
 			Title: "Test Task 2",
-			Date:  time.Now(),
+			DueAt: time.Now(),
 		},
 	}
 
-	mockRepo.On("GetTaskList", 0, "", 10, "").Return(tasks, nil)
+	mockRepo.On("GetTaskList", mock.Anything, entity.TaskFilter{OwnerID: 1, Limit: 11}).Return(tasks, nil)
+
+	result, err := service.GetTaskList(testUserContext, entity.TaskFilter{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, &entity.TaskPage{Tasks: tasks}, result)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTask_Timeout(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	ctx, cancel := context.WithTimeout(testUserContext, 0)
+	defer cancel()
 
-	result, err := service.GetTaskList(0, "", 10, "")
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return((*entity.Task)(nil), errors.New("pq: canceling statement due to user request"))
+
+	result, err := service.GetTask(ctx, 1)
+	assert.Nil(t, result)
+	assert.Equal(t, ErrTimeout, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTaskList_NormalizesFilters(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockUsers := new(MockUserRepository)
+	service := NewService(mockRepo, mockUsers, nil, nil, &configs.Config{})
+
+	newYork, _ := time.LoadLocation("America/New_York")
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, newYork)
+
+	mockUsers.On("GetUser", mock.Anything, 1).Return(&entity.User{ID: 1, TimeZone: "America/New_York"}, nil)
+	mockRepo.On("GetTaskList", mock.Anything, mock.MatchedBy(func(filter entity.TaskFilter) bool {
+		return filter.OwnerID == 1 && *filter.Completed && filter.Date == "" &&
+			filter.DueFrom.Equal(day) && filter.DueTo.Equal(day.AddDate(0, 0, 1))
+	})).Return([]*entity.Task{}, nil)
+
+	_, err := service.GetTaskList(testUserContext, entity.TaskFilter{Completed: boolPtr(true), Date: "2020-01-01T00:00:00Z", Limit: 10})
 	assert.NoError(t, err)
-	assert.Equal(t, tasks, result)
 	mockRepo.AssertExpectations(t)
+	mockUsers.AssertExpectations(t)
+}
+
+func TestGetTaskList_TodayInRequestTimeZone(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+	// 02:00 UTC on January 2nd is still January 1st in Los Angeles.
+	service.now = func() time.Time { return time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC) }
+
+	losAngeles, _ := time.LoadLocation("America/Los_Angeles")
+	ctx := WithLocation(testUserContext, losAngeles)
+	today := time.Date(2024, 1, 1, 0, 0, 0, 0, losAngeles)
+
+	mockRepo.On("GetTaskList", mock.Anything, mock.MatchedBy(func(filter entity.TaskFilter) bool {
+		return filter.DueFrom.Equal(today) && filter.DueTo.Equal(today.AddDate(0, 0, 7))
+	})).Return([]*entity.Task{}, nil)
+
+	_, err := service.GetTaskList(ctx, entity.TaskFilter{From: "today", To: "2024-01-07", Limit: 10})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTaskList_InvalidFilters(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+	ctx := WithLocation(testUserContext, time.UTC)
+
+	_, err := service.GetTaskList(ctx, entity.TaskFilter{Date: "yesterday", Limit: 10})
+	assert.ErrorIs(t, err, ErrInvalidData)
+	assert.ErrorContains(t, err, "invalid date")
+
+	_, err = service.GetTaskList(ctx, entity.TaskFilter{From: "2024-01-02", To: "2024-01-01", Limit: 10})
+	assert.ErrorIs(t, err, ErrInvalidData)
+
+	_, err = service.GetTaskList(ctx, entity.TaskFilter{Date: "today", From: "2024-01-01", Limit: 10})
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
+
+func TestGetTaskList_Overdue(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+	now := time.Date(2024, 1, 2, 2, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	mockRepo.On("GetTaskList", mock.Anything, entity.TaskFilter{OwnerID: 1, Overdue: boolPtr(true), Now: now, Limit: 11}).Return([]*entity.Task{}, nil)
+	mockRepo.On("GetTaskList", mock.Anything, entity.TaskFilter{OwnerID: 1, Completed: boolPtr(false), DueWithin: 7 * 24 * time.Hour, Now: now, Limit: 11}).Return([]*entity.Task{}, nil)
+
+	_, err := service.GetTaskList(testUserContext, entity.TaskFilter{Overdue: boolPtr(true), Limit: 10})
+	assert.NoError(t, err)
+	_, err = service.GetTaskList(testUserContext, entity.TaskFilter{Completed: boolPtr(false), DueWithin: 7 * 24 * time.Hour, Limit: 10})
+	assert.NoError(t, err)
+
+	_, err = service.GetTaskList(testUserContext, entity.TaskFilter{Overdue: boolPtr(true), Completed: boolPtr(true), Limit: 10})
+	assert.ErrorIs(t, err, ErrInvalidData)
+	_, err = service.GetTaskList(testUserContext, entity.TaskFilter{Overdue: boolPtr(true), DueWithin: time.Hour, Limit: 10})
+	assert.ErrorIs(t, err, ErrInvalidData)
+	_, err = service.GetTaskList(testUserContext, entity.TaskFilter{DueWithin: maxDueWithin + time.Hour, Limit: 10})
+	assert.ErrorContains(t, err, "due_within")
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTask_AllDayInUserTimeZone(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	mockUsers := new(MockUserRepository)
+	service := NewService(mockRepo, mockUsers, nil, nil, &configs.Config{})
+
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	mockUsers.On("GetUser", mock.Anything, 1).Return(&entity.User{ID: 1, TimeZone: "Asia/Tokyo"}, nil)
+	mockRepo.On("InsertTask", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+		return task.DueAt.Equal(time.Date(2024, 3, 5, 0, 0, 0, 0, tokyo))
+	})).Return(int64(1), nil)
+
+	task := &entity.Task{Title: "Test Task", DueAt: time.Date(2024, 3, 5, 18, 45, 0, 0, time.UTC), AllDay: true}
+	_, err := service.CreateTask(testUserContext, task)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateTask_OwnerFromContext(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	task := &entity.Task{
+		OwnerID: 99,
+		Title:   "Test Task",
+		DueAt:   time.Now(),
+	}
+
+	mockRepo.On("InsertTask", mock.Anything, mock.MatchedBy(func(task *entity.Task) bool {
+		return task.OwnerID == 1
+	})).Return(int64(1), nil)
+
+	_, err := service.CreateTask(testUserContext, task)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTaskOperations_Unauthenticated(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+	ctx := context.Background()
+	task := &entity.Task{Title: "Test Task", DueAt: time.Now()}
+
+	_, err := service.CreateTask(ctx, task)
+	assert.Equal(t, ErrUnauthorized, err)
+	_, err = service.GetTask(ctx, 1)
+	assert.Equal(t, ErrUnauthorized, err)
+	assert.Equal(t, ErrUnauthorized, service.UpdateTask(ctx, 1, task, false))
+	assert.Equal(t, ErrUnauthorized, service.DeleteTask(ctx, 1, 0))
+	_, err = service.GetTaskList(ctx, entity.TaskFilter{Limit: 10})
+	assert.Equal(t, ErrUnauthorized, err)
+
+	mockRepo.AssertNotCalled(t, "InsertTask", mock.Anything, mock.Anything)
+}
+
+func TestTaskOperations_ScopedToCaller(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+	ctx := WithUser(context.Background(), &entity.User{ID: 2, Username: "jane"})
+	task := &entity.Task{Title: "Hijacked", DueAt: time.Now()}
+
+	mockRepo.On("GetTask", mock.Anything, 2, 1).Return((*entity.Task)(nil), ErrNotFound)
+	mockRepo.On("UpdateTask", mock.Anything, 2, 1, task).Return(ErrNotFound)
+	mockRepo.On("DeleteTask", mock.Anything, 2, 1, 0).Return(ErrNotFound)
+	mockRepo.On("GetTaskList", mock.Anything, entity.TaskFilter{OwnerID: 2, Limit: 11}).Return([]*entity.Task{}, nil)
+
+	_, err := service.GetTask(ctx, 1)
+	assert.Equal(t, ErrNotFound, err)
+	assert.Equal(t, ErrNotFound, service.UpdateTask(ctx, 1, task, false))
+	assert.Equal(t, 2, task.OwnerID)
+	assert.Equal(t, ErrNotFound, service.DeleteTask(ctx, 1, 0))
+	page, err := service.GetTaskList(ctx, entity.TaskFilter{Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Tasks)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetTaskList_Sort(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	sort := []entity.SortField{{Field: "priority", Desc: true}, {Field: "due_at"}}
+	mockRepo.On("GetTaskList", mock.Anything, entity.TaskFilter{OwnerID: 1, Sort: sort, Limit: 11}).Return([]*entity.Task{}, nil)
+
+	_, err := service.GetTaskList(testUserContext, entity.TaskFilter{Sort: sort, Limit: 10})
+	assert.NoError(t, err)
+	// date is the name due_at had before all-day tasks.
+	_, err = service.GetTaskList(testUserContext, entity.TaskFilter{Sort: []entity.SortField{{Field: "priority", Desc: true}, {Field: "date"}}, Limit: 10})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+
+	_, err = service.GetTaskList(testUserContext, entity.TaskFilter{Sort: []entity.SortField{{Field: "owner_id"}}, Limit: 10})
+	assert.ErrorIs(t, err, ErrInvalidData)
+}
+
+func TestCreateTask_InvalidPriority(t *testing.T) {
+	service := NewService(new(MockTaskRepository), nil, nil, nil, &configs.Config{})
+
+	_, err := service.CreateTask(testUserContext, &entity.Task{Title: "Test Task", DueAt: time.Now(), Priority: entity.Priority(9)})
+	assert.Equal(t, ErrInvalidData, err)
 }
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"todo-list/internal/entity"
+)
+
+// ErrParentInTrash is returned when restoring a subtask whose parent is still
+// in the trash.
+var ErrParentInTrash = errors.New("parent task is in the trash")
+
+func (s *Service) GetTrash(ctx context.Context) ([]*entity.Task, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.TaskRepository.GetTrash(ctx, user.ID)
+	if err != nil {
+		return nil, checkTimeout(ctx, err)
+	}
+	if tasks == nil {
+		tasks = []*entity.Task{}
+	}
+
+	return tasks, nil
+}
+
+func (s *Service) RestoreTask(ctx context.Context, id int) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	if id <= 0 {
+		return ErrInvalidData
+	}
+
+	return checkTimeout(ctx, s.TaskRepository.RestoreTask(ctx, user.ID, id))
+}
+
+// PurgeTrash permanently removes the tasks of every user that have been in the
+// trash for longer than the retention period. It returns how many it removed.
+func (s *Service) PurgeTrash(ctx context.Context) (int64, error) {
+	return s.TaskRepository.PurgeTasks(ctx, s.now().Add(-s.trashRetention))
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetTrash(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	deletedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	mockRepo.On("GetTrash", mock.Anything, 1).Return([]*entity.Task{{ID: 1, DeletedAt: &deletedAt}}, nil)
+
+	tasks, err := service.GetTrash(testUserContext)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRestoreTask(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	mockRepo.On("RestoreTask", mock.Anything, 1, 2).Return(ErrParentInTrash)
+
+	assert.ErrorIs(t, service.RestoreTask(testUserContext, 2), ErrParentInTrash)
+	assert.ErrorIs(t, service.RestoreTask(testUserContext, 0), ErrInvalidData)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{TrashRetention: 24 * time.Hour})
+	now := time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	mockRepo.On("PurgeTasks", mock.Anything, now.Add(-24*time.Hour)).Return(int64(3), nil)
+
+	purged, err := service.PurgeTrash(testUserContext)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	mockRepo.AssertExpectations(t)
+}
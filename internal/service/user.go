@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"todo-list/internal/entity"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrUserExists         = errors.New("user already exists")
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrUnauthorized       = errors.New("unauthorized")
+)
+
+const (
+	minPasswordLength = 8
+	// bcrypt ignores everything past 72 bytes, so longer passwords are refused
+	// rather than silently truncated.
+	maxPasswordLength = 72
+)
+
+// dummyHash is compared against when a login names no user, so that an unknown
+// username takes as long to refuse as a wrong password.
+const dummyHash = "$2a$10$nTJtS3kyf76moFhcTgP.Ve8tW3fOo0jvtZsV3N7DMAWSd/KWbCFdK"
+
+// defaultTimeZone is the time zone of a new account until the user picks one.
+const defaultTimeZone = "UTC"
+
+type userKey struct{}
+
+type locationKey struct{}
+
+// WithUser returns a copy of ctx carrying the authenticated user.
+func WithUser(ctx context.Context, user *entity.User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext returns the user put into ctx by WithUser.
+func UserFromContext(ctx context.Context) (*entity.User, bool) {
+	user, ok := ctx.Value(userKey{}).(*entity.User)
+	return user, ok
+}
+
+// currentUser returns the caller every task operation is scoped to.
+func currentUser(ctx context.Context) (*entity.User, error) {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	return user, nil
+}
+
+// WithLocation returns a copy of ctx asking for days to be read in loc rather
+// than in the user's own time zone.
+func WithLocation(ctx context.Context, loc *time.Location) context.Context {
+	return context.WithValue(ctx, locationKey{}, loc)
+}
+
+// LoadTimeZone resolves an IANA time zone name such as "Europe/Berlin".
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidData, name)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", ErrInvalidData, name)
+	}
+
+	return loc, nil
+}
+
+// location returns the time zone days are read in for the caller: the one
+// sent with the request, or else the one saved in the user's settings.
+func (s *Service) location(ctx context.Context, user *entity.User) (*time.Location, error) {
+	if loc, ok := ctx.Value(locationKey{}).(*time.Location); ok {
+		return loc, nil
+	}
+
+	stored, err := s.UserRepository.GetUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return time.LoadLocation(stored.TimeZone)
+}
+
+type tokenClaims struct {
+	Username string `json:"username"`
+	jwt.RegisteredClaims
+}
+
+func (s *Service) Register(ctx context.Context, credentials *entity.Credentials) (int64, error) {
+	username := strings.TrimSpace(credentials.Username)
+	if username == "" || len(username) > 255 ||
+		len(credentials.Password) < minPasswordLength || len(credentials.Password) > maxPasswordLength {
+		return -1, ErrInvalidData
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(credentials.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return -1, err
+	}
+
+	id, err := s.UserRepository.InsertUser(ctx, &entity.User{
+		Username:     username,
+		PasswordHash: string(hash),
+		CreatedAt:    time.Now().UTC(),
+		TimeZone:     defaultTimeZone,
+	})
+	return id, checkTimeout(ctx, err)
+}
+
+func (s *Service) Login(ctx context.Context, credentials *entity.Credentials) (*entity.Token, error) {
+	user, err := s.UserRepository.GetUserByUsername(ctx, strings.TrimSpace(credentials.Username))
+	if errors.Is(err, ErrNotFound) {
+		_ = bcrypt.CompareHashAndPassword([]byte(dummyHash), []byte(credentials.Password))
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, checkTimeout(ctx, err)
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(credentials.Password))
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, tokenClaims{
+		Username: user.Username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(user.ID),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.tokenTTL)),
+		},
+	})
+
+	signed, err := token.SignedString(s.jwtSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Token{
+		AccessToken: signed,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.tokenTTL.Seconds()),
+	}, nil
+}
+
+// ParseToken verifies an access token issued by Login and returns the user
+// it was issued to.
+func (s *Service) ParseToken(token string) (*entity.User, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (interface{}, error) {
+		return s.jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil || id <= 0 {
+		return nil, ErrUnauthorized
+	}
+
+	return &entity.User{ID: id, Username: claims.Username}, nil
+}
+
+// GetCurrentUser returns the caller's account.
+func (s *Service) GetCurrentUser(ctx context.Context) (*entity.User, error) {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.UserRepository.GetUser(ctx, user.ID)
+	return stored, checkTimeout(ctx, err)
+}
+
+func (s *Service) UpdateSettings(ctx context.Context, settings *entity.Settings) error {
+	user, err := currentUser(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = LoadTimeZone(settings.TimeZone)
+	if err != nil {
+		return err
+	}
+
+	return checkTimeout(ctx, s.UserRepository.UpdateUserTimeZone(ctx, user.ID, settings.TimeZone))
+}
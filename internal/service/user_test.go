@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type MockUserRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserRepository) InsertUser(ctx context.Context, user *entity.User) (int64, error) {
+	args := m.Called(ctx, user)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) GetUser(ctx context.Context, id int) (*entity.User, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateUserTimeZone(ctx context.Context, id int, timeZone string) error {
+	args := m.Called(ctx, id, timeZone)
+	return args.Error(0)
+}
+
+var testConfig = &configs.Config{JWTSecret: "test-secret", TokenTTL: time.Hour}
+
+func TestRegister(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewService(nil, mockRepo, nil, nil, testConfig)
+
+	mockRepo.On("InsertUser", mock.Anything, mock.MatchedBy(func(user *entity.User) bool {
+		return user.Username == "john" && bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte("password1")) == nil
+	})).Return(int64(1), nil)
+
+	id, err := service.Register(context.Background(), &entity.Credentials{Username: " john ", Password: "password1"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRegister_InvalidData(t *testing.T) {
+	service := NewService(nil, new(MockUserRepository), nil, nil, testConfig)
+
+	for _, credentials := range []*entity.Credentials{
+		{Username: "", Password: "password1"},
+		{Username: "john", Password: "short"},
+		{Username: "john", Password: string(make([]byte, 73))},
+	} {
+		_, err := service.Register(context.Background(), credentials)
+		assert.Equal(t, ErrInvalidData, err)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewService(nil, mockRepo, nil, nil, testConfig)
+
+	hash, _ := bcrypt.GenerateFromPassword([]byte("password1"), bcrypt.MinCost)
+	mockRepo.On("GetUserByUsername", mock.Anything, "john").Return(&entity.User{ID: 7, Username: "john", PasswordHash: string(hash)}, nil)
+
+	token, err := service.Login(context.Background(), &entity.Credentials{Username: "john", Password: "password1"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", token.TokenType)
+	assert.Equal(t, int64(3600), token.ExpiresIn)
+
+	user, err := service.ParseToken(token.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.User{ID: 7, Username: "john"}, user)
+
+	_, err = service.Login(context.Background(), &entity.Credentials{Username: "john", Password: "password2"})
+	assert.Equal(t, ErrInvalidCredentials, err)
+}
+
+func TestLogin_UnknownUser(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewService(nil, mockRepo, nil, nil, testConfig)
+
+	mockRepo.On("GetUserByUsername", mock.Anything, "john").Return((*entity.User)(nil), ErrNotFound)
+
+	_, err := service.Login(context.Background(), &entity.Credentials{Username: "john", Password: "password1"})
+	assert.Equal(t, ErrInvalidCredentials, err)
+}
+
+func TestDummyHash(t *testing.T) {
+	// The dummy hash must cost as much as a stored one to hide unknown users.
+	cost, err := bcrypt.Cost([]byte(dummyHash))
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.DefaultCost, cost)
+}
+
+func TestParseToken_Invalid(t *testing.T) {
+	service := NewService(nil, nil, nil, nil, testConfig)
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.Claims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		require.NoError(t, err)
+		return token
+	}
+	valid := jwt.RegisteredClaims{Subject: "1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+
+	for name, token := range map[string]string{
+		"garbage":        "not-a-token",
+		"wrong secret":   sign(jwt.SigningMethodHS256, []byte("other-secret"), valid),
+		"none algorithm": sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, valid),
+		"expired":        sign(jwt.SigningMethodHS256, []byte("test-secret"), jwt.RegisteredClaims{Subject: "1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))}),
+		"no expiry":      sign(jwt.SigningMethodHS256, []byte("test-secret"), jwt.RegisteredClaims{Subject: "1"}),
+		"bad subject":    sign(jwt.SigningMethodHS256, []byte("test-secret"), jwt.RegisteredClaims{Subject: "abc", ExpiresAt: valid.ExpiresAt}),
+	} {
+		_, err := service.ParseToken(token)
+		assert.Equal(t, ErrUnauthorized, err, name)
+	}
+}
+
+func TestUserFromContext(t *testing.T) {
+	_, ok := UserFromContext(context.Background())
+	assert.False(t, ok)
+
+	user := &entity.User{ID: 1}
+	result, ok := UserFromContext(WithUser(context.Background(), user))
+	assert.True(t, ok)
+	assert.Equal(t, user, result)
+}
+
+func TestUpdateSettings(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	service := NewService(nil, mockRepo, nil, nil, testConfig)
+
+	mockRepo.On("UpdateUserTimeZone", mock.Anything, 1, "Europe/Berlin").Return(nil)
+
+	assert.NoError(t, service.UpdateSettings(testUserContext, &entity.Settings{TimeZone: "Europe/Berlin"}))
+	assert.ErrorIs(t, service.UpdateSettings(testUserContext, &entity.Settings{TimeZone: "Mars/Olympus"}), ErrInvalidData)
+	assert.ErrorIs(t, service.UpdateSettings(testUserContext, &entity.Settings{TimeZone: "Local"}), ErrInvalidData)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"errors"
+	"todo-list/internal/entity"
+)
+
+var (
+	ErrVersionMismatch = errors.New("task has been changed since the given version")
+	ErrVersionRequired = errors.New("the task version to replace is required")
+)
+
+// AnyVersion stands for a precondition any existing task meets, as
+// "If-Match: *" is.
+const AnyVersion = -1
+
+// expectedVersion checks the version a write was told to replace: 0 for none,
+// AnyVersion, or a version. It returns the version the repository must find,
+// 0 when any will do.
+func (s *Service) expectedVersion(version int) (int, error) {
+	switch {
+	case version == 0 && s.requireVersion:
+		return 0, ErrVersionRequired
+	case version == AnyVersion:
+		return 0, nil
+	case version < 0:
+		return 0, ErrInvalidData
+	}
+
+	return version, nil
+}
+
+// checkVersion fails with ErrVersionMismatch unless task is at version, or
+// version is 0.
+func checkVersion(task *entity.Task, version int) error {
+	if version != 0 && task.Version != version {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
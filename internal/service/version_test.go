@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"todo-list/configs"
+	"todo-list/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateTask_Version(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	task := &entity.Task{Title: "Task", DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), Version: 3}
+	mockRepo.On("UpdateTask", mock.Anything, 1, 1, mock.MatchedBy(func(task *entity.Task) bool { return task.Version == 3 })).Return(ErrVersionMismatch)
+
+	err := service.UpdateTask(testUserContext, 1, task, false)
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRequireVersion(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{RequireIfMatch: true})
+
+	task := &entity.Task{Title: "Task", DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	assert.ErrorIs(t, service.UpdateTask(testUserContext, 1, task, false), ErrVersionRequired)
+	assert.ErrorIs(t, service.DeleteTask(testUserContext, 1, 0), ErrVersionRequired)
+	_, err := service.PatchTask(testUserContext, 1, entity.TaskPatch{"title": []byte(`"New"`)}, 0, false)
+	assert.ErrorIs(t, err, ErrVersionRequired)
+
+	mockRepo.On("DeleteTask", mock.Anything, 1, 1, 0).Return(nil)
+	assert.NoError(t, service.DeleteTask(testUserContext, 1, AnyVersion))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPatchTask_Version(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	service := NewService(mockRepo, nil, nil, nil, &configs.Config{})
+
+	current := &entity.Task{ID: 1, OwnerID: 1, Title: "Old", DueAt: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), Version: 4}
+	mockRepo.On("GetTask", mock.Anything, 1, 1).Return(current, nil)
+
+	_, err := service.PatchTask(testUserContext, 1, mergePatch(t, `{"title": "New"}`), 3, false)
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+	mockRepo.AssertNotCalled(t, "PatchTask", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	mockRepo.On("PatchTask", mock.Anything, 1, 1, mock.MatchedBy(func(task *entity.Task) bool { return task.Version == 4 }), []string{"title"}).
+		Run(func(args mock.Arguments) { args.Get(3).(*entity.Task).Version = 5 }).
+		Return(nil)
+	task, err := service.PatchTask(testUserContext, 1, mergePatch(t, `{"title": "New"}`), 4, false)
+	require.NoError(t, err)
+	assert.Equal(t, 5, task.Version)
+
+	task, err = service.PatchTask(testUserContext, 1, mergePatch(t, `{"title": "Old"}`), 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, 4, task.Version)
+	mockRepo.AssertNumberOfCalls(t, "PatchTask", 1)
+}